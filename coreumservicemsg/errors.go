@@ -0,0 +1,54 @@
+package coreumservicemsg
+
+// Machine-readable error codes returned in the error reply of every endpoint.
+// The callers should rely on the code (and the retryable flag) instead of parsing the message.
+type ErrorCode string
+
+const (
+	// The request is malformed or has invalid parameters (HTTP 400)
+	ErrorCodeInvalidRequest ErrorCode = "invalid_request"
+	// The key or the operation is not allowed by the config of the stage (HTTP 403)
+	ErrorCodeForbidden ErrorCode = "forbidden"
+	// The requested resource (transaction, account,...) does not exist (HTTP 404)
+	ErrorCodeNotFound ErrorCode = "not_found"
+	// The request conflicts with the current chain state, e.g. account sequence mismatch (HTTP 409)
+	ErrorCodeConflict ErrorCode = "conflict"
+	// The request is well-formed but cannot be executed, e.g. insufficient funds (HTTP 422)
+	ErrorCodeUnprocessable ErrorCode = "unprocessable"
+	// The estimated fee of the transaction exceeds the configured maximum fee per transaction (HTTP 422)
+	ErrorCodeFeeCeilingExceeded ErrorCode = "fee_ceiling_exceeded"
+	// The transfer exceeds the remaining spend limit of the authz send authorization of the operator (HTTP 422)
+	ErrorCodeSpendLimitExceeded ErrorCode = "spend_limit_exceeded"
+	// The blockchain node returned an unexpected error (HTTP 502)
+	ErrorCodeChainError ErrorCode = "chain_error"
+	// The blockchain node is not reachable or timed out (HTTP 503)
+	ErrorCodeChainUnavailable ErrorCode = "chain_unavailable"
+	// The secret (e.g. the treasury mnemonic) cannot be loaded (HTTP 503)
+	ErrorCodeSecretUnavailable ErrorCode = "secret_unavailable"
+	// Unexpected error inside the service (HTTP 500)
+	ErrorCodeInternal ErrorCode = "internal"
+)
+
+// Whether the same request may succeed if it is sent again later
+func (c ErrorCode) IsRetryable() bool {
+	switch c {
+	// The fee spike is temporary
+	case ErrorCodeConflict, ErrorCodeFeeCeilingExceeded, ErrorCodeChainError, ErrorCodeChainUnavailable, ErrorCodeSecretUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// The error reply of every endpoint.
+// It also implements the error interface so it can be returned by the service functions directly.
+type Error struct {
+	ErrorMessage string            `json:"ErrorMessage"`
+	ErrorCode    ErrorCode         `json:"error_code"`
+	Retryable    bool              `json:"retryable"`
+	Details      map[string]string `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.ErrorMessage
+}
@@ -1,20 +1,55 @@
 package coreumservicemsg
 
+import "encoding/json"
+
 type ValidateTransferParamsRequest struct {
 	ToTokenDenom string `json:"to_token_denom"`
 	ToAddress    string `json:"to_address"`
-	ToAmount     string `json:"to_amount"`
+	// The amount in the display unit (e.g. "12.34" USDS), at most TokenDecimal fractional digits
+	ToAmount string `json:"to_amount"`
+	// The treasury sending the transfer, default to the treasury of the asset config
+	SenderSecretID string `json:"sender_secret_id,omitempty"` // optional
+}
+
+type TransferParamsCheckCode string
+
+const (
+	TransferParamsCheckInvalidAddress              TransferParamsCheckCode = "invalid_address"
+	TransferParamsCheckWrongAddressPrefix          TransferParamsCheckCode = "wrong_address_prefix"
+	TransferParamsCheckUnknownDenom                TransferParamsCheckCode = "unknown_denom"
+	TransferParamsCheckInvalidAmount               TransferParamsCheckCode = "invalid_amount"
+	TransferParamsCheckAmountPrecisionExceeded     TransferParamsCheckCode = "amount_precision_exceeded"
+	TransferParamsCheckInsufficientTreasuryBalance TransferParamsCheckCode = "insufficient_treasury_balance"
+	TransferParamsCheckTokenGloballyFrozen         TransferParamsCheckCode = "token_globally_frozen"
+	TransferParamsCheckRecipientFrozen             TransferParamsCheckCode = "recipient_frozen"
+	TransferParamsCheckRecipientNotWhitelisted     TransferParamsCheckCode = "recipient_not_whitelisted"
+)
+
+type TransferParamsCheckFailure struct {
+	Code    TransferParamsCheckCode `json:"code"`
+	Message string                  `json:"message"`
 }
 
-type ValidateTransferParamsReply struct{}
+type ValidateTransferParamsReply struct {
+	Valid bool `json:"valid"`
+	// Every failed check, empty if the params are valid
+	FailedChecks []*TransferParamsCheckFailure `json:"failed_checks"`
+}
 
 type GetGasForTransferStablyTokenRequest struct {
-	SenderSecretID   string `json:"sender_secret_id"`
-	TokenDenom       string `json:"token_denom"`
-	TokenAmount      int64  `json:"token_amount"`
+	SenderSecretID string `json:"sender_secret_id"`
+	TokenDenom     string `json:"token_denom"`
+	TokenAmount    int64  `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount    string `json:"display_amount,omitempty"`
 	RecipientAddress string `json:"recipient_address"`
 	SequenceNumber   uint64 `json:"sequence_number"`
 	Memo             string `json:"memo,omitempty"` // optional
+	// Estimate with the committed sequence of the sender instead of SequenceNumber
+	AutoSequence bool `json:"auto_sequence,omitempty"` // optional
 }
 
 type GetGasForTransferStablyTokenReply struct {
@@ -23,18 +58,26 @@ type GetGasForTransferStablyTokenReply struct {
 }
 
 type TransferStablyTokenRequest struct {
-	SenderSecretID   string `json:"sender_secret_id"`
-	TokenDenom       string `json:"token_denom"`
-	SequenceNumber   uint64 `json:"sequence_number"`
-	TokenAmount      int64  `json:"token_amount"`
+	SenderSecretID string `json:"sender_secret_id"`
+	TokenDenom     string `json:"token_denom"`
+	SequenceNumber uint64 `json:"sequence_number"`
+	TokenAmount    int64  `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount    string `json:"display_amount,omitempty"`
 	Memo             string `json:"memo,omitempty"` // optional
 	RecipientAddress string `json:"recipient_address"`
 	GasPrice         string `json:"gas_price"`
 	GasUsed          uint64 `json:"gas_used"`
+	// Reserve the sequence from the sequence manager of the sender instead of SequenceNumber
+	AutoSequence bool `json:"auto_sequence,omitempty"` // optional
 }
 
 type TransferStablyTokenReply struct {
-	// The transaction hash after submitting to the blockchain
+	// The transaction hash after submitting to the blockchain.
+	// The transaction is accepted by the mempool but not in a block yet, see get-transaction-confirmation-status
 	TxHash string `json:"tx_hash"`
 }
 
@@ -43,21 +86,32 @@ type TransferTokenWithMnemonicRequest struct {
 	RecipientAddress string `json:"recipient_address"`
 	TokenDenom       string `json:"token_denom"`
 	TokenAmount      int64  `json:"token_amount"`
-	Memo             string `json:"memo,omitempty"` // optional
-	SequenceNumber   uint64 `json:"sequence_number"`
-	GasPrice         string `json:"gas_price,omitempty"` // auto-suggested if not filled
-	GasUsed          uint64 `json:"gas_used,omitempty"`  // auto-suggested if not filled
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount  string `json:"display_amount,omitempty"`
+	Memo           string `json:"memo,omitempty"` // optional
+	SequenceNumber uint64 `json:"sequence_number"`
+	GasPrice       string `json:"gas_price,omitempty"` // auto-suggested if not filled
+	GasUsed        uint64 `json:"gas_used,omitempty"`  // auto-suggested if not filled
 }
 
 type TransferTokenWithMnemonicReply struct {
-	// The transaction hash after submitting to the blockchain
+	// The transaction hash after submitting to the blockchain.
+	// The transaction is accepted by the mempool but not in a block yet, see get-transaction-confirmation-status
 	TxHash string `json:"tx_hash"`
 }
 
 type CalculateHashOfTransactionRequest struct {
-	SenderSecretID   string `json:"sender_secret_id"`
-	TokenDenom       string `json:"token_denom"`
-	TokenAmount      int64  `json:"token_amount"`
+	SenderSecretID string `json:"sender_secret_id"`
+	TokenDenom     string `json:"token_denom"`
+	TokenAmount    int64  `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount    string `json:"display_amount,omitempty"`
 	Memo             string `json:"memo,omitempty"` // optional
 	RecipientAddress string `json:"recipient_address"`
 	SequenceNumber   uint64 `json:"sequence_number"`
@@ -70,6 +124,472 @@ type CalculateHashOfTransactionReply struct {
 	CalculatedTxHash string `json:"calculated_tx_hash"`
 }
 
+type ProposeTransferParamsRequest struct {
+	SenderSecretID string `json:"sender_secret_id"`
+	TokenDenom     string `json:"token_denom"`
+	TokenAmount    int64  `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount    string `json:"display_amount,omitempty"`
+	Memo             string `json:"memo,omitempty"` // optional
+	RecipientAddress string `json:"recipient_address"`
+}
+
+// All the params of transfer-stably-token, persist them before broadcasting the transfer
+type ProposeTransferParamsReply struct {
+	SenderSecretID string `json:"sender_secret_id"`
+	SenderAddress  string `json:"sender_address"`
+	TokenDenom     string `json:"token_denom"`
+	// 0 if the amount doesn't fit in int64, use Amount instead
+	TokenAmount int64 `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000")
+	Amount string `json:"amount"`
+	// The amount in the display unit, empty if the denom has no asset config
+	DisplayAmount    string `json:"display_amount,omitempty"`
+	DisplayUnit      string `json:"display_unit,omitempty"`
+	Memo             string `json:"memo,omitempty"`
+	RecipientAddress string `json:"recipient_address"`
+	// The sequence is reserved for the signer
+	SequenceNumber uint64 `json:"sequence_number"`
+	GasPrice       string `json:"gas_price"`
+	GasUsed        uint64 `json:"gas_used"`
+	// The hash of the transaction signed with the params above
+	CalculatedTxHash string `json:"calculated_tx_hash"`
+	// The wallet signing the transaction, the operator of the treasury if configured, otherwise the sender
+	SignerAddress string `json:"signer_address"`
+}
+
+type BatchTransferMessageMode string
+
+const (
+	// One MsgMultiSend paying all the recipients of the batch
+	BatchTransferMessageModeMultiSend BatchTransferMessageMode = "multi_send"
+	// One MsgSend per recipient, in the same transaction
+	BatchTransferMessageModeSend BatchTransferMessageMode = "send"
+)
+
+type BatchTransferRecipient struct {
+	RecipientAddress string `json:"recipient_address"`
+	TokenAmount      int64  `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount string `json:"display_amount,omitempty"`
+}
+
+type BatchTransferStablyTokenRequest struct {
+	SenderSecretID string                    `json:"sender_secret_id"`
+	TokenDenom     string                    `json:"token_denom"`
+	Recipients     []*BatchTransferRecipient `json:"recipients"`
+	Memo           string                    `json:"memo,omitempty"` // optional
+	// Default to multi_send
+	MessageMode BatchTransferMessageMode `json:"message_mode,omitempty"` // optional
+	// Only estimate the gas and precompute the hashes, nothing is broadcast
+	DryRun bool `json:"dry_run,omitempty"` // optional
+}
+
+type BatchTransferLegStatus string
+
+const (
+	// The leg is estimated by the dry run
+	BatchTransferLegStatusEstimated BatchTransferLegStatus = "estimated"
+	// The transaction of the leg is accepted by the mempool, see get-transaction-confirmation-status
+	BatchTransferLegStatusSubmitted BatchTransferLegStatus = "submitted"
+	BatchTransferLegStatusFailed    BatchTransferLegStatus = "failed"
+)
+
+type BatchTransferLegResult struct {
+	// The index of the recipient in the request
+	Index            int                    `json:"index"`
+	RecipientAddress string                 `json:"recipient_address"`
+	Amount           string                 `json:"amount"`
+	TxHash           string                 `json:"tx_hash"`
+	Status           BatchTransferLegStatus `json:"status"`
+	ErrorMessage     string                 `json:"error_message,omitempty"`
+}
+
+// The transaction paying a part of the recipients, the recipients are split to fit in the block gas limit
+type TransferBatch struct {
+	TxHash         string                    `json:"tx_hash"`
+	SequenceNumber uint64                    `json:"sequence_number"`
+	GasPrice       string                    `json:"gas_price"`
+	GasUsed        uint64                    `json:"gas_used"`
+	Legs           []*BatchTransferLegResult `json:"legs"`
+}
+
+type BatchTransferStablyTokenReply struct {
+	Batches []*TransferBatch `json:"batches"`
+	// The results of all the legs, in the order of the recipients in the request
+	Legs []*BatchTransferLegResult `json:"legs"`
+}
+
+type TxEncoding string
+
+const (
+	// The protobuf bytes encoded in base64, the format broadcast to the chain
+	TxEncodingProto TxEncoding = "proto"
+	// The JSON of the transaction, for the review before signing
+	TxEncodingJSON TxEncoding = "json"
+)
+
+type BuildUnsignedTransferRequest struct {
+	// The treasury sending the transfer, or SenderAddress for the key that is not configured in the service
+	SenderSecretID   string `json:"sender_secret_id,omitempty"`
+	SenderAddress    string `json:"sender_address,omitempty"`
+	RecipientAddress string `json:"recipient_address"`
+	TokenDenom       string `json:"token_denom"`
+	TokenAmount      int64  `json:"token_amount"`
+	// The amount in the base unit as the decimal string (e.g. "1000000"), for the amounts beyond int64
+	Amount string `json:"amount,omitempty"`
+	// The amount in the display unit of the asset (e.g. "12.34" USDS), at most TokenDecimal fractional digits.
+	// If more than one amount is set, they must be equal.
+	DisplayAmount  string `json:"display_amount,omitempty"`
+	Memo           string `json:"memo,omitempty"` // optional
+	SequenceNumber uint64 `json:"sequence_number"`
+	// Reserve the sequence from the sequence manager of the sender instead of SequenceNumber
+	AutoSequence bool       `json:"auto_sequence,omitempty"` // optional
+	GasPrice     string     `json:"gas_price,omitempty"`     // auto-suggested if not filled
+	GasUsed      uint64     `json:"gas_used,omitempty"`      // auto-suggested if not filled
+	Encoding     TxEncoding `json:"encoding,omitempty"`      // default to proto
+}
+
+type BuildUnsignedTransferReply struct {
+	// The base64 protobuf bytes or the JSON of the transaction, depending on Encoding
+	UnsignedTx string     `json:"unsigned_tx"`
+	Encoding   TxEncoding `json:"encoding"`
+	// The params needed to sign the transaction
+	SenderAddress  string `json:"sender_address"`
+	AccountNumber  uint64 `json:"account_number"`
+	SequenceNumber uint64 `json:"sequence_number"`
+	ChainID        string `json:"chain_id"`
+	GasPrice       string `json:"gas_price"`
+	GasUsed        uint64 `json:"gas_used"`
+}
+
+type SignTransactionRequest struct {
+	SignerSecretID string     `json:"signer_secret_id"`
+	UnsignedTx     string     `json:"unsigned_tx"`
+	Encoding       TxEncoding `json:"encoding,omitempty"` // default to proto
+	// Fetched from the chain if not set (0 is the account number of the first genesis account)
+	AccountNumber *uint64 `json:"account_number,omitempty"`
+	// Required, the sequence the transaction is built for (0 is the first sequence of the account)
+	SequenceNumber *uint64 `json:"sequence_number"`
+}
+
+type SignTransactionReply struct {
+	// The base64 protobuf bytes of the signed transaction, persist them before broadcasting
+	SignedTxBytes string `json:"signed_tx_bytes"`
+	TxHash        string `json:"tx_hash"`
+}
+
+type BroadcastSignedTransactionRequest struct {
+	// The base64 protobuf bytes of the signed transaction
+	SignedTxBytes string `json:"signed_tx_bytes"`
+	// The broadcast is refused if the hash of the bytes is different
+	ExpectedTxHash string `json:"expected_tx_hash"`
+}
+
+type BroadcastSignedTransactionReply struct {
+	TxHash string `json:"tx_hash"`
+	// submitted, or in_block/failed if the same transaction was already included in a block
+	State TransactionConfirmationState `json:"state"`
+}
+
+type RawTxEncoding string
+
+const (
+	RawTxEncodingBase64 RawTxEncoding = "base64"
+	RawTxEncodingHex    RawTxEncoding = "hex"
+)
+
+// Broadcast the transaction signed outside the service, e.g. by a custody partner
+type BroadcastRawTransactionRequest struct {
+	// The protobuf bytes of the signed transaction
+	TxBytes  string        `json:"tx_bytes"`
+	Encoding RawTxEncoding `json:"encoding,omitempty"` // default to base64
+}
+
+type BroadcastRawTransactionReply struct {
+	TxHash string `json:"tx_hash"`
+	// submitted, or in_block/failed if the same transaction was already included in a block
+	State TransactionConfirmationState `json:"state"`
+}
+
+type DecodeTransactionRequest struct {
+	// The protobuf bytes of the transaction, e.g. copied from an explorer
+	TxBytes  string        `json:"tx_bytes"`
+	Encoding RawTxEncoding `json:"encoding,omitempty"` // default to base64
+}
+
+type CoreumSignerInfo struct {
+	// Empty if the public key is not in the transaction (it's already known by the chain)
+	Address    string `json:"address,omitempty"`
+	PubKeyType string `json:"pub_key_type,omitempty"`
+	// The bytes of the public key in base64
+	PubKey   string `json:"pub_key,omitempty"`
+	Sequence uint64 `json:"sequence"`
+	// e.g. SIGN_MODE_DIRECT, or multi for the multisig
+	SignMode string `json:"sign_mode"`
+}
+
+type DecodeTransactionReply struct {
+	TxHash      string                `json:"tx_hash"`
+	Body        CoreumTransactionBody `json:"body"`
+	SignerInfos []*CoreumSignerInfo   `json:"signer_infos"`
+	Fee         []*Coin               `json:"fee"`
+	GasLimit    uint64                `json:"gas_limit"`
+	FeePayer    string                `json:"fee_payer,omitempty"`
+	FeeGranter  string                `json:"fee_granter,omitempty"`
+	// The signatures in base64, in the order of the signer infos
+	Signatures []string `json:"signatures"`
+}
+
+// Simulate any set of messages without signing or broadcasting them
+type SimulateTransactionRequest struct {
+	// The account simulating the transaction, default to the first signer of the first message
+	SignerAddress string `json:"signer_address,omitempty"`
+	// The messages in the same format as the messages of the transaction detail,
+	// e.g. {"type_url": "/cosmos.bank.v1beta1.MsgSend", "value": {"from_address": ...}}
+	Messages []*CoreumTransactionMessage `json:"messages"`
+	Memo     string                      `json:"memo,omitempty"`
+}
+
+type SimulateTransactionReply struct {
+	SignerAddress string `json:"signer_address"`
+	// The gas used by the simulation
+	GasUsed uint64 `json:"gas_used"`
+	// The gas used multiplied by the gas adjustment, to be used as the gas limit
+	GasAdjusted uint64 `json:"gas_adjusted"`
+	GasPrice    string `json:"gas_price"`
+	// The gas price multiplied by the adjusted gas
+	SuggestedFee *Coin `json:"suggested_fee"`
+	// The transaction with the suggested fee would be refused by the maximum fee per transaction
+	ExceedsFeeCeiling bool                      `json:"exceeds_fee_ceiling"`
+	Events            []*CoreumTransactionEvent `json:"events"`
+}
+
+// Grant the fee allowance, so the grantee's transactions are paid by the granter (the gas-funding wallet by default)
+type GrantFeeAllowanceRequest struct {
+	GranterSecretID string `json:"granter_secret_id,omitempty"` // default to the gas-funding wallet
+	GranteeAddress  string `json:"grantee_address"`
+	// The maximum fee paid by the granter, e.g. "10000000ucore", unlimited if empty
+	SpendLimit string `json:"spend_limit,omitempty"`
+	// Unix seconds, never expires if 0
+	Expiration int64 `json:"expiration,omitempty"`
+	// The type URLs of the messages allowed to use the allowance, all the messages if empty
+	AllowedMessages []string `json:"allowed_messages,omitempty"`
+}
+
+type GrantFeeAllowanceReply struct {
+	TxHash         string `json:"tx_hash"`
+	GranterAddress string `json:"granter_address"`
+}
+
+type RevokeFeeAllowanceRequest struct {
+	GranterSecretID string `json:"granter_secret_id,omitempty"` // default to the gas-funding wallet
+	GranteeAddress  string `json:"grantee_address"`
+}
+
+type RevokeFeeAllowanceReply struct {
+	TxHash         string `json:"tx_hash"`
+	GranterAddress string `json:"granter_address"`
+}
+
+type GetFeeAllowancesRequest struct {
+	GranteeAddress string `json:"grantee_address"`
+	GranterAddress string `json:"granter_address,omitempty"` // optional, all the granters if empty
+}
+
+type FeeAllowance struct {
+	Granter string `json:"granter"`
+	Grantee string `json:"grantee"`
+	// The type URL of the allowance, e.g. /cosmos.feegrant.v1beta1.BasicAllowance
+	Type string `json:"type"`
+	// Empty if unlimited
+	SpendLimit []*Coin `json:"spend_limit"`
+	// Unix seconds, 0 if it never expires
+	Expiration      int64    `json:"expiration,omitempty"`
+	AllowedMessages []string `json:"allowed_messages,omitempty"`
+}
+
+type GetFeeAllowancesReply struct {
+	Allowances []*FeeAllowance `json:"allowances"`
+}
+
+// Grant the authz send authorization, so the grantee (the operator) sends the coins of the granter (the treasury) up to the spend limit
+type GrantAuthorizationRequest struct {
+	GranterSecretID string `json:"granter_secret_id,omitempty"` // default to the treasury
+	GranteeAddress  string `json:"grantee_address"`
+	// The maximum amount sent by the grantee, e.g. "1000000000microusds-testcore1..."
+	SpendLimit string `json:"spend_limit"`
+	// Unix seconds
+	Expiration int64 `json:"expiration"`
+}
+
+type GrantAuthorizationReply struct {
+	TxHash         string `json:"tx_hash"`
+	GranterAddress string `json:"granter_address"`
+}
+
+type RevokeAuthorizationRequest struct {
+	GranterSecretID string `json:"granter_secret_id,omitempty"` // default to the treasury
+	GranteeAddress  string `json:"grantee_address"`
+}
+
+type RevokeAuthorizationReply struct {
+	TxHash         string `json:"tx_hash"`
+	GranterAddress string `json:"granter_address"`
+}
+
+type GetAuthorizationsRequest struct {
+	GranteeAddress string `json:"grantee_address"`
+	GranterAddress string `json:"granter_address,omitempty"` // optional, all the granters if empty
+}
+
+type Authorization struct {
+	Granter string `json:"granter"`
+	Grantee string `json:"grantee"`
+	// The type URL of the authorization, e.g. /cosmos.bank.v1beta1.SendAuthorization
+	Type string `json:"type"`
+	// The type URL of the messages executed with the authorization
+	MsgTypeURL string `json:"msg_type_url"`
+	// The remaining spend limit of the send authorization, empty for the other authorizations
+	SpendLimit []*Coin `json:"spend_limit"`
+	// Unix seconds
+	Expiration int64 `json:"expiration"`
+}
+
+type GetAuthorizationsReply struct {
+	Authorizations []*Authorization `json:"authorizations"`
+}
+
+// Register the k-of-n multisig account, so its transactions are signed by collecting the partial signatures
+type RegisterMultisigAccountRequest struct {
+	// The base64 compressed secp256k1 public keys of the signers, the address depends on their order
+	PubKeys   []string `json:"pub_keys"`
+	Threshold uint32   `json:"threshold"`
+}
+
+type MultisigAccount struct {
+	Address   string   `json:"address"`
+	PubKeys   []string `json:"pub_keys"`
+	Threshold uint32   `json:"threshold"`
+}
+
+type RegisterMultisigAccountReply struct {
+	Account *MultisigAccount `json:"account"`
+}
+
+// Build the unsigned transaction of the multisig account and start collecting the partial signatures
+type CreateMultisigSessionRequest struct {
+	MultisigAddress string `json:"multisig_address"`
+	// The messages signed by the multisig account, in the same format as SimulateTransactionRequest.
+	// The transfer below is built if empty.
+	Messages         []*CoreumTransactionMessage `json:"messages,omitempty"`
+	RecipientAddress string                      `json:"recipient_address,omitempty"`
+	TokenDenom       string                      `json:"token_denom,omitempty"`
+	TokenAmount      int64                       `json:"token_amount,omitempty"`
+	Amount           string                      `json:"amount,omitempty"`
+	DisplayAmount    string                      `json:"display_amount,omitempty"`
+	Memo             string                      `json:"memo,omitempty"` // optional
+	SequenceNumber   uint64                      `json:"sequence_number"`
+	// Use the lowest sequence from the account sequence without a pending session instead of SequenceNumber
+	AutoSequence bool   `json:"auto_sequence,omitempty"` // optional
+	GasPrice     string `json:"gas_price,omitempty"`     // auto-suggested if not filled
+	GasUsed      uint64 `json:"gas_used,omitempty"`      // auto-suggested if not filled
+}
+
+type MultisigSession struct {
+	// The hash of the unsigned transaction
+	SessionID       string `json:"session_id"`
+	MultisigAddress string `json:"multisig_address"`
+	Threshold       uint32 `json:"threshold"`
+	// The base64 protobuf bytes of the unsigned transaction
+	UnsignedTx string `json:"unsigned_tx"`
+	// The base64 bytes every signer signs, in the legacy amino JSON sign mode required by the multisig
+	SignBytes      string `json:"sign_bytes"`
+	AccountNumber  uint64 `json:"account_number"`
+	SequenceNumber uint64 `json:"sequence_number"`
+	ChainID        string `json:"chain_id"`
+	GasPrice       string `json:"gas_price"`
+	GasUsed        uint64 `json:"gas_used"`
+	// The addresses of the keys that have signed, in the order of the keys of the multisig
+	SignedBy []string `json:"signed_by"`
+	// Unix seconds
+	CreatedAt int64 `json:"created_at"`
+	// Unix seconds, the session is dropped if it's not broadcast by then
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+type CreateMultisigSessionReply struct {
+	Session *MultisigSession `json:"session"`
+}
+
+// Add the partial signature to the session.
+// The transaction is broadcast once the threshold is met.
+type SubmitMultisigSignatureRequest struct {
+	SessionID string `json:"session_id"`
+	// The base64 public key of the signer and its base64 signature of the sign bytes of the session
+	PubKey    string `json:"pub_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	// Instead of PubKey and Signature, sign with the key of the service
+	SignerSecretID string `json:"signer_secret_id,omitempty"`
+}
+
+type SubmitMultisigSignatureReply struct {
+	Session *MultisigSession `json:"session"`
+	// Set once the threshold is met and the transaction is broadcast
+	TxHash string                       `json:"tx_hash,omitempty"`
+	State  TransactionConfirmationState `json:"state,omitempty"`
+}
+
+// Drop the pending session, so its sequence can be used by a new session
+type CancelMultisigSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+type CancelMultisigSessionReply struct {
+	Session *MultisigSession `json:"session"`
+}
+
+type GetMultisigSessionsRequest struct {
+	MultisigAddress string `json:"multisig_address,omitempty"` // optional, all the accounts if empty
+}
+
+type GetMultisigSessionsReply struct {
+	// The pending sessions, the oldest first
+	Sessions []*MultisigSession `json:"sessions"`
+}
+
+type RefreshSecretsRequest struct {
+	SecretNames []string `json:"secret_names,omitempty"` // optional, all the cached secrets if empty
+}
+
+type SecretVersion struct {
+	SecretName       string `json:"secret_name"`
+	CurrentVersionID string `json:"current_version_id"`
+	// Empty if the secret has no previous version
+	PreviousVersionID string `json:"previous_version_id,omitempty"`
+	// Unix seconds
+	RefreshedAt int64 `json:"refreshed_at"`
+}
+
+type TreasuryWallet struct {
+	SecretID string `json:"secret_id"`
+	Address  string `json:"address"`
+	// The address before the refresh, only set if the rotated mnemonic has the other address
+	PreviousAddress string `json:"previous_address,omitempty"`
+}
+
+type RefreshSecretsReply struct {
+	Secrets []*SecretVersion `json:"secrets"`
+	// The treasury wallets used since the start of the service
+	Wallets []*TreasuryWallet `json:"wallets"`
+}
+
 type GetTreasuryAddressRequest struct {
 	TreasurySecretID string `json:"treasury_secret_id"`
 }
@@ -87,13 +607,65 @@ type GetAccountInfoReply struct {
 	Sequence      uint64 `json:"sequence"`
 }
 
+type TransactionStatus string
+
+const (
+	// The transaction is neither in a block nor in the mempool
+	TransactionStatusNotFound TransactionStatus = "not_found"
+	// The transaction is in the mempool, waiting to be included in a block
+	TransactionStatusPending TransactionStatus = "pending"
+	// The transaction is not in a block, and the mempool has more transactions than the checked ones
+	TransactionStatusUnknown TransactionStatus = "unknown"
+	// The transaction is included in a block, but its execution failed
+	TransactionStatusFailed  TransactionStatus = "failed"
+	TransactionStatusSuccess TransactionStatus = "success"
+)
+
+// A message of the transaction
+type CoreumTransactionMessage struct {
+	// e.g. /cosmos.bank.v1beta1.MsgSend
+	TypeURL string `json:"type_url"`
+	// The message decoded to JSON
+	Value json.RawMessage `json:"value"`
+}
+
+type CoreumTransactionEventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type CoreumTransactionEvent struct {
+	Type       string                             `json:"type"`
+	Attributes []*CoreumTransactionEventAttribute `json:"attributes"`
+}
+
+// The Memo field is kept without the JSON tag for the existing callers
 type CoreumTransactionBody struct {
-	Memo string
+	Memo          string
+	TimeoutHeight uint64                      `json:"timeout_height"`
+	Messages      []*CoreumTransactionMessage `json:"messages"`
 }
 
 // Reference: https://docs.coreum.dev/api/api.html#cosmos.tx.v1beta1.Tx
+// The Body field is kept without the JSON tag for the existing callers
 type CoreumTransactionDetail struct {
-	Body CoreumTransactionBody
+	TxHash string            `json:"tx_hash"`
+	Status TransactionStatus `json:"status"`
+	// The block fields are empty for the pending transaction
+	Height     int64   `json:"height,omitempty"`
+	Timestamp  int64   `json:"timestamp,omitempty"` // unix seconds of the block
+	Code       uint32  `json:"code"`
+	Codespace  string  `json:"codespace,omitempty"`
+	RawLog     string  `json:"raw_log,omitempty"`
+	GasUsed    int64   `json:"gas_used,omitempty"`
+	GasWanted  int64   `json:"gas_wanted"`
+	Fee        []*Coin `json:"fee"`
+	FeePayer   string  `json:"fee_payer,omitempty"`
+	FeeGranter string  `json:"fee_granter,omitempty"`
+	// The addresses signing the transaction, the first one pays the fee by default
+	Signers []string `json:"signers"`
+	Body    CoreumTransactionBody
+	Events  []*CoreumTransactionEvent `json:"events,omitempty"`
 }
 
 type GetTransactionRequest struct {
@@ -101,9 +673,66 @@ type GetTransactionRequest struct {
 }
 
 type GetTransactionReply struct {
+	Status TransactionStatus `json:"status"`
+	// nil if the status is not_found
 	TransactionDetail *CoreumTransactionDetail
 }
 
+type GetSenderSequenceStatusRequest struct {
+	Address string `json:"address"`
+}
+
+type InFlightSequence struct {
+	Sequence uint64 `json:"sequence"`
+	// Empty if the sequence is reserved but not broadcast yet
+	TxHash     string `json:"tx_hash,omitempty"`
+	ReservedAt int64  `json:"reserved_at"`
+}
+
+type GetSenderSequenceStatusReply struct {
+	Address string `json:"address"`
+	// The sequence of the next transaction committed on chain
+	ChainSequence uint64 `json:"chain_sequence"`
+	// The sequence reserved by the next transfer
+	NextSequence uint64              `json:"next_sequence"`
+	InFlight     []*InFlightSequence `json:"in_flight"`
+	// The sequences that block the later transactions of the sender
+	Gaps []uint64 `json:"gaps"`
+}
+
+type TransactionConfirmationState string
+
+const (
+	// The transaction is accepted by the mempool, waiting to be included in a block
+	TransactionConfirmationStateSubmitted TransactionConfirmationState = "submitted"
+	// The transaction is in a block, but has fewer confirmations than required
+	TransactionConfirmationStateInBlock TransactionConfirmationState = "in_block"
+	// The transaction has at least the required number of confirmations
+	TransactionConfirmationStateConfirmed TransactionConfirmationState = "confirmed"
+	// The transaction is in a block, but its execution failed
+	TransactionConfirmationStateFailed TransactionConfirmationState = "failed"
+	// The transaction is unknown, or it was dropped from the mempool
+	TransactionConfirmationStateNotFound TransactionConfirmationState = "not_found"
+	// The transaction is not in a block, and the mempool has more transactions than the checked ones
+	TransactionConfirmationStateUnknown TransactionConfirmationState = "unknown"
+)
+
+type GetTransactionConfirmationStatusRequest struct {
+	TransactionHash string `json:"transaction_hash"`
+}
+
+type GetTransactionConfirmationStatusReply struct {
+	TxHash string                       `json:"tx_hash"`
+	State  TransactionConfirmationState `json:"state"`
+	// The block fields are empty until the transaction is in a block
+	Height int64 `json:"height,omitempty"`
+	// The number of blocks since the block of the transaction, the block itself included
+	Confirmations         int64  `json:"confirmations"`
+	RequiredConfirmations int64  `json:"required_confirmations"`
+	Code                  uint32 `json:"code"`
+	RawLog                string `json:"raw_log,omitempty"`
+}
+
 type BlockStatus struct {
 	// Reference: the SyncInfo in github.com/informalsystems/tendermint@v0.34.26/rpc/core/types/responses.go
 	LatestBlockHash   string `json:"latest_block_hash"`
@@ -123,18 +752,51 @@ type GetLatestBlockStatusReply struct {
 	BlockStatus *BlockStatus `json:"block_status"`
 }
 
+type NodeStatus struct {
+	Name         string `json:"name"`
+	Healthy      bool   `json:"healthy"`
+	LatestHeight int64  `json:"latest_height"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+type GetNodeStatusReply struct {
+	Nodes []*NodeStatus `json:"nodes"`
+}
+
+// A transfer leg of a transaction, a transaction has one entry per leg
 type Transaction struct {
 	TxHash      string  `json:"tx_hash"`
-	FromAddress string  `json:"from_address"`
-	ToAddress   string  `json:"to_address"`
+	FromAddress string  `json:"from_address"` // empty for the minted coins
+	ToAddress   string  `json:"to_address"`   // empty for the burnt coins
 	Memo        string  `json:"memo,omitempty"`
 	Coins       []*Coin `json:"coins"`
 	BlockNumber uint64  `json:"block_number"`
+	// The index of the message in the transaction, and the index of the leg in the message.
+	// (TxHash, MessageIndex, LegIndex) identifies the leg.
+	MessageIndex int `json:"message_index"`
+	LegIndex     int `json:"leg_index"`
+	// The type URL of the message moving the coins, e.g. /cosmos.bank.v1beta1.MsgSend
+	MessageType string `json:"message_type"`
+
+	// The fee of the whole transaction, repeated in every leg
+	Fee []*Coin `json:"fee"`
+
+	// The execution result of the transaction, only available for the transactions fetched from a block
+	BlockTime int64  `json:"block_time,omitempty"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	GasUsed   int64  `json:"gas_used,omitempty"`
+	GasWanted int64  `json:"gas_wanted,omitempty"`
+	// False if the transaction failed, its transfers were NOT executed and must not be credited
+	Success bool `json:"success"`
 }
 
 type Coin struct {
 	Amount string `json:"amount"`
 	Denom  string `json:"denom"`
+	// The amount in the display unit, empty if the denom has no asset config
+	DisplayAmount string `json:"display_amount,omitempty"`
+	DisplayUnit   string `json:"display_unit,omitempty"`
 }
 
 type GetBlockTransactionsRequest struct {
@@ -147,9 +809,19 @@ type GetBlockTransactionsReply struct {
 type GetBlockTransactionsInRangeRequest struct {
 	StartBlockNumber uint64 `json:"start_block_number"`
 	EndBlockNumber   uint64 `json:"end_block_number"`
+	// Number of blocks fetched at the same time, default to (and capped at) the configured maximum
+	Concurrency int `json:"concurrency,omitempty"` // optional
 }
 type GetBlockTransactionsInRangeReply struct {
+	// The transactions of the successfully fetched blocks
 	Transactions []*Transaction `json:"transactions"`
+	// The blocks that failed to be fetched, the caller should fetch them again
+	FailedBlocks []*BlockError `json:"failed_blocks"`
+}
+
+type BlockError struct {
+	BlockNumber  uint64 `json:"block_number"`
+	ErrorMessage string `json:"error_message"`
 }
 
 type GetBalanceOfAddressForDenomRequest struct {
@@ -159,8 +831,7 @@ type GetBalanceOfAddressForDenomRequest struct {
 
 type GetBalanceOfAddressForDenomReply struct {
 	Amount string `json:"amount"`
-}
-
-type Error struct {
-	ErrorMessage string `json:"ErrorMessage"`
+	// The amount in the display unit, empty if the denom has no asset config
+	DisplayAmount string `json:"display_amount,omitempty"`
+	DisplayUnit   string `json:"display_unit,omitempty"`
 }
@@ -2,6 +2,7 @@ package coreumservicelib
 
 import (
 	"context"
+	"coreumservice/go/stably_io/config"
 	"coreumservice/go/stably_io/utils"
 	"fmt"
 
@@ -36,8 +37,20 @@ func GetAddress(mnemonic string, index int64) (*AddressInfo, error) {
 	}, nil
 }
 
+// The key of the remote signer has no derivation path
 func GetTreasuryAddress(ctx context.Context, secretID string) (*AddressInfo, error) {
-	mnemonic := GetTreasuryMnemonicFromSecretID(ctx, secretID)
+	if config.GetConfigDefault().Blockchain.Coreum.RemoteSigner.KeyIDs[secretID] != "" {
+		signer, err := GetSigner(ctx, secretID)
+		if err != nil {
+			return nil, err
+		}
+		return &AddressInfo{Address: signer.GetAddress().String()}, nil
+	}
+
+	mnemonic, err := getRequiredTreasuryMnemonic(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
 	addressInfo, err := GetAddress(mnemonic, 0)
 	if err != nil {
 		return nil, errors.Errorf("GetAddress(mnemonic, 0): %v", err)
@@ -0,0 +1,146 @@
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"regexp"
+	"strings"
+
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+var displayAmountRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// No sign, no exponent, no leading zeros
+var baseAmountRegex = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+// The amount has more fractional digits than the token decimal
+var ErrAmountPrecisionExceeded = errors.New("amount precision exceeded")
+
+// Parse the amount in the display unit (e.g. "12.34" USDS) to the amount in the base unit (e.g. 12340000 microusds).
+// The amount must be positive, and the excess precision is rejected instead of being rounded.
+func ParseDisplayAmount(amount string, tokenDecimal int) (cosmossdk.Int, error) {
+	if !displayAmountRegex.MatchString(amount) {
+		return cosmossdk.Int{}, errors.Errorf("invalid amount %q", amount)
+	}
+
+	integerPart, fractionalPart, _ := strings.Cut(amount, ".")
+	if len(fractionalPart) > tokenDecimal {
+		return cosmossdk.Int{}, errors.Wrapf(ErrAmountPrecisionExceeded, "amount %q has more than %d fractional digits", amount, tokenDecimal)
+	}
+	fractionalPart += strings.Repeat("0", tokenDecimal-len(fractionalPart))
+
+	baseAmount, ok := cosmossdk.NewIntFromString(integerPart + fractionalPart)
+	if !ok {
+		return cosmossdk.Int{}, errors.Errorf("invalid amount %q", amount)
+	}
+	if !baseAmount.IsPositive() {
+		return cosmossdk.Int{}, errors.Errorf("amount %q must be positive", amount)
+	}
+	return baseAmount, nil
+}
+
+// Parse the amount in the base unit (e.g. "12340000" microusds).
+// The amount must be a positive integer in decimal digits that fits in sdk.Int (256 bits).
+func ParseBaseAmount(amount string) (cosmossdk.Int, error) {
+	if !baseAmountRegex.MatchString(amount) {
+		return cosmossdk.Int{}, errors.Errorf("invalid amount %q, it must be a positive integer without leading zeros", amount)
+	}
+	baseAmount, ok := cosmossdk.NewIntFromString(amount)
+	if !ok {
+		return cosmossdk.Int{}, errors.Errorf("invalid amount %q, it is out of range", amount)
+	}
+	return baseAmount, nil
+}
+
+// Return the amount of the request in the base unit.
+// The decimal string takes precedence, the int64 amount is accepted for the existing callers.
+// If both are set, they must be equal.
+func ResolveTokenAmount(amount string, legacyAmount int64) (cosmossdk.Int, error) {
+	if amount == "" {
+		if legacyAmount <= 0 {
+			return cosmossdk.Int{}, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "amount %d must be positive", legacyAmount)
+		}
+		return cosmossdk.NewInt(legacyAmount), nil
+	}
+
+	baseAmount, err := ParseBaseAmount(amount)
+	if err != nil {
+		return cosmossdk.Int{}, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "%v", err)
+	}
+	if legacyAmount != 0 && !baseAmount.Equal(cosmossdk.NewInt(legacyAmount)) {
+		return cosmossdk.Int{}, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"amount %q is different from the token amount %d", amount, legacyAmount)
+	}
+	return baseAmount, nil
+}
+
+// Return the amount as int64 for the int64 fields, 0 if the amount doesn't fit
+func tokenAmountInt64(amount cosmossdk.Int) int64 {
+	if !amount.IsInt64() {
+		return 0
+	}
+	return amount.Int64()
+}
+
+// Return the amount of the request in the base unit, from any of the display amount, the decimal string or the int64 amount.
+// The display amount is converted with the TokenDecimal of the asset config of the denom.
+func ResolveRequestAmount(denom string, displayAmount string, amount string, legacyAmount int64) (cosmossdk.Int, error) {
+	if displayAmount == "" {
+		return ResolveTokenAmount(amount, legacyAmount)
+	}
+
+	assetConfig := GetAssetConfigByDenom(denom)
+	if assetConfig == nil {
+		return cosmossdk.Int{}, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"denom %q has no asset config, the display amount is not supported", denom)
+	}
+	baseAmount, err := ParseDisplayAmount(displayAmount, assetConfig.TokenDecimal)
+	if err != nil {
+		return cosmossdk.Int{}, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "%v", err)
+	}
+
+	if amount != "" || legacyAmount != 0 {
+		otherAmount, err := ResolveTokenAmount(amount, legacyAmount)
+		if err != nil {
+			return cosmossdk.Int{}, err
+		}
+		if !baseAmount.Equal(otherAmount) {
+			return cosmossdk.Int{}, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+				"display amount %q %s is %s%s, it is different from the amount %s%s",
+				displayAmount, assetConfig.DisplayUnit, baseAmount, denom, otherAmount, denom)
+		}
+	}
+	return baseAmount, nil
+}
+
+// Format the amount in the base unit to the display unit (e.g. 12340000 microusds to "12.34"), without the trailing zeros
+func FormatDisplayAmount(amount cosmossdk.Int, tokenDecimal int) string {
+	digits := amount.Abs().String()
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+	}
+	if tokenDecimal <= 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= tokenDecimal {
+		digits = strings.Repeat("0", tokenDecimal-len(digits)+1) + digits
+	}
+	integerPart := digits[:len(digits)-tokenDecimal]
+	fractionalPart := strings.TrimRight(digits[len(digits)-tokenDecimal:], "0")
+	if fractionalPart == "" {
+		return sign + integerPart
+	}
+	return sign + integerPart + "." + fractionalPart
+}
+
+// Return the display amount and the display unit of the amount, empty if the denom has no asset config
+func GetDisplayAmount(denom string, amount cosmossdk.Int) (string, string) {
+	assetConfig := GetAssetConfigByDenom(denom)
+	if assetConfig == nil || amount.IsNil() {
+		return "", ""
+	}
+	return FormatDisplayAmount(amount, assetConfig.TokenDecimal), assetConfig.DisplayUnit
+}
@@ -0,0 +1,158 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib_test
+
+import (
+	lib "coreumservice/go/lib"
+	"coreumservicemsg"
+	"testing"
+
+	"coreumservice/go/stably_io/config"
+
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDisplayAmount(t *testing.T) {
+	validCases := []struct {
+		amount   string
+		expected string
+	}{
+		{amount: "1", expected: "1000000"},
+		{amount: "12.34", expected: "12340000"},
+		{amount: "0.000001", expected: "1"},
+		{amount: "123456789012345678901234567890", expected: "123456789012345678901234567890000000"},
+	}
+	for _, testCase := range validCases {
+		baseAmount, err := lib.ParseDisplayAmount(testCase.amount, 6)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, baseAmount.String())
+	}
+
+	invalidCases := []string{"", "0", "0.0", "-1", "1.", ".1", "1e6", "1,000", " 1", "0.0000001"}
+	for _, amount := range invalidCases {
+		_, err := lib.ParseDisplayAmount(amount, 6)
+		require.Error(t, err, amount)
+	}
+}
+
+func TestParseBaseAmount(t *testing.T) {
+	validCases := []string{"1", "1000000", "115792089237316195423570985008687907853269984665640564039457584007913129639935"}
+	for _, amount := range validCases {
+		baseAmount, err := lib.ParseBaseAmount(amount)
+		require.NoError(t, err)
+		require.Equal(t, amount, baseAmount.String())
+	}
+
+	invalidCases := []string{
+		"", "0", "01", "-1", "+1", "1.0", "1e6", "1,000", " 1", "1 ", "0x10",
+		// 2^256 is out of range
+		"115792089237316195423570985008687907853269984665640564039457584007913129639936",
+	}
+	for _, amount := range invalidCases {
+		_, err := lib.ParseBaseAmount(amount)
+		require.Error(t, err, amount)
+	}
+}
+
+func TestResolveTokenAmount(t *testing.T) {
+	// The int64 amount of the existing callers
+	amount, err := lib.ResolveTokenAmount("", 123)
+	require.NoError(t, err)
+	require.Equal(t, "123", amount.String())
+
+	// The decimal string beyond int64
+	amount, err = lib.ResolveTokenAmount("1000000000000000000000000", 0)
+	require.NoError(t, err)
+	require.Equal(t, "1000000000000000000000000", amount.String())
+
+	// Both are set and equal
+	amount, err = lib.ResolveTokenAmount("123", 123)
+	require.NoError(t, err)
+	require.Equal(t, "123", amount.String())
+
+	for _, testCase := range []struct {
+		amount       string
+		legacyAmount int64
+	}{
+		{amount: "", legacyAmount: 0},
+		{amount: "", legacyAmount: -1},
+		{amount: "124", legacyAmount: 123},
+		{amount: "12.3", legacyAmount: 0},
+	} {
+		_, err := lib.ResolveTokenAmount(testCase.amount, testCase.legacyAmount)
+		require.Error(t, err)
+		require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, lib.ToErrorReply(err).ErrorCode)
+	}
+}
+
+func TestFormatDisplayAmount(t *testing.T) {
+	cases := []struct {
+		amount   int64
+		expected string
+	}{
+		{amount: 0, expected: "0"},
+		{amount: 1, expected: "0.000001"},
+		{amount: 12340000, expected: "12.34"},
+		{amount: 1000000, expected: "1"},
+		{amount: -1500000, expected: "-1.5"},
+	}
+	for _, testCase := range cases {
+		require.Equal(t, testCase.expected, lib.FormatDisplayAmount(cosmossdk.NewInt(testCase.amount), 6))
+	}
+	require.Equal(t, "123", lib.FormatDisplayAmount(cosmossdk.NewInt(123), 0))
+
+	// The formatted amount is parsed back to the same amount
+	amount, ok := cosmossdk.NewIntFromString("123456789012345678901234567890")
+	require.True(t, ok)
+	parsedAmount, err := lib.ParseDisplayAmount(lib.FormatDisplayAmount(amount, 18), 18)
+	require.NoError(t, err)
+	require.Equal(t, amount.String(), parsedAmount.String())
+}
+
+func TestResolveRequestAmount(t *testing.T) {
+	usdsConfig := config.GetConfigDefault().Blockchain.Coreum.USDS
+
+	amount, err := lib.ResolveRequestAmount(usdsConfig.TokenDenom, "12.34", "", 0)
+	require.NoError(t, err)
+	require.Equal(t, "12340000", amount.String())
+
+	// All the amounts are set and equal
+	amount, err = lib.ResolveRequestAmount(usdsConfig.TokenDenom, "12.34", "12340000", 12340000)
+	require.NoError(t, err)
+	require.Equal(t, "12340000", amount.String())
+
+	// The display amount is ignored if it's not set
+	amount, err = lib.ResolveRequestAmount("utestcore", "", "", 5)
+	require.NoError(t, err)
+	require.Equal(t, "5", amount.String())
+
+	invalidCases := []struct {
+		name          string
+		denom         string
+		displayAmount string
+		amount        string
+	}{
+		{name: "excess precision is not rounded", denom: usdsConfig.TokenDenom, displayAmount: "12.3456789"},
+		{name: "different amounts", denom: usdsConfig.TokenDenom, displayAmount: "12.34", amount: "1234"},
+		{name: "denom without asset config", denom: "utestcore", displayAmount: "1"},
+	}
+	for _, testCase := range invalidCases {
+		_, err := lib.ResolveRequestAmount(testCase.denom, testCase.displayAmount, testCase.amount, 0)
+		require.Error(t, err, testCase.name)
+		require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, lib.ToErrorReply(err).ErrorCode, testCase.name)
+	}
+}
+
+func TestGetDisplayAmount(t *testing.T) {
+	usdsConfig := config.GetConfigDefault().Blockchain.Coreum.USDS
+
+	displayAmount, displayUnit := lib.GetDisplayAmount(usdsConfig.TokenDenom, cosmossdk.NewInt(12340000))
+	require.Equal(t, "12.34", displayAmount)
+	require.Equal(t, usdsConfig.DisplayUnit, displayUnit)
+
+	displayAmount, displayUnit = lib.GetDisplayAmount("utestcore", cosmossdk.NewInt(12340000))
+	require.Empty(t, displayAmount)
+	require.Empty(t, displayUnit)
+}
@@ -0,0 +1,296 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	"coreumservicemsg"
+	"time"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+)
+
+// The type URL of the messages executed with the send authorization
+var sendMsgTypeURL = cosmossdk.MsgTypeURL(&banktypes.MsgSend{})
+
+// The wallet signing the transfers
+type transferSigner struct {
+	signer        Signer
+	signerAddress cosmossdk.AccAddress
+	// The coins are sent from this address: the signer itself, or the granter of the send authorization of the signer
+	senderAddress cosmossdk.AccAddress
+}
+
+// The signer sending its own coins
+func newTransferSigner(signer Signer) *transferSigner {
+	return &transferSigner{
+		signer:        signer,
+		signerAddress: signer.GetAddress(),
+		senderAddress: signer.GetAddress(),
+	}
+}
+
+// Return the signer of the transfers of the treasury.
+// The operator of the treasury sends them with its send authorization if configured, so the treasury key isn't used.
+func getTreasuryTransferSigner(ctx context.Context, treasurySecretID string) (*transferSigner, error) {
+	assetConfig := config.GetConfigDefault().Blockchain.Coreum.USDS
+	if assetConfig.OperatorSecretID == "" || assetConfig.TreasurySecretID != treasurySecretID {
+		treasurySigner, err := GetSigner(ctx, treasurySecretID)
+		if err != nil {
+			return nil, err
+		}
+		return newTransferSigner(treasurySigner), nil
+	}
+
+	treasuryAddress, err := cosmossdk.AccAddressFromBech32(assetConfig.TreasuryAddress)
+	if err != nil {
+		return nil, errors.Errorf("invalid treasury address %q of the operator: %v", assetConfig.TreasuryAddress, err)
+	}
+	operatorSigner, err := GetSigner(ctx, assetConfig.OperatorSecretID)
+	if err != nil {
+		return nil, err
+	}
+	signer := newTransferSigner(operatorSigner)
+	signer.senderAddress = treasuryAddress
+	return signer, nil
+}
+
+// Whether the signer sends the coins of the granter with the send authorization
+func (s *transferSigner) isOperator() bool {
+	return !s.signerAddress.Equals(s.senderAddress)
+}
+
+// Wrap the messages sending the coins of the granter in the MsgExec of the operator
+func (s *transferSigner) wrapMsgs(msgs ...cosmossdk.Msg) []cosmossdk.Msg {
+	if !s.isOperator() {
+		return msgs
+	}
+	msgExec := authz.NewMsgExec(s.signerAddress, msgs)
+	return []cosmossdk.Msg{&msgExec}
+}
+
+// Same as PrepareTransferTransaction, the account number and the sequence of the transaction are the ones of the signer.
+// The client context has no keyring, the transaction is signed with CreateSignedTxWithSigner.
+func (s *transferSigner) prepareTransferTransaction(ctx context.Context,
+	recipientAddress string,
+	denom string,
+	toAmount cosmossdk.Int,
+	memo string,
+	sequenceNumber uint64,
+	gasPrice string,
+	gasUsed uint64,
+) (client.Context, client.Factory, cosmossdk.Msg, error) {
+	clientCtx, txFactory, msg, err := PrepareTransferTransaction(ctx,
+		nil,
+		s.signerAddress.String(),
+		recipientAddress,
+		denom,
+		toAmount,
+		memo,
+		sequenceNumber,
+		gasPrice,
+		gasUsed,
+	)
+	if err != nil || !s.isOperator() {
+		return clientCtx, txFactory, msg, err
+	}
+
+	msgSend, ok := msg.(*banktypes.MsgSend)
+	if !ok {
+		return client.Context{}, client.Factory{}, nil, errors.Errorf("unexpected transfer message %T", msg)
+	}
+	msgSend.FromAddress = s.senderAddress.String()
+	return clientCtx, txFactory, s.wrapMsgs(msgSend)[0], nil
+}
+
+// Refuse the coins above the remaining spend limit of the send authorization of the operator
+func (s *transferSigner) checkSpendLimit(ctx context.Context, coins cosmossdk.Coins) error {
+	if !s.isOperator() {
+		return nil
+	}
+	return CheckSendAuthorization(ctx, s.senderAddress.String(), s.signerAddress.String(), coins)
+}
+
+// Refuse the coins if the grantee has no send authorization of the granter, or if they exceed its remaining spend limit
+func CheckSendAuthorization(ctx context.Context, granterAddress string, granteeAddress string, coins cosmossdk.Coins) error {
+	res, err := authz.NewQueryClient(GetClientContext()).Grants(ctx, &authz.QueryGrantsRequest{
+		Granter: granterAddress,
+		Grantee: granteeAddress,
+	})
+	if err != nil {
+		return errors.Errorf("queryClient.Grants: %v", err)
+	}
+	return checkSendAuthorizationGrants(res.Grants, granterAddress, granteeAddress, coins, time.Now())
+}
+
+func checkSendAuthorizationGrants(grants []*authz.Grant,
+	granterAddress string,
+	granteeAddress string,
+	coins cosmossdk.Coins,
+	now time.Time,
+) error {
+	for _, grant := range grants {
+		// The expired grants are pruned only when they are used
+		if !grant.Expiration.After(now) {
+			continue
+		}
+		authorization, err := unpackAuthorization(grant)
+		if err != nil {
+			return err
+		}
+		if authorization.MsgTypeURL() != sendMsgTypeURL {
+			continue
+		}
+		sendAuthorization, ok := authorization.(*banktypes.SendAuthorization)
+		if !ok {
+			// The generic authorization has no spend limit
+			return nil
+		}
+		if _, isNegative := sendAuthorization.SpendLimit.SafeSub(coins); isNegative {
+			return NewServiceError(coreumservicemsg.ErrorCodeSpendLimitExceeded,
+				map[string]string{"amount": coins.String(), "spend_limit": sendAuthorization.SpendLimit.String()},
+				"the amount %v exceeds the remaining spend limit %v of %v", coins, sendAuthorization.SpendLimit, granteeAddress)
+		}
+		return nil
+	}
+	return NewServiceError(coreumservicemsg.ErrorCodeUnprocessable, nil,
+		"no send authorization from %v to %v", granterAddress, granteeAddress)
+}
+
+// Grant the send authorization capped by spendLimit from the granter wallet to the grantee
+func GrantSendAuthorization(ctx context.Context,
+	granterSecretID string,
+	granteeAddress string,
+	spendLimit cosmossdk.Coins,
+	expiration time.Time,
+) (*cosmossdk.TxResponse, string, error) {
+	granterSigner, granterAddress, err := getAuthorizationGranterWallet(ctx, granterSecretID)
+	if err != nil {
+		return nil, "", err
+	}
+	grantee, err := cosmossdk.AccAddressFromBech32(granteeAddress)
+	if err != nil {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid grantee address: %v", err)
+	}
+	if !expiration.After(time.Now()) {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "the expiration %v is not in the future", expiration)
+	}
+
+	msg, err := authz.NewMsgGrant(granterAddress, grantee, banktypes.NewSendAuthorization(spendLimit), expiration)
+	if err != nil {
+		return nil, "", errors.Errorf("authz.NewMsgGrant: %v", err)
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid send authorization: %v", err)
+	}
+
+	txResult, err := BroadcastMsgsWithSigner(ctx, granterSigner, "", msg)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "BroadcastMsgsWithSigner")
+	}
+	return txResult, granterAddress.String(), nil
+}
+
+// Revoke the send authorization granted by the granter wallet to the grantee
+func RevokeSendAuthorization(ctx context.Context, granterSecretID string, granteeAddress string) (*cosmossdk.TxResponse, string, error) {
+	granterSigner, granterAddress, err := getAuthorizationGranterWallet(ctx, granterSecretID)
+	if err != nil {
+		return nil, "", err
+	}
+	grantee, err := cosmossdk.AccAddressFromBech32(granteeAddress)
+	if err != nil {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid grantee address: %v", err)
+	}
+
+	msg := authz.NewMsgRevoke(granterAddress, grantee, sendMsgTypeURL)
+	txResult, err := BroadcastMsgsWithSigner(ctx, granterSigner, "", &msg)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "BroadcastMsgsWithSigner")
+	}
+	return txResult, granterAddress.String(), nil
+}
+
+// Return the signer and the address of the granter, the treasury of the config by default
+func getAuthorizationGranterWallet(ctx context.Context, granterSecretID string) (Signer, cosmossdk.AccAddress, error) {
+	if granterSecretID == "" {
+		granterSecretID = config.GetConfigDefault().Blockchain.Coreum.USDS.TreasurySecretID
+	}
+	granterSigner, err := GetSigner(ctx, granterSecretID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return granterSigner, granterSigner.GetAddress(), nil
+}
+
+// Return the authorizations of the grantee, only the ones of the granter if granterAddress is set
+func GetAuthorizations(ctx context.Context, granteeAddress string, granterAddress string) ([]*coreumservicemsg.Authorization, error) {
+	if _, err := cosmossdk.AccAddressFromBech32(granteeAddress); err != nil {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid grantee address: %v", err)
+	}
+
+	queryClient := authz.NewQueryClient(GetClientContext())
+	authorizations := []*coreumservicemsg.Authorization{}
+	var nextKey []byte
+	for {
+		res, err := queryClient.GranteeGrants(ctx, &authz.QueryGranteeGrantsRequest{
+			Grantee:    granteeAddress,
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, errors.Errorf("queryClient.GranteeGrants: %v", err)
+		}
+		for _, grant := range res.Grants {
+			if granterAddress != "" && grant.Granter != granterAddress {
+				continue
+			}
+			authorization, err := toAuthorization(grant)
+			if err != nil {
+				return nil, errors.Errorf("toAuthorization: %v", err)
+			}
+			authorizations = append(authorizations, authorization)
+		}
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return authorizations, nil
+		}
+		nextKey = res.Pagination.NextKey
+	}
+}
+
+func toAuthorization(grant *authz.GrantAuthorization) (*coreumservicemsg.Authorization, error) {
+	authorization := &coreumservicemsg.Authorization{
+		Granter:    grant.Granter,
+		Grantee:    grant.Grantee,
+		SpendLimit: []*coreumservicemsg.Coin{},
+		Expiration: grant.Expiration.Unix(),
+	}
+	if grant.Authorization == nil {
+		return authorization, nil
+	}
+	authorization.Type = grant.Authorization.TypeUrl
+
+	unpackedAuthorization, err := unpackAuthorization(&authz.Grant{Authorization: grant.Authorization})
+	if err != nil {
+		return nil, err
+	}
+	authorization.MsgTypeURL = unpackedAuthorization.MsgTypeURL()
+	if sendAuthorization, ok := unpackedAuthorization.(*banktypes.SendAuthorization); ok {
+		authorization.SpendLimit = toMsgCoins(sendAuthorization.SpendLimit)
+	}
+	return authorization, nil
+}
+
+// The gRPC client doesn't unpack the authorization
+func unpackAuthorization(grant *authz.Grant) (authz.Authorization, error) {
+	if grant.Authorization == nil || grant.Authorization.TypeUrl == "" {
+		return nil, errors.New("grant without authorization")
+	}
+	var authorization authz.Authorization
+	if err := GetAppEncodingConfig().InterfaceRegistry.UnpackAny(grant.Authorization, &authorization); err != nil {
+		return nil, errors.Errorf("UnpackAny(%v): %v", grant.Authorization.GetTypeUrl(), err)
+	}
+	return authorization, nil
+}
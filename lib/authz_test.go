@@ -0,0 +1,110 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"testing"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGrant(t *testing.T, authorization authz.Authorization, expiration time.Time) *authz.Grant {
+	authorizationAny, err := codectypes.NewAnyWithValue(authorization)
+	require.NoError(t, err)
+	// As received from the gRPC client, without the cached value
+	return &authz.Grant{
+		Authorization: &codectypes.Any{TypeUrl: authorizationAny.TypeUrl, Value: authorizationAny.Value},
+		Expiration:    expiration,
+	}
+}
+
+func TestCheckSendAuthorizationGrants(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	spendLimit := cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1000))
+	sendGrant := newTestGrant(t, banktypes.NewSendAuthorization(spendLimit), now.Add(time.Hour))
+
+	require.NoError(t, checkSendAuthorizationGrants([]*authz.Grant{sendGrant}, "granter", "grantee",
+		cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1000)), now))
+
+	err := checkSendAuthorizationGrants([]*authz.Grant{sendGrant}, "granter", "grantee",
+		cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1001)), now)
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeSpendLimitExceeded, errorReply.ErrorCode)
+	require.Equal(t, "1000usds", errorReply.Details["spend_limit"])
+
+	// The other denom isn't covered by the spend limit
+	err = checkSendAuthorizationGrants([]*authz.Grant{sendGrant}, "granter", "grantee",
+		cosmossdk.NewCoins(cosmossdk.NewInt64Coin("ucore", 1)), now)
+	require.Equal(t, coreumservicemsg.ErrorCodeSpendLimitExceeded, ToErrorReply(err).ErrorCode)
+
+	// The generic authorization of MsgSend has no spend limit
+	genericGrant := newTestGrant(t, authz.NewGenericAuthorization(sendMsgTypeURL), now.Add(time.Hour))
+	require.NoError(t, checkSendAuthorizationGrants([]*authz.Grant{genericGrant}, "granter", "grantee",
+		cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1000000)), now))
+
+	// Neither the expired grant nor the grant of the other messages allows the transfer
+	expiredGrant := newTestGrant(t, banktypes.NewSendAuthorization(spendLimit), now)
+	otherGrant := newTestGrant(t, authz.NewGenericAuthorization("/cosmos.bank.v1beta1.MsgMultiSend"), now.Add(time.Hour))
+	err = checkSendAuthorizationGrants([]*authz.Grant{expiredGrant, otherGrant}, "granter", "grantee",
+		cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1)), now)
+	require.Equal(t, coreumservicemsg.ErrorCodeUnprocessable, ToErrorReply(err).ErrorCode)
+}
+
+func TestToAuthorization(t *testing.T) {
+	expiration := time.Unix(1700000000, 0).UTC()
+	grant := newTestGrant(t, banktypes.NewSendAuthorization(cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1000))), expiration)
+
+	authorization, err := toAuthorization(&authz.GrantAuthorization{
+		Granter:       "granter",
+		Grantee:       "grantee",
+		Authorization: grant.Authorization,
+		Expiration:    expiration,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &coreumservicemsg.Authorization{
+		Granter:    "granter",
+		Grantee:    "grantee",
+		Type:       "/cosmos.bank.v1beta1.SendAuthorization",
+		MsgTypeURL: "/cosmos.bank.v1beta1.MsgSend",
+		SpendLimit: []*coreumservicemsg.Coin{{Amount: "1000", Denom: "usds"}},
+		Expiration: expiration.Unix(),
+	}, authorization)
+}
+
+func TestTransferSignerWrapMsgs(t *testing.T) {
+	mnemonicSigner, err := NewMnemonicSigner(offlineTestMnemonic)
+	require.NoError(t, err)
+	signer := newTransferSigner(mnemonicSigner)
+	require.False(t, signer.isOperator())
+
+	msgSend := &banktypes.MsgSend{
+		FromAddress: signer.signerAddress.String(),
+		ToAddress:   signer.signerAddress.String(),
+		Amount:      cosmossdk.NewCoins(cosmossdk.NewInt64Coin("usds", 1)),
+	}
+	require.Equal(t, []cosmossdk.Msg{msgSend}, signer.wrapMsgs(msgSend))
+
+	// The operator executes the transfer of the treasury
+	treasuryAddress, err := cosmossdk.AccAddressFromBech32(mustAddressFromMnemonic(t, offlineTestOtherMnemonic))
+	require.NoError(t, err)
+	signer.senderAddress = treasuryAddress
+	require.True(t, signer.isOperator())
+
+	msgSend.FromAddress = treasuryAddress.String()
+	msgs := signer.wrapMsgs(msgSend)
+	require.Len(t, msgs, 1)
+	msgExec, ok := msgs[0].(*authz.MsgExec)
+	require.True(t, ok)
+	require.Equal(t, signer.signerAddress.String(), msgExec.Grantee)
+	require.Equal(t, []cosmossdk.AccAddress{signer.signerAddress}, msgExec.GetSigners())
+	execMsgs, err := msgExec.GetMessages()
+	require.NoError(t, err)
+	require.Equal(t, []cosmossdk.Msg{msgSend}, execMsgs)
+}
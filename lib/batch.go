@@ -0,0 +1,296 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"fmt"
+	"sort"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+)
+
+// The share of the block gas limit a batch may use, leaving room for the other transactions of the block
+const maxBatchBlockGasShare = 0.5
+
+// A recipient of the batch transfer
+type BatchTransferLeg struct {
+	// The index of the recipient in the request
+	Index            int
+	RecipientAddress string
+	Amount           cosmossdk.Int
+}
+
+// The transaction of the batch, built and signed but not broadcast
+type plannedTransferBatch struct {
+	legs     []*BatchTransferLeg
+	msgs     []cosmossdk.Msg
+	gasUsed  uint64
+	gasPrice string
+}
+
+// Pay all the recipients from the treasury with as few transactions as possible.
+// The recipients are split into the batches that fit in the block gas limit, every batch is one transaction
+// with its own sequence. The failure of a batch doesn't stop the next batches, it's reported in the legs of the batch.
+func BatchTransferStablyToken(ctx context.Context,
+	senderSecretID string,
+	assetDenom string,
+	legs []*BatchTransferLeg,
+	memo string,
+	messageMode coreumservicemsg.BatchTransferMessageMode,
+	dryRun bool,
+) ([]*coreumservicemsg.TransferBatch, error) {
+	if len(legs) == 0 {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "no recipient")
+	}
+	if messageMode != "" && messageMode != coreumservicemsg.BatchTransferMessageModeMultiSend && messageMode != coreumservicemsg.BatchTransferMessageModeSend {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "unknown message mode %q", messageMode)
+	}
+
+	signer, err := getTreasuryTransferSigner(ctx, senderSecretID)
+	if err != nil {
+		return nil, err
+	}
+	// The send authorization of the operator only executes MsgSend
+	if signer.isOperator() {
+		if messageMode == coreumservicemsg.BatchTransferMessageModeMultiSend {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "the operator of the treasury only supports the send mode")
+		}
+		messageMode = coreumservicemsg.BatchTransferMessageModeSend
+	}
+	if messageMode == "" {
+		messageMode = coreumservicemsg.BatchTransferMessageModeMultiSend
+	}
+	// The sequences are the ones of the signer
+	senderAddress := signer.signerAddress
+
+	acc, err := GetAccountInfo(ctx, senderAddress.String())
+	if err != nil {
+		return nil, errors.Errorf("GetAccountInfo: %v", err)
+	}
+
+	maxBatchGas, err := getMaxBatchGas(ctx)
+	if err != nil {
+		return nil, errors.Errorf("getMaxBatchGas: %v", err)
+	}
+
+	clientCtx, err := getSenderClientContext(nil, senderAddress)
+	if err != nil {
+		return nil, errors.Errorf("getSenderClientContext: %v", err)
+	}
+	// The simulation expects the committed sequence
+	simulationTxFactory := CoreumTxFactory(clientCtx).
+		WithAccountNumber(acc.AccountNumber).
+		WithSequence(acc.Sequence).
+		WithMemo(memo)
+
+	planner := &transferBatchPlanner{
+		ctx:         ctx,
+		clientCtx:   clientCtx,
+		txFactory:   simulationTxFactory,
+		signer:      signer,
+		denom:       assetDenom,
+		messageMode: messageMode,
+		maxGas:      maxBatchGas,
+	}
+	plannedBatches, err := planner.plan(legs)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		// Refuse the whole batch transfer before broadcasting the first batch
+		total := cosmossdk.ZeroInt()
+		for _, leg := range legs {
+			total = total.Add(leg.Amount)
+		}
+		if err := signer.checkSpendLimit(ctx, cosmossdk.NewCoins(cosmossdk.NewCoin(assetDenom, total))); err != nil {
+			return nil, err
+		}
+	}
+
+	// The dry run doesn't reserve, the batches take the sequences following the ones already reserved or in flight
+	var nextSequence uint64
+	if dryRun {
+		status, err := GetSequenceManager().GetStatus(ctx, senderAddress.String())
+		if err != nil {
+			return nil, errors.Errorf("GetSequenceManager().GetStatus: %v", err)
+		}
+		nextSequence = status.NextSequence
+	}
+
+	batches := []*coreumservicemsg.TransferBatch{}
+	for i, plannedBatch := range plannedBatches {
+		var sequenceNumber uint64
+		if dryRun {
+			// The batches are expected to be broadcast in order right after the dry run
+			sequenceNumber = nextSequence + uint64(i)
+		} else {
+			sequenceNumber, err = GetSequenceManager().Reserve(ctx, senderAddress.String())
+			if err != nil {
+				return nil, errors.Errorf("GetSequenceManager().Reserve: %v", err)
+			}
+		}
+
+		batch := &coreumservicemsg.TransferBatch{
+			SequenceNumber: sequenceNumber,
+			GasPrice:       plannedBatch.gasPrice,
+			GasUsed:        plannedBatch.gasUsed,
+		}
+		batches = append(batches, batch)
+
+		txFactory := CoreumTxFactory(clientCtx).
+			WithAccountNumber(acc.AccountNumber).
+			WithSequence(sequenceNumber).
+			WithGasPrices(plannedBatch.gasPrice).
+			WithGas(plannedBatch.gasUsed).
+			WithMemo(memo)
+		_, signedTransactionInBytes, err := CreateSignedTxWithSigner(ctx, clientCtx, txFactory, signer.signer, plannedBatch.msgs...)
+		if err != nil {
+			if !dryRun {
+				GetSequenceManager().Release(senderAddress.String(), sequenceNumber)
+			}
+			return nil, errors.Wrap(err, "CreateSignedTxWithSigner")
+		}
+		_, batch.TxHash = CalculateHashOfTransaction(signedTransactionInBytes)
+
+		status := coreumservicemsg.BatchTransferLegStatusEstimated
+		errorMessage := ""
+		if !dryRun {
+			status = coreumservicemsg.BatchTransferLegStatusSubmitted
+			_, err = client.BroadcastRawTx(ctx, clientCtx, signedTransactionInBytes)
+			if err != nil {
+				// The batches already broadcast are still reported
+				if syncErr := GetSequenceManager().HandleBroadcastError(ctx, senderAddress.String(), sequenceNumber, err); syncErr != nil {
+					fmt.Printf("[sequence manager] Error from HandleBroadcastError(%v): %v\n", senderAddress.String(), syncErr)
+				}
+				status = coreumservicemsg.BatchTransferLegStatusFailed
+				errorMessage = err.Error()
+			} else {
+				GetSequenceManager().Track(senderAddress.String(), sequenceNumber, batch.TxHash)
+				recordSubmittedTransaction(batch.TxHash)
+			}
+		}
+
+		for _, leg := range plannedBatch.legs {
+			batch.Legs = append(batch.Legs, &coreumservicemsg.BatchTransferLegResult{
+				Index:            leg.Index,
+				RecipientAddress: leg.RecipientAddress,
+				Amount:           leg.Amount.String(),
+				TxHash:           batch.TxHash,
+				Status:           status,
+				ErrorMessage:     errorMessage,
+			})
+		}
+	}
+	return batches, nil
+}
+
+// Return the results of all the legs of the batches, in the order of the recipients in the request
+func GetBatchTransferLegResults(batches []*coreumservicemsg.TransferBatch) []*coreumservicemsg.BatchTransferLegResult {
+	legResults := []*coreumservicemsg.BatchTransferLegResult{}
+	for _, batch := range batches {
+		legResults = append(legResults, batch.Legs...)
+	}
+	sort.SliceStable(legResults, func(i, j int) bool {
+		return legResults[i].Index < legResults[j].Index
+	})
+	return legResults
+}
+
+// Return the maximum gas of a batch from the block gas limit of the chain, 0 if the block gas is unlimited
+func getMaxBatchGas(ctx context.Context) (uint64, error) {
+	consensusParams, err := GetTendermintRPCClient().ConsensusParams(ctx, nil)
+	if err != nil {
+		return 0, errors.Errorf("rpcClient.ConsensusParams: %v", err)
+	}
+	maxBlockGas := consensusParams.ConsensusParams.Block.MaxGas
+	if maxBlockGas <= 0 {
+		return 0, nil
+	}
+	return uint64(float64(maxBlockGas) * maxBatchBlockGasShare), nil
+}
+
+type transferBatchPlanner struct {
+	ctx         context.Context
+	clientCtx   client.Context
+	txFactory   client.Factory
+	signer      *transferSigner
+	denom       string
+	messageMode coreumservicemsg.BatchTransferMessageMode
+	// 0 if unlimited
+	maxGas uint64
+}
+
+// Estimate the gas of the legs in one transaction, and split them in halves until every batch fits in maxGas
+// and in the fee ceiling
+func (p *transferBatchPlanner) plan(legs []*BatchTransferLeg) ([]*plannedTransferBatch, error) {
+	msgs := p.signer.wrapMsgs(BuildBatchTransferMsgs(p.signer.senderAddress.String(), p.denom, legs, p.messageMode)...)
+	gasUsed, gasPrice, err := CalculateGas(p.ctx, p.clientCtx, p.txFactory, msgs...)
+	// The smaller batches may fit in the fee ceiling
+	feeCeilingExceeded := err != nil && ToErrorReply(err).ErrorCode == coreumservicemsg.ErrorCodeFeeCeilingExceeded && len(legs) > 1
+	if err != nil && !feeCeilingExceeded {
+		return nil, errors.Errorf("CalculateGas of %d recipients: %v", len(legs), err)
+	}
+
+	if !feeCeilingExceeded && (p.maxGas == 0 || gasUsed <= p.maxGas) {
+		return []*plannedTransferBatch{{
+			legs:     legs,
+			msgs:     msgs,
+			gasUsed:  gasUsed,
+			gasPrice: gasPrice,
+		}}, nil
+	}
+	if len(legs) == 1 {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeUnprocessable, nil,
+			"the transfer to %v needs %d gas, more than the batch limit %d", legs[0].RecipientAddress, gasUsed, p.maxGas)
+	}
+
+	firstHalf, err := p.plan(legs[:len(legs)/2])
+	if err != nil {
+		return nil, err
+	}
+	secondHalf, err := p.plan(legs[len(legs)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return append(firstHalf, secondHalf...), nil
+}
+
+// Build the messages paying the legs from the sender
+func BuildBatchTransferMsgs(senderAddress string,
+	denom string,
+	legs []*BatchTransferLeg,
+	messageMode coreumservicemsg.BatchTransferMessageMode,
+) []cosmossdk.Msg {
+	if messageMode == coreumservicemsg.BatchTransferMessageModeSend {
+		msgs := []cosmossdk.Msg{}
+		for _, leg := range legs {
+			msgs = append(msgs, &banktypes.MsgSend{
+				FromAddress: senderAddress,
+				ToAddress:   leg.RecipientAddress,
+				Amount:      cosmossdk.NewCoins(cosmossdk.NewCoin(denom, leg.Amount)),
+			})
+		}
+		return msgs
+	}
+
+	total := cosmossdk.ZeroInt()
+	outputs := []banktypes.Output{}
+	for _, leg := range legs {
+		total = total.Add(leg.Amount)
+		outputs = append(outputs, banktypes.Output{
+			Address: leg.RecipientAddress,
+			Coins:   cosmossdk.NewCoins(cosmossdk.NewCoin(denom, leg.Amount)),
+		})
+	}
+	return []cosmossdk.Msg{&banktypes.MsgMultiSend{
+		Inputs: []banktypes.Input{{
+			Address: senderAddress,
+			Coins:   cosmossdk.NewCoins(cosmossdk.NewCoin(denom, total)),
+		}},
+		Outputs: outputs,
+	}}
+}
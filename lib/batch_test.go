@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"testing"
+
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBatchTransferMsgs(t *testing.T) {
+	senderAddress := "testcore1av2q6yuaeqw5rqy958842fu6u9xzw62qjy8j3u"
+	legs := []*BatchTransferLeg{
+		{Index: 0, RecipientAddress: "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e", Amount: cosmossdk.NewInt(100)},
+		{Index: 1, RecipientAddress: "testcore1xjehmty2z5j7mfmpzxe8dgrf506c70n3747c95", Amount: cosmossdk.NewInt(250)},
+	}
+
+	t.Run("Case with MsgMultiSend", func(it *testing.T) {
+		msgs := BuildBatchTransferMsgs(senderAddress, "utestcore", legs, coreumservicemsg.BatchTransferMessageModeMultiSend)
+		require.Len(it, msgs, 1)
+
+		multiSend, ok := msgs[0].(*banktypes.MsgMultiSend)
+		require.True(it, ok)
+		require.Len(it, multiSend.Inputs, 1)
+		require.Equal(it, senderAddress, multiSend.Inputs[0].Address)
+		require.Equal(it, "350utestcore", multiSend.Inputs[0].Coins.String())
+		require.Len(it, multiSend.Outputs, 2)
+		require.Equal(it, legs[1].RecipientAddress, multiSend.Outputs[1].Address)
+		require.Equal(it, "250utestcore", multiSend.Outputs[1].Coins.String())
+
+		// The legs are extracted back from the message
+		extractedLegs, err := ExtractTransferLegs(multiSend)
+		require.NoError(it, err)
+		require.Len(it, extractedLegs, 2)
+		require.Equal(it, senderAddress, extractedLegs[0].FromAddress)
+	})
+
+	t.Run("Case with MsgSend", func(it *testing.T) {
+		msgs := BuildBatchTransferMsgs(senderAddress, "utestcore", legs, coreumservicemsg.BatchTransferMessageModeSend)
+		require.Len(it, msgs, 2)
+		for i, msg := range msgs {
+			bankSend, ok := msg.(*banktypes.MsgSend)
+			require.True(it, ok)
+			require.Equal(it, senderAddress, bankSend.FromAddress)
+			require.Equal(it, legs[i].RecipientAddress, bankSend.ToAddress)
+			require.Equal(it, legs[i].Amount, bankSend.Amount.AmountOf("utestcore"))
+		}
+	})
+}
+
+func TestGetBatchTransferLegResults(t *testing.T) {
+	batches := []*coreumservicemsg.TransferBatch{
+		{
+			TxHash: "HASH1",
+			Legs: []*coreumservicemsg.BatchTransferLegResult{
+				{Index: 2, TxHash: "HASH1"},
+				{Index: 0, TxHash: "HASH1"},
+			},
+		},
+		{
+			TxHash: "HASH2",
+			Legs: []*coreumservicemsg.BatchTransferLegResult{
+				{Index: 1, TxHash: "HASH2"},
+			},
+		},
+	}
+
+	legResults := GetBatchTransferLegResults(batches)
+	require.Len(t, legResults, 3)
+	for i, legResult := range legResults {
+		require.Equal(t, i, legResult.Index)
+	}
+	require.Equal(t, "HASH2", legResults[1].TxHash)
+}
@@ -1,20 +1,13 @@
 package coreumservicelib
 
 import (
-	"coreumservice/go/stably_io/config"
-	"crypto/tls"
-
 	"github.com/CoreumFoundation/coreum/pkg/client"
-	cosmosClient "github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/x/auth"
-	"github.com/pkg/errors"
-	"github.com/tendermint/tendermint/rpc/client/http"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	protobufgrpc "github.com/gogo/protobuf/grpc"
 )
 
 // Generate the base client context
@@ -27,24 +20,23 @@ func GetClientContext() client.Context {
 
 	gprcClient := GetGRPCClient()
 
-	cosmosClientCtx := client.NewContext(client.DefaultContextConfig(), modules).
+	contextConfig := client.DefaultContextConfig()
+	contextConfig.GasConfig = getClientGasConfig()
+
+	cosmosClientCtx := client.NewContext(contextConfig, modules).
 		WithChainID(GetChainIDByStage()).
 		WithGRPCClient(gprcClient).
 		WithKeyring(keyring.NewInMemory()).
-		WithBroadcastMode(flags.BroadcastBlock)
+		// Return as soon as the transaction is accepted by the mempool, see GetTransactionConfirmationStatus
+		WithBroadcastMode(flags.BroadcastSync)
 
 	return cosmosClientCtx // Config.Coreum.CosmosClientCtx
 }
 
-func GetGRPCClient() *grpc.ClientConn {
-	coreumConfig := config.GetConfigDefault().Blockchain.Coreum
-	// Configure client context and tx factory
-	// If you don't use TLS then replace `grpc.WithTransportCredentials()` with `grpc.WithInsecure()`
-	gprcClient, err := grpc.Dial(coreumConfig.PRCConfig.GRPCNodeURL, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
-	if err != nil {
-		panic(err)
-	}
-	return gprcClient
+// Return the gRPC client shared by all the callers.
+// The requests are routed to the healthy nodes and fail over to the next node, see NodePool
+func GetGRPCClient() protobufgrpc.ClientConn {
+	return GetNodePool().GRPCClient()
 }
 
 // Transaction Factory is generated from the client Context.
@@ -61,12 +53,8 @@ func CoreumTxFactory(clientCtx client.Context) tx.Factory {
 	return txFactory
 }
 
-func GetTendermintRPCClient() (*http.HTTP, error) {
-	coreumConfig := config.GetConfigDefault().Blockchain.Coreum
-	// Reference from Coreum team: https://pastebin.com/MgJS98Jz
-	rpcClient, err := cosmosClient.NewClientFromNode(coreumConfig.PRCConfig.TendermintRPCNodeURL)
-	if err != nil {
-		return nil, errors.Errorf("cosmosClient.NewClientFromNode: %v", err)
-	}
-	return rpcClient, nil
+// Return the Tendermint RPC client shared by all the callers.
+// The requests are routed to the healthy nodes and fail over to the next node, see NodePool
+func GetTendermintRPCClient() TendermintRPCClient {
+	return GetNodePool().TendermintRPCClient()
 }
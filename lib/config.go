@@ -1,6 +1,8 @@
 package coreumservicelib
 
 import (
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
 	"sync"
 
 	coreumconstant "github.com/CoreumFoundation/coreum/pkg/config/constant"
@@ -27,3 +29,15 @@ func init() {
 		config.Seal()
 	})
 }
+
+// Return the asset config of the denom, nil if the denom is not configured for this service
+func GetAssetConfigByDenom(denom string) *coreumconfig.CoreumAssetConfig {
+	coreumConfig := config.GetConfigDefault().Blockchain.Coreum
+	for _, assetConfig := range []coreumconfig.CoreumAssetConfig{coreumConfig.USDS} {
+		if assetConfig.TokenDenom == denom {
+			assetConfig := assetConfig
+			return &assetConfig
+		}
+	}
+	return nil
+}
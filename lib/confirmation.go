@@ -0,0 +1,113 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	"coreumservicemsg"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The submitted transaction is reported as submitted instead of not_found for this duration,
+// since the mempool of the queried node may not have received it yet
+const submittedTransactionTTL = 10 * time.Minute
+
+//nolint:gochecknoglobals // The transactions submitted by this instance
+var (
+	submittedTransactionsMu sync.Mutex
+	submittedTransactions   = map[string]time.Time{}
+)
+
+// Remember the transaction broadcast by this instance, until it's in a block or expired
+func recordSubmittedTransaction(txHash string) {
+	submittedTransactionsMu.Lock()
+	defer submittedTransactionsMu.Unlock()
+
+	now := time.Now()
+	for hash, submittedAt := range submittedTransactions {
+		if now.Sub(submittedAt) > submittedTransactionTTL {
+			delete(submittedTransactions, hash)
+		}
+	}
+	submittedTransactions[strings.ToUpper(txHash)] = now
+}
+
+func isSubmittedTransaction(txHash string) bool {
+	submittedTransactionsMu.Lock()
+	defer submittedTransactionsMu.Unlock()
+
+	submittedAt, ok := submittedTransactions[strings.ToUpper(txHash)]
+	return ok && time.Since(submittedAt) <= submittedTransactionTTL
+}
+
+func forgetSubmittedTransaction(txHash string) {
+	submittedTransactionsMu.Lock()
+	defer submittedTransactionsMu.Unlock()
+	delete(submittedTransactions, strings.ToUpper(txHash))
+}
+
+// Track the transaction until it has the required number of confirmations of the stage
+func GetTransactionConfirmationStatus(ctx context.Context, transactionHash string) (*coreumservicemsg.GetTransactionConfirmationStatusReply, error) {
+	transactionDetail, err := GetTransactionByHash(ctx, GetClientContext(), transactionHash)
+	if err != nil {
+		return nil, errors.Errorf("GetTransactionByHash: %v", err)
+	}
+
+	latestBlockHeight := int64(0)
+	if transactionDetail.Status == coreumservicemsg.TransactionStatusSuccess {
+		blockStatus, err := GetLatestBlockStatus(ctx)
+		if err != nil {
+			return nil, errors.Errorf("GetLatestBlockStatus: %v", err)
+		}
+		latestBlockHeight = blockStatus.LatestBlockHeight
+	}
+
+	requiredConfirmations := int64(config.GetConfigDefault().Blockchain.Coreum.RequiredNumberOfConfirmations)
+	confirmationStatus := toTransactionConfirmationStatus(transactionDetail, latestBlockHeight, requiredConfirmations, isSubmittedTransaction(transactionHash))
+	if transactionDetail.Height > 0 {
+		forgetSubmittedTransaction(transactionHash)
+	}
+	return confirmationStatus, nil
+}
+
+func toTransactionConfirmationStatus(transactionDetail *coreumservicemsg.CoreumTransactionDetail,
+	latestBlockHeight int64,
+	requiredConfirmations int64,
+	submitted bool,
+) *coreumservicemsg.GetTransactionConfirmationStatusReply {
+	confirmationStatus := &coreumservicemsg.GetTransactionConfirmationStatusReply{
+		TxHash:                transactionDetail.TxHash,
+		Height:                transactionDetail.Height,
+		RequiredConfirmations: requiredConfirmations,
+		Code:                  transactionDetail.Code,
+		RawLog:                transactionDetail.RawLog,
+	}
+
+	switch transactionDetail.Status {
+	case coreumservicemsg.TransactionStatusPending:
+		confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateSubmitted
+	case coreumservicemsg.TransactionStatusUnknown:
+		confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateUnknown
+	case coreumservicemsg.TransactionStatusFailed:
+		confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateFailed
+	case coreumservicemsg.TransactionStatusSuccess:
+		// The latest height may come from a node lagging behind the node serving the transaction
+		confirmationStatus.Confirmations = latestBlockHeight - transactionDetail.Height + 1
+		if confirmationStatus.Confirmations < 1 {
+			confirmationStatus.Confirmations = 1
+		}
+		confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateInBlock
+		if confirmationStatus.Confirmations >= requiredConfirmations {
+			confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateConfirmed
+		}
+	default:
+		confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateNotFound
+		if submitted {
+			confirmationStatus.State = coreumservicemsg.TransactionConfirmationStateSubmitted
+		}
+	}
+	return confirmationStatus
+}
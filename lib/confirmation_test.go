@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToTransactionConfirmationStatus(t *testing.T) {
+	cases := []struct {
+		name                  string
+		transactionDetail     *coreumservicemsg.CoreumTransactionDetail
+		latestBlockHeight     int64
+		submitted             bool
+		expectedState         coreumservicemsg.TransactionConfirmationState
+		expectedConfirmations int64
+	}{
+		{
+			name:              "Case with the unknown transaction",
+			transactionDetail: &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusNotFound},
+			expectedState:     coreumservicemsg.TransactionConfirmationStateNotFound,
+		},
+		{
+			name:              "Case with the submitted transaction not in the mempool of the node yet",
+			transactionDetail: &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusNotFound},
+			submitted:         true,
+			expectedState:     coreumservicemsg.TransactionConfirmationStateSubmitted,
+		},
+		{
+			name:              "Case with the transaction in the mempool",
+			transactionDetail: &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusPending},
+			expectedState:     coreumservicemsg.TransactionConfirmationStateSubmitted,
+		},
+		{
+			name:              "Case with the transaction beyond the checked transactions of the mempool",
+			transactionDetail: &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusUnknown},
+			expectedState:     coreumservicemsg.TransactionConfirmationStateUnknown,
+		},
+		{
+			name:              "Case with the failed transaction",
+			transactionDetail: &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusFailed, Height: 100, Code: 5},
+			latestBlockHeight: 110,
+			expectedState:     coreumservicemsg.TransactionConfirmationStateFailed,
+		},
+		{
+			name:                  "Case with the transaction in the latest block",
+			transactionDetail:     &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusSuccess, Height: 100},
+			latestBlockHeight:     100,
+			expectedState:         coreumservicemsg.TransactionConfirmationStateInBlock,
+			expectedConfirmations: 1,
+		},
+		{
+			name:                  "Case with the lagging node",
+			transactionDetail:     &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusSuccess, Height: 100},
+			latestBlockHeight:     98,
+			expectedState:         coreumservicemsg.TransactionConfirmationStateInBlock,
+			expectedConfirmations: 1,
+		},
+		{
+			name:                  "Case with the confirmed transaction",
+			transactionDetail:     &coreumservicemsg.CoreumTransactionDetail{Status: coreumservicemsg.TransactionStatusSuccess, Height: 100},
+			latestBlockHeight:     101,
+			expectedState:         coreumservicemsg.TransactionConfirmationStateConfirmed,
+			expectedConfirmations: 2,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(it *testing.T) {
+			confirmationStatus := toTransactionConfirmationStatus(testCase.transactionDetail, testCase.latestBlockHeight, 2, testCase.submitted)
+			require.Equal(it, testCase.expectedState, confirmationStatus.State)
+			require.Equal(it, testCase.expectedConfirmations, confirmationStatus.Confirmations)
+			require.Equal(it, int64(2), confirmationStatus.RequiredConfirmations)
+		})
+	}
+}
+
+func TestSubmittedTransactions(t *testing.T) {
+	txHash := "dd4814669e9bfeebffdeeb4264bab30e85fddc5da0158cd1194228e6569e4cab"
+	require.False(t, isSubmittedTransaction(txHash))
+
+	recordSubmittedTransaction(txHash)
+	require.True(t, isSubmittedTransaction("DD4814669E9BFEEBFFDEEB4264BAB30E85FDDC5DA0158CD1194228E6569E4CAB"))
+
+	forgetSubmittedTransaction(txHash)
+	require.False(t, isSubmittedTransaction(txHash))
+}
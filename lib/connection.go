@@ -0,0 +1,136 @@
+package coreumservicelib
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// Ping the gRPC node if there's no activity, to detect the dead connection early
+	grpcKeepAliveTime    = 30 * time.Second
+	grpcKeepAliveTimeout = 10 * time.Second
+	// Timeout of a single Tendermint RPC request
+	tendermintRPCRequestTimeout = 30 * time.Second
+)
+
+// ConnectionManager holds the long-lived connections to a blockchain node.
+// The connections are dialed lazily on the first use, shared by all the callers,
+// re-dialed if they were shut down, and released by Close().
+type ConnectionManager struct {
+	grpcNodeURL          string
+	tendermintRPCNodeURL string
+
+	mu                  sync.Mutex
+	closed              bool
+	grpcConn            *grpc.ClientConn
+	tendermintRPCClient *tmhttp.HTTP
+	httpTransport       *http.Transport
+}
+
+func NewConnectionManager(grpcNodeURL string, tendermintRPCNodeURL string) *ConnectionManager {
+	return &ConnectionManager{
+		grpcNodeURL:          grpcNodeURL,
+		tendermintRPCNodeURL: tendermintRPCNodeURL,
+	}
+}
+
+// Return the shared gRPC connection, dial it if it is not dialed yet or it was shut down
+func (m *ConnectionManager) GRPCConn() (*grpc.ClientConn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, errors.New("connection manager is closed")
+	}
+
+	if m.grpcConn != nil {
+		switch m.grpcConn.GetState() {
+		case connectivity.Shutdown:
+			// The connection can't be reused anymore, dial a new one below
+			m.grpcConn = nil
+		case connectivity.TransientFailure:
+			// Try to reconnect immediately instead of waiting for the backoff
+			m.grpcConn.ResetConnectBackoff()
+			return m.grpcConn, nil
+		default:
+			return m.grpcConn, nil
+		}
+	}
+
+	// grpc.Dial doesn't block, the connection is established on the first call
+	grpcConn, err := grpc.Dial(m.grpcNodeURL,
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                grpcKeepAliveTime,
+			Timeout:             grpcKeepAliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		return nil, errors.Errorf("grpc.Dial(%v): %v", m.grpcNodeURL, err)
+	}
+	m.grpcConn = grpcConn
+	return m.grpcConn, nil
+}
+
+// Return the shared Tendermint RPC client.
+// All the requests share the same HTTP transport, so the idle connections are reused.
+func (m *ConnectionManager) TendermintRPCClient() (*tmhttp.HTTP, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, errors.New("connection manager is closed")
+	}
+	if m.tendermintRPCClient != nil {
+		return m.tendermintRPCClient, nil
+	}
+
+	// Reference from Coreum team: https://pastebin.com/MgJS98Jz
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	rpcClient, err := tmhttp.NewWithClient(m.tendermintRPCNodeURL, "/websocket", &http.Client{
+		Transport: transport,
+		Timeout:   tendermintRPCRequestTimeout,
+	})
+	if err != nil {
+		return nil, errors.Errorf("tmhttp.NewWithClient(%v): %v", m.tendermintRPCNodeURL, err)
+	}
+	m.httpTransport = transport
+	m.tendermintRPCClient = rpcClient
+	return m.tendermintRPCClient, nil
+}
+
+// Release all the connections, the manager can't be used after being closed
+func (m *ConnectionManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	if m.httpTransport != nil {
+		m.httpTransport.CloseIdleConnections()
+		m.httpTransport = nil
+	}
+	m.tendermintRPCClient = nil
+
+	if m.grpcConn != nil {
+		err := m.grpcConn.Close()
+		m.grpcConn = nil
+		if err != nil {
+			return errors.Errorf("grpcConn.Close: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib_test
+
+import (
+	"context"
+	lib "coreumservice/go/lib"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/require"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConnectionManager(t *testing.T) {
+	nodeConfig := config.GetConfigDefault().Blockchain.Coreum.PRCConfig.Nodes[0]
+	manager := lib.NewConnectionManager(nodeConfig.GRPCNodeURL, nodeConfig.TendermintRPCNodeURL)
+
+	// The connections are shared by the callers
+	grpcConn, err := manager.GRPCConn()
+	require.NoError(t, err)
+	grpcConn2, err := manager.GRPCConn()
+	require.NoError(t, err)
+	require.Same(t, grpcConn, grpcConn2)
+
+	rpcClient, err := manager.TendermintRPCClient()
+	require.NoError(t, err)
+	rpcClient2, err := manager.TendermintRPCClient()
+	require.NoError(t, err)
+	require.Same(t, rpcClient, rpcClient2)
+
+	// The manager can't be used after being closed
+	require.NoError(t, manager.Close())
+	require.NoError(t, manager.Close())
+	_, err = manager.GRPCConn()
+	require.Error(t, err)
+	_, err = manager.TendermintRPCClient()
+	require.Error(t, err)
+}
+
+func TestNodePool(t *testing.T) {
+	ctx := context.Background()
+	rpcConfig := config.GetConfigDefault().Blockchain.Coreum.PRCConfig
+
+	// The unreachable node is ejected by the health check
+	nodes := append([]coreumconfig.CoreumNodeConfig{{
+		Name:                 "unreachable",
+		GRPCNodeURL:          "localhost:1",
+		TendermintRPCNodeURL: "http://localhost:1",
+	}}, rpcConfig.Nodes...)
+	pool, err := lib.NewNodePool(nodes, rpcConfig.MaxNodeBlockLag, rpcConfig.NodeHealthCheckInterval)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	pool.CheckHealth(ctx)
+	statuses := pool.GetNodeStatuses()
+	require.Len(t, statuses, len(nodes))
+	require.False(t, statuses[0].Healthy)
+	require.NotEmpty(t, statuses[0].LastError)
+
+	// The requests are served by the healthy nodes
+	blockStatus, err := lib.GetLatestBlockStatusFromClient(ctx, pool.TendermintRPCClient())
+	require.NoError(t, err)
+	require.False(t, blockStatus.CatchingUp)
+}
+
+// The fake Tendermint RPC node replying the status at the height
+func newFakeTendermintRPCNode(t *testing.T, height int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := rpctypes.RPCRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "status", request.Method)
+
+		result, err := tmjson.Marshal(&coretypes.ResultStatus{
+			SyncInfo: coretypes.SyncInfo{LatestBlockHeight: height},
+		})
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(rpctypes.RPCResponse{
+			JSONRPC: "2.0",
+			ID:      request.ID,
+			Result:  result,
+		}))
+	}))
+}
+
+func TestNodePoolTendermintRPCFailover(t *testing.T) {
+	ctx := context.Background()
+	fakeNode := newFakeTendermintRPCNode(t, 100)
+	defer fakeNode.Close()
+
+	pool, err := lib.NewNodePool([]coreumconfig.CoreumNodeConfig{{
+		Name:                 "unreachable",
+		GRPCNodeURL:          "localhost:1",
+		TendermintRPCNodeURL: "http://localhost:1",
+	}, {
+		Name:                 "fake",
+		GRPCNodeURL:          "localhost:1",
+		TendermintRPCNodeURL: fakeNode.URL,
+	}}, 10, time.Minute)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// The unreachable node is tried first before the health check, the request fails over to the next node
+	blockStatus, err := lib.GetLatestBlockStatusFromClient(ctx, pool.TendermintRPCClient())
+	require.NoError(t, err)
+	require.Equal(t, int64(100), blockStatus.LatestBlockHeight)
+
+	// The unreachable node is ejected until the next health check
+	statuses := pool.GetNodeStatuses()
+	require.False(t, statuses[0].Healthy)
+	require.NotEmpty(t, statuses[0].LastError)
+	require.True(t, statuses[1].Healthy)
+}
+
+func TestNewNodePoolInvalidConfig(t *testing.T) {
+	nodes := []coreumconfig.CoreumNodeConfig{{
+		Name:                 "unreachable",
+		GRPCNodeURL:          "localhost:1",
+		TendermintRPCNodeURL: "http://localhost:1",
+	}}
+
+	_, err := lib.NewNodePool(nil, 10, time.Minute)
+	require.Error(t, err)
+	_, err = lib.NewNodePool(nodes, 10, 0)
+	require.Error(t, err)
+}
+
+func TestNodePoolBroadcastNotFailedOver(t *testing.T) {
+	ctx := context.Background()
+	pool, err := lib.NewNodePool([]coreumconfig.CoreumNodeConfig{{
+		Name:                 "unreachable",
+		GRPCNodeURL:          "localhost:1",
+		TendermintRPCNodeURL: "http://localhost:1",
+	}, {
+		Name:                 "other unreachable",
+		GRPCNodeURL:          "localhost:1",
+		TendermintRPCNodeURL: "http://localhost:1",
+	}}, 10, time.Minute)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// The broadcast is only sent to the first node
+	txClient := txtypes.NewServiceClient(pool.GRPCClient())
+	_, err = txClient.BroadcastTx(ctx, &txtypes.BroadcastTxRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	statuses := pool.GetNodeStatuses()
+	require.False(t, statuses[0].Healthy)
+	require.True(t, statuses[1].Healthy)
+
+	// The queries fail over to the next node
+	_, err = txClient.GetTx(ctx, &txtypes.GetTxRequest{Hash: "ABCD"})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	statuses = pool.GetNodeStatuses()
+	require.False(t, statuses[1].Healthy)
+}
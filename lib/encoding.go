@@ -0,0 +1,35 @@
+package coreumservicelib
+
+import (
+	"sync"
+
+	app "github.com/CoreumFoundation/coreum/app"
+	coreumappconfig "github.com/CoreumFoundation/coreum/pkg/config"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+)
+
+//nolint:gochecknoglobals // Building the encoding config of all the modules is expensive, it's built once
+var (
+	appEncodingConfig     coreumappconfig.EncodingConfig
+	appEncodingConfigOnce sync.Once
+)
+
+// Return the encoding config of all the Coreum modules,
+// so every message type (bank, authz, assetft, assetnft, wasm,...) can be decoded
+func GetAppEncodingConfig() coreumappconfig.EncodingConfig {
+	appEncodingConfigOnce.Do(func() {
+		appEncodingConfig = coreumappconfig.NewEncodingConfig(app.ModuleBasics)
+	})
+	return appEncodingConfig
+}
+
+// Decode the protobuf transaction bytes, the messages are unpacked so tx.GetMsgs() can be used
+func DecodeTx(txBytes []byte) (*txtypes.Tx, error) {
+	tx := &txtypes.Tx{}
+	err := GetAppEncodingConfig().Codec.Unmarshal(txBytes, tx)
+	if err != nil {
+		return nil, errors.Errorf("encodingConfig.Codec.Unmarshal: %v", err)
+	}
+	return tx, nil
+}
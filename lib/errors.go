@@ -0,0 +1,135 @@
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+var httpStatusByErrorCode = map[coreumservicemsg.ErrorCode]int{
+	coreumservicemsg.ErrorCodeInvalidRequest:     http.StatusBadRequest,
+	coreumservicemsg.ErrorCodeForbidden:          http.StatusForbidden,
+	coreumservicemsg.ErrorCodeNotFound:           http.StatusNotFound,
+	coreumservicemsg.ErrorCodeConflict:           http.StatusConflict,
+	coreumservicemsg.ErrorCodeUnprocessable:      http.StatusUnprocessableEntity,
+	coreumservicemsg.ErrorCodeFeeCeilingExceeded: http.StatusUnprocessableEntity,
+	coreumservicemsg.ErrorCodeSpendLimitExceeded: http.StatusUnprocessableEntity,
+	coreumservicemsg.ErrorCodeChainError:         http.StatusBadGateway,
+	coreumservicemsg.ErrorCodeChainUnavailable:   http.StatusServiceUnavailable,
+	coreumservicemsg.ErrorCodeSecretUnavailable:  http.StatusServiceUnavailable,
+	coreumservicemsg.ErrorCodeInternal:           http.StatusInternalServerError,
+}
+
+// The known error messages from the blockchain node (and the service) and the corresponding error codes.
+// They are used to classify the errors that are not typed, e.g. the errors flattened by errors.Errorf("...: %v", err)
+var chainErrorPatterns = []struct {
+	pattern string
+	code    coreumservicemsg.ErrorCode
+}{
+	{pattern: "tx not found", code: coreumservicemsg.ErrorCodeNotFound},
+	{pattern: "account sequence mismatch", code: coreumservicemsg.ErrorCodeConflict},
+	{pattern: "tx already exists in cache", code: coreumservicemsg.ErrorCodeConflict},
+	// Wrapped in the insufficient funds error by the chain
+	{pattern: "requested amount is more than spend limit", code: coreumservicemsg.ErrorCodeSpendLimitExceeded},
+	{pattern: "authorization not found", code: coreumservicemsg.ErrorCodeUnprocessable},
+	{pattern: "insufficient funds", code: coreumservicemsg.ErrorCodeUnprocessable},
+	{pattern: "insufficient fee", code: coreumservicemsg.ErrorCodeUnprocessable},
+	{pattern: "out of gas", code: coreumservicemsg.ErrorCodeUnprocessable},
+	{pattern: "exceeds the fee ceiling", code: coreumservicemsg.ErrorCodeFeeCeilingExceeded},
+	{pattern: "decoding bech32 failed", code: coreumservicemsg.ErrorCodeInvalidRequest},
+	{pattern: "invalid coins", code: coreumservicemsg.ErrorCodeInvalidRequest},
+	{pattern: "context deadline exceeded", code: coreumservicemsg.ErrorCodeChainUnavailable},
+	{pattern: "connection refused", code: coreumservicemsg.ErrorCodeChainUnavailable},
+	{pattern: "mempool is full", code: coreumservicemsg.ErrorCodeChainUnavailable},
+}
+
+// The gRPC status codes of the node errors not matched by chainErrorPatterns.
+// Only the internal errors of the node are the chain errors, the others are caused by the request.
+var grpcErrorCodes = map[codes.Code]coreumservicemsg.ErrorCode{
+	codes.NotFound:           coreumservicemsg.ErrorCodeNotFound,
+	codes.InvalidArgument:    coreumservicemsg.ErrorCodeInvalidRequest,
+	codes.OutOfRange:         coreumservicemsg.ErrorCodeInvalidRequest,
+	codes.AlreadyExists:      coreumservicemsg.ErrorCodeConflict,
+	codes.FailedPrecondition: coreumservicemsg.ErrorCodeUnprocessable,
+	codes.PermissionDenied:   coreumservicemsg.ErrorCodeForbidden,
+	codes.Unauthenticated:    coreumservicemsg.ErrorCodeForbidden,
+	codes.DeadlineExceeded:   coreumservicemsg.ErrorCodeChainUnavailable,
+	codes.Unavailable:        coreumservicemsg.ErrorCodeChainUnavailable,
+	codes.ResourceExhausted:  coreumservicemsg.ErrorCodeChainUnavailable,
+	codes.Internal:           coreumservicemsg.ErrorCodeChainError,
+	codes.Unknown:            coreumservicemsg.ErrorCodeChainError,
+}
+
+// e.g. "rpc error: code = NotFound desc = tx not found", also in the flattened errors
+var grpcStatusPattern = regexp.MustCompile(`rpc error: code = (\w+) desc`)
+
+// Create the typed error which is returned to the caller with the corresponding HTTP status code
+func NewServiceError(code coreumservicemsg.ErrorCode, details map[string]string, format string, args ...interface{}) error {
+	return &coreumservicemsg.Error{
+		ErrorMessage: fmt.Sprintf(format, args...),
+		ErrorCode:    code,
+		Retryable:    code.IsRetryable(),
+		Details:      details,
+	}
+}
+
+// Convert any error to the error reply.
+// The typed error in the chain takes precedence, otherwise the error is classified by its message.
+func ToErrorReply(err error) *coreumservicemsg.Error {
+	if err == nil {
+		return nil
+	}
+
+	code := coreumservicemsg.ErrorCodeInternal
+	var details map[string]string
+
+	var serviceErr *coreumservicemsg.Error
+	if errors.As(err, &serviceErr) {
+		code = serviceErr.ErrorCode
+		details = serviceErr.Details
+	} else {
+		code = classifyErrorMessage(err.Error())
+	}
+
+	return &coreumservicemsg.Error{
+		// Keep the whole chain of the messages for debugging
+		ErrorMessage: err.Error(),
+		ErrorCode:    code,
+		Retryable:    code.IsRetryable(),
+		Details:      details,
+	}
+}
+
+// Return the HTTP status code of the error code, 500 if the code is unknown
+func GetHTTPStatusOfErrorCode(code coreumservicemsg.ErrorCode) int {
+	status, ok := httpStatusByErrorCode[code]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+func classifyErrorMessage(message string) coreumservicemsg.ErrorCode {
+	lowerMessage := strings.ToLower(message)
+	for _, p := range chainErrorPatterns {
+		if strings.Contains(lowerMessage, p.pattern) {
+			return p.code
+		}
+	}
+
+	match := grpcStatusPattern.FindStringSubmatch(message)
+	if match == nil {
+		return coreumservicemsg.ErrorCodeInternal
+	}
+	for grpcCode, code := range grpcErrorCodes {
+		if grpcCode.String() == match[1] {
+			return code
+		}
+	}
+	return coreumservicemsg.ErrorCodeInternal
+}
@@ -0,0 +1,114 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib_test
+
+import (
+	lib "coreumservice/go/lib"
+	"coreumservicemsg"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToErrorReply(t *testing.T) {
+	cases := []struct {
+		name          string
+		err           error
+		expectedCode  coreumservicemsg.ErrorCode
+		expectedRetry bool
+		expectedHTTP  int
+	}{
+		{
+			name:          "Typed error wrapped by errors.Wrap",
+			err:           errors.Wrap(lib.NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, nil, "missing secret"), "TransferStablyToken"),
+			expectedCode:  coreumservicemsg.ErrorCodeSecretUnavailable,
+			expectedRetry: true,
+			expectedHTTP:  http.StatusServiceUnavailable,
+		},
+		{
+			name:          "Flattened sequence mismatch error",
+			err:           errors.Errorf("client.BroadcastTx: account sequence mismatch, expected 10, got 9: incorrect account sequence"),
+			expectedCode:  coreumservicemsg.ErrorCodeConflict,
+			expectedRetry: true,
+			expectedHTTP:  http.StatusConflict,
+		},
+		{
+			name:          "Flattened insufficient funds error",
+			err:           errors.Errorf("client.BroadcastTx: 1usds is smaller than 2usds: insufficient funds"),
+			expectedCode:  coreumservicemsg.ErrorCodeUnprocessable,
+			expectedRetry: false,
+			expectedHTTP:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "Flattened spend limit error",
+			err:           errors.Errorf("client.BroadcastTx: failed to execute message; message index: 0: requested amount is more than spend limit: insufficient funds"),
+			expectedCode:  coreumservicemsg.ErrorCodeSpendLimitExceeded,
+			expectedRetry: false,
+			expectedHTTP:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "Flattened fee ceiling error",
+			err:           errors.Errorf("CreateSignedTx: the fee 3000000ucore exceeds the fee ceiling 2000000ucore"),
+			expectedCode:  coreumservicemsg.ErrorCodeFeeCeilingExceeded,
+			expectedRetry: true,
+			expectedHTTP:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "Flattened chain timeout",
+			err:           errors.Errorf("GetAccountInfo: rpc error: code = DeadlineExceeded desc = context deadline exceeded"),
+			expectedCode:  coreumservicemsg.ErrorCodeChainUnavailable,
+			expectedRetry: true,
+			expectedHTTP:  http.StatusServiceUnavailable,
+		},
+		{
+			name:          "gRPC not found error",
+			err:           errors.Wrap(status.Error(codes.NotFound, "account testcore1abc not found"), "GetAccountInfo"),
+			expectedCode:  coreumservicemsg.ErrorCodeNotFound,
+			expectedRetry: false,
+			expectedHTTP:  http.StatusNotFound,
+		},
+		{
+			name:          "Flattened gRPC invalid argument error",
+			err:           errors.Errorf("GetTransactionByHash: rpc error: code = InvalidArgument desc = invalid tx hash"),
+			expectedCode:  coreumservicemsg.ErrorCodeInvalidRequest,
+			expectedRetry: false,
+			expectedHTTP:  http.StatusBadRequest,
+		},
+		{
+			name:          "Flattened gRPC permission denied error",
+			err:           errors.Errorf("GetBalance: rpc error: code = PermissionDenied desc = not allowed"),
+			expectedCode:  coreumservicemsg.ErrorCodeForbidden,
+			expectedRetry: false,
+			expectedHTTP:  http.StatusForbidden,
+		},
+		{
+			name:          "Flattened gRPC internal error",
+			err:           errors.Errorf("client.BroadcastTx: rpc error: code = Internal desc = panic"),
+			expectedCode:  coreumservicemsg.ErrorCodeChainError,
+			expectedRetry: true,
+			expectedHTTP:  http.StatusBadGateway,
+		},
+		{
+			name:          "Unknown error",
+			err:           errors.New(`unexpected "quoted" error`),
+			expectedCode:  coreumservicemsg.ErrorCodeInternal,
+			expectedRetry: false,
+			expectedHTTP:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(it *testing.T) {
+			reply := lib.ToErrorReply(testCase.err)
+			require.Equal(it, testCase.expectedCode, reply.ErrorCode)
+			require.Equal(it, testCase.expectedRetry, reply.Retryable)
+			require.Equal(it, testCase.err.Error(), reply.ErrorMessage)
+			require.Equal(it, testCase.expectedHTTP, lib.GetHTTPStatusOfErrorCode(reply.ErrorCode))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	"coreumservicemsg"
+	"time"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	txsigning "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/pkg/errors"
+)
+
+// Return the fee granter of the sending wallet from the config, nil if the wallet pays its own fee
+func GetFeeGranterAddress(senderAddress string) (cosmossdk.AccAddress, error) {
+	return getFeeGranterAddress(config.GetConfigDefault().Blockchain.Coreum.FeeGrant.Granters, senderAddress)
+}
+
+func getFeeGranterAddress(granters map[string]string, senderAddress string) (cosmossdk.AccAddress, error) {
+	granter, ok := granters[senderAddress]
+	if !ok || granter == "" {
+		return nil, nil
+	}
+	granterAddress, err := cosmossdk.AccAddressFromBech32(granter)
+	if err != nil {
+		return nil, errors.Errorf("invalid fee granter %q of %v: %v", granter, senderAddress, err)
+	}
+	return granterAddress, nil
+}
+
+// Return the client context of the sending wallet, with the fee granter of the wallet if any
+func getSenderClientContext(signingKeyring keyring.Keyring, senderAddress cosmossdk.AccAddress) (client.Context, error) {
+	feeGranter, err := GetFeeGranterAddress(senderAddress.String())
+	if err != nil {
+		return client.Context{}, errors.Errorf("GetFeeGranterAddress: %v", err)
+	}
+	return GetClientContext().
+		// Assign the keyring that has the private key to sign the transaction
+		WithKeyring(signingKeyring).
+		// From the specific address
+		WithFromAddress(senderAddress).
+		// The granter pays the fee, nil if the sender pays
+		WithFeeGranterAddress(feeGranter), nil
+}
+
+// Same as client.CalculateGas, with the fee granter of the client context in the simulated transaction
+func calculateGasWithFeeGranter(ctx context.Context, clientCtx client.Context, txf client.Factory, msgs ...cosmossdk.Msg) (*sdktx.SimulateResponse, uint64, error) {
+	// The simulation accepts any public key of the default type, the signature is not checked
+	return calculateGasWithSignature(ctx, clientCtx, txf, txsigning.SignatureV2{
+		PubKey:   &secp256k1.PubKey{},
+		Data:     &txsigning.SingleSignatureData{SignMode: txf.SignMode()},
+		Sequence: txf.Sequence(),
+	}, msgs...)
+}
+
+// Simulate the transaction with the placeholder signature and the fee granter of the client context
+func calculateGasWithSignature(ctx context.Context,
+	clientCtx client.Context,
+	txf client.Factory,
+	simSignature txsigning.SignatureV2,
+	msgs ...cosmossdk.Msg,
+) (*sdktx.SimulateResponse, uint64, error) {
+	unsignedTx, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, 0, errors.Errorf("txf.BuildUnsignedTx: %v", err)
+	}
+	unsignedTx.SetFeeGranter(clientCtx.FeeGranterAddress())
+
+	err = unsignedTx.SetSignatures(simSignature)
+	if err != nil {
+		return nil, 0, errors.Errorf("unsignedTx.SetSignatures: %v", err)
+	}
+	txBytes, err := clientCtx.TxConfig().TxEncoder()(unsignedTx.GetTx())
+	if err != nil {
+		return nil, 0, errors.Errorf("TxEncoder: %v", err)
+	}
+
+	simRes, err := sdktx.NewServiceClient(clientCtx).Simulate(ctx, &sdktx.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return nil, 0, errors.Errorf("transaction estimation failed: %v", err)
+	}
+
+	gasAdjustment := txf.GasAdjustment()
+	if gasAdjustment == 0 {
+		gasAdjustment = clientCtx.GasAdjustment()
+	}
+	return simRes, uint64(gasAdjustment * float64(simRes.GasInfo.GasUsed)), nil
+}
+
+// Grant the fee allowance from the granter wallet to the grantee.
+// The allowance is unlimited if spendLimit is empty, it never expires if expiration is nil,
+// and it's restricted to allowedMessages (type URLs) if any.
+func GrantFeeAllowance(ctx context.Context,
+	granterSecretID string,
+	granteeAddress string,
+	spendLimit cosmossdk.Coins,
+	expiration *time.Time,
+	allowedMessages []string,
+) (*cosmossdk.TxResponse, string, error) {
+	granterSigner, granterAddress, err := getFeeGranterWallet(ctx, granterSecretID)
+	if err != nil {
+		return nil, "", err
+	}
+	grantee, err := cosmossdk.AccAddressFromBech32(granteeAddress)
+	if err != nil {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid grantee address: %v", err)
+	}
+
+	var allowance feegrant.FeeAllowanceI = &feegrant.BasicAllowance{
+		SpendLimit: spendLimit,
+		Expiration: expiration,
+	}
+	if len(allowedMessages) > 0 {
+		allowance, err = feegrant.NewAllowedMsgAllowance(allowance, allowedMessages)
+		if err != nil {
+			return nil, "", errors.Errorf("feegrant.NewAllowedMsgAllowance: %v", err)
+		}
+	}
+	msg, err := feegrant.NewMsgGrantAllowance(allowance, granterAddress, grantee)
+	if err != nil {
+		return nil, "", errors.Errorf("feegrant.NewMsgGrantAllowance: %v", err)
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid fee allowance: %v", err)
+	}
+
+	txResult, err := BroadcastMsgsWithSigner(ctx, granterSigner, "", msg)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "BroadcastMsgsWithSigner")
+	}
+	return txResult, granterAddress.String(), nil
+}
+
+// Revoke the fee allowance granted by the granter wallet to the grantee
+func RevokeFeeAllowance(ctx context.Context, granterSecretID string, granteeAddress string) (*cosmossdk.TxResponse, string, error) {
+	granterSigner, granterAddress, err := getFeeGranterWallet(ctx, granterSecretID)
+	if err != nil {
+		return nil, "", err
+	}
+	grantee, err := cosmossdk.AccAddressFromBech32(granteeAddress)
+	if err != nil {
+		return nil, "", NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid grantee address: %v", err)
+	}
+
+	msg := feegrant.NewMsgRevokeAllowance(granterAddress, grantee)
+	txResult, err := BroadcastMsgsWithSigner(ctx, granterSigner, "", &msg)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "BroadcastMsgsWithSigner")
+	}
+	return txResult, granterAddress.String(), nil
+}
+
+// Return the signer and the address of the granter, the gas-funding wallet of the config by default
+func getFeeGranterWallet(ctx context.Context, granterSecretID string) (Signer, cosmossdk.AccAddress, error) {
+	if granterSecretID == "" {
+		granterSecretID = config.GetConfigDefault().Blockchain.Coreum.FeeGrant.GasFunderSecretID
+	}
+	if granterSecretID == "" {
+		return nil, nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "no granter secret ID and no gas-funding wallet configured")
+	}
+	granterSigner, err := GetSigner(ctx, granterSecretID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return granterSigner, granterSigner.GetAddress(), nil
+}
+
+// Return the fee allowances of the grantee, only the one of the granter if granterAddress is set
+func GetFeeAllowances(ctx context.Context, granteeAddress string, granterAddress string) ([]*coreumservicemsg.FeeAllowance, error) {
+	if _, err := cosmossdk.AccAddressFromBech32(granteeAddress); err != nil {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid grantee address: %v", err)
+	}
+
+	queryClient := feegrant.NewQueryClient(GetClientContext())
+	allowances := []*coreumservicemsg.FeeAllowance{}
+	var nextKey []byte
+	for {
+		res, err := queryClient.Allowances(ctx, &feegrant.QueryAllowancesRequest{
+			Grantee:    granteeAddress,
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, errors.Errorf("queryClient.Allowances: %v", err)
+		}
+		for _, grant := range res.Allowances {
+			if granterAddress != "" && grant.Granter != granterAddress {
+				continue
+			}
+			allowance, err := toFeeAllowance(grant)
+			if err != nil {
+				return nil, errors.Errorf("toFeeAllowance: %v", err)
+			}
+			allowances = append(allowances, allowance)
+		}
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return allowances, nil
+		}
+		nextKey = res.Pagination.NextKey
+	}
+}
+
+func toFeeAllowance(grant *feegrant.Grant) (*coreumservicemsg.FeeAllowance, error) {
+	feeAllowance := &coreumservicemsg.FeeAllowance{
+		Granter:    grant.Granter,
+		Grantee:    grant.Grantee,
+		SpendLimit: []*coreumservicemsg.Coin{},
+	}
+	if grant.Allowance == nil {
+		return feeAllowance, nil
+	}
+	feeAllowance.Type = grant.Allowance.TypeUrl
+
+	// The gRPC client doesn't unpack the allowance
+	var allowance feegrant.FeeAllowanceI
+	if err := GetAppEncodingConfig().InterfaceRegistry.UnpackAny(grant.Allowance, &allowance); err != nil {
+		return nil, errors.Errorf("UnpackAny(%v): %v", grant.Allowance.TypeUrl, err)
+	}
+	if allowedMsgAllowance, ok := allowance.(*feegrant.AllowedMsgAllowance); ok {
+		feeAllowance.AllowedMessages = allowedMsgAllowance.AllowedMessages
+		innerAllowance, err := allowedMsgAllowance.GetAllowance()
+		if err != nil {
+			return nil, errors.Errorf("allowedMsgAllowance.GetAllowance: %v", err)
+		}
+		allowance = innerAllowance
+	}
+
+	var basicAllowance feegrant.BasicAllowance
+	switch typedAllowance := allowance.(type) {
+	case *feegrant.BasicAllowance:
+		basicAllowance = *typedAllowance
+	case *feegrant.PeriodicAllowance:
+		basicAllowance = typedAllowance.Basic
+	}
+	feeAllowance.SpendLimit = toMsgCoins(basicAllowance.SpendLimit)
+	if basicAllowance.Expiration != nil {
+		feeAllowance.Expiration = basicAllowance.Expiration.Unix()
+	}
+	return feeAllowance, nil
+}
@@ -0,0 +1,67 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"testing"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFeeGranterAddress(t *testing.T) {
+	senderAddress := mustAddressFromMnemonic(t, offlineTestMnemonic)
+	granterAddress := mustAddressFromMnemonic(t, offlineTestOtherMnemonic)
+
+	granter, err := getFeeGranterAddress(map[string]string{senderAddress: granterAddress}, senderAddress)
+	require.NoError(t, err)
+	require.Equal(t, granterAddress, granter.String())
+
+	// The sender without a granter pays its own fee
+	granter, err = getFeeGranterAddress(map[string]string{}, senderAddress)
+	require.NoError(t, err)
+	require.Nil(t, granter)
+
+	_, err = getFeeGranterAddress(map[string]string{senderAddress: "invalid"}, senderAddress)
+	require.Error(t, err)
+}
+
+func TestToFeeAllowance(t *testing.T) {
+	expiration := time.Unix(1700000000, 0).UTC()
+	basicAllowance := &feegrant.BasicAllowance{
+		SpendLimit: cosmossdk.NewCoins(cosmossdk.NewInt64Coin("ucore", 1000000)),
+		Expiration: &expiration,
+	}
+	allowedMsgAllowance, err := feegrant.NewAllowedMsgAllowance(basicAllowance, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	require.NoError(t, err)
+	allowanceAny, err := codectypes.NewAnyWithValue(allowedMsgAllowance)
+	require.NoError(t, err)
+
+	// As received from the gRPC client, without the cached value
+	feeAllowance, err := toFeeAllowance(&feegrant.Grant{
+		Granter:   "granter",
+		Grantee:   "grantee",
+		Allowance: &codectypes.Any{TypeUrl: allowanceAny.TypeUrl, Value: allowanceAny.Value},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &coreumservicemsg.FeeAllowance{
+		Granter:         "granter",
+		Grantee:         "grantee",
+		Type:            "/cosmos.feegrant.v1beta1.AllowedMsgAllowance",
+		SpendLimit:      []*coreumservicemsg.Coin{{Amount: "1000000", Denom: "ucore"}},
+		Expiration:      expiration.Unix(),
+		AllowedMessages: []string{"/cosmos.bank.v1beta1.MsgSend"},
+	}, feeAllowance)
+}
+
+func TestGetFeeGranterWalletWithoutGasFunder(t *testing.T) {
+	// No gas-funding wallet in the test config
+	_, _, err := getFeeGranterWallet(context.Background(), "")
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+}
@@ -0,0 +1,120 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+	"coreumservicemsg"
+	"math"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	feemodeltypes "github.com/CoreumFoundation/coreum/x/feemodel/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// The precision of the gas price adjustment in the config
+const gasPriceAdjustmentPrecision = 6
+
+// Return the gas config of the client context from the stage config, the default config fills the unset values
+func getClientGasConfig() client.GasConfig {
+	gasConfig := client.DefaultContextConfig().GasConfig
+	stageGasConfig := config.GetConfigDefault().Blockchain.Coreum.Gas
+	if stageGasConfig.GasAdjustment > 0 {
+		gasConfig.GasAdjustment = stageGasConfig.GasAdjustment
+	}
+	if stageGasConfig.GasPriceAdjustment > 0 {
+		gasConfig.GasPriceAdjustment = cosmossdk.NewDecWithPrec(
+			int64(math.Round(stageGasConfig.GasPriceAdjustment*math.Pow10(gasPriceAdjustmentPrecision))),
+			gasPriceAdjustmentPrecision,
+		)
+	}
+	return gasConfig
+}
+
+// Return the gas price of the transactions with the price strategy of the stage config.
+// The price is multiplied by the gas price adjustment, except for the fixed price.
+func GetGasPriceByStrategy(ctx context.Context, clientCtx client.Context) (cosmossdk.DecCoin, error) {
+	gasConfig := config.GetConfigDefault().Blockchain.Coreum.Gas
+
+	var gasPrice cosmossdk.DecCoin
+	switch gasConfig.PriceStrategy {
+	case coreumconfig.GasPriceStrategyFixed:
+		fixedGasPrice, err := cosmossdk.ParseDecCoin(gasConfig.FixedGasPrice)
+		if err != nil {
+			return cosmossdk.DecCoin{}, errors.Errorf("invalid fixed gas price %q: %v", gasConfig.FixedGasPrice, err)
+		}
+		return fixedGasPrice, nil
+	case "", coreumconfig.GasPriceStrategyMinGasPrice:
+		minGasPrice, err := client.GetGasPrice(ctx, clientCtx)
+		if err != nil {
+			return cosmossdk.DecCoin{}, errors.Errorf("client.GetGasPrice: %v", err)
+		}
+		gasPrice = minGasPrice
+	case coreumconfig.GasPriceStrategyFeeModelRecommended:
+		minGasPrice, err := client.GetGasPrice(ctx, clientCtx)
+		if err != nil {
+			return cosmossdk.DecCoin{}, errors.Errorf("client.GetGasPrice: %v", err)
+		}
+		params, err := feemodeltypes.NewQueryClient(clientCtx).Params(ctx, &feemodeltypes.QueryParamsRequest{})
+		if err != nil {
+			return cosmossdk.DecCoin{}, errors.Errorf("feemodel Params: %v", err)
+		}
+		gasPrice = recommendedGasPrice(minGasPrice, params.Params.Model)
+	default:
+		return cosmossdk.DecCoin{}, errors.Errorf("unknown gas price strategy %q", gasConfig.PriceStrategy)
+	}
+
+	gasPrice.Amount = gasPrice.Amount.Mul(clientCtx.GasPriceAdjustment())
+	return gasPrice, nil
+}
+
+// The fee model discounts the initial gas price when the blocks are not full.
+// Paying the price without the discount keeps the transaction valid if the discount shrinks before it's included,
+// and the current minimum gas price is paid if it's already escalated above the initial price.
+func recommendedGasPrice(minGasPrice cosmossdk.DecCoin, model feemodeltypes.ModelParams) cosmossdk.DecCoin {
+	if model.InitialGasPrice.IsNil() || model.InitialGasPrice.LTE(minGasPrice.Amount) {
+		return minGasPrice
+	}
+	return cosmossdk.NewDecCoinFromDec(minGasPrice.Denom, model.InitialGasPrice)
+}
+
+// Refuse the fee of the gas price and gas limit above the maximum fee per transaction of the stage config
+func CheckFeeCeiling(gasPrice cosmossdk.DecCoin, gasLimit uint64) error {
+	return checkFeeCeiling(gasPrice, gasLimit, config.GetConfigDefault().Blockchain.Coreum.Gas.MaxFeePerTx)
+}
+
+// Refuse the fee above maxFeePerTx, 0 for no limit
+func checkFeeCeiling(gasPrice cosmossdk.DecCoin, gasLimit uint64, maxFeePerTx uint64) error {
+	if maxFeePerTx == 0 {
+		return nil
+	}
+	fee := cosmossdk.NewCoin(gasPrice.Denom, gasPrice.Amount.MulInt(cosmossdk.NewIntFromUint64(gasLimit)).Ceil().TruncateInt())
+	return checkFeeAmountCeiling(cosmossdk.Coins{fee}, maxFeePerTx)
+}
+
+// Same as checkFeeCeiling, the fee is given, e.g. the fee of the transaction built outside the service
+func checkFeeAmountCeiling(fee cosmossdk.Coins, maxFeePerTx uint64) error {
+	if maxFeePerTx == 0 {
+		return nil
+	}
+	for _, feeCoin := range fee {
+		maxFee := cosmossdk.NewCoin(feeCoin.Denom, cosmossdk.NewIntFromUint64(maxFeePerTx))
+		if feeCoin.Amount.GT(maxFee.Amount) {
+			return NewServiceError(coreumservicemsg.ErrorCodeFeeCeilingExceeded,
+				map[string]string{"fee": feeCoin.String(), "max_fee": maxFee.String()},
+				"the fee %v exceeds the fee ceiling %v", feeCoin, maxFee)
+		}
+	}
+	return nil
+}
+
+// Refuse the transaction of the factory if its fee is above the maximum fee per transaction
+func checkFactoryFeeCeiling(txf client.Factory) error {
+	for _, gasPrice := range txf.GasPrices() {
+		if err := CheckFeeCeiling(gasPrice, txf.Gas()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"testing"
+
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+
+	feemodeltypes "github.com/CoreumFoundation/coreum/x/feemodel/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClientGasConfig(t *testing.T) {
+	gasConfig := getClientGasConfig()
+	require.Equal(t, coreumconfig.GasAdjustment, gasConfig.GasAdjustment)
+	require.Equal(t, cosmossdk.MustNewDecFromStr("1.1"), gasConfig.GasPriceAdjustment)
+
+	clientCtx := GetClientContext()
+	require.Equal(t, gasConfig.GasAdjustment, clientCtx.GasAdjustment())
+	require.Equal(t, gasConfig.GasPriceAdjustment, clientCtx.GasPriceAdjustment())
+}
+
+func TestRecommendedGasPrice(t *testing.T) {
+	model := feemodeltypes.DefaultParams().Model
+
+	// Discounted: the price without the discount is recommended
+	discounted := cosmossdk.NewDecCoinFromDec("ucore", model.InitialGasPrice.QuoInt64(2))
+	require.Equal(t, cosmossdk.NewDecCoinFromDec("ucore", model.InitialGasPrice), recommendedGasPrice(discounted, model))
+
+	// Escalated: the current minimum gas price is recommended
+	escalated := cosmossdk.NewDecCoinFromDec("ucore", model.InitialGasPrice.MulInt64(2))
+	require.Equal(t, escalated, recommendedGasPrice(escalated, model))
+}
+
+func TestCheckFeeCeiling(t *testing.T) {
+	gasPrice := cosmossdk.NewDecCoinFromDec("ucore", cosmossdk.MustNewDecFromStr("0.0625"))
+
+	// 0.0625 * 80000 = 5000
+	require.NoError(t, checkFeeCeiling(gasPrice, 80000, 5000))
+	require.NoError(t, checkFeeCeiling(gasPrice, 80000, 0))
+
+	err := checkFeeCeiling(gasPrice, 80001, 5000)
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeFeeCeilingExceeded, errorReply.ErrorCode)
+	require.True(t, errorReply.Retryable)
+	require.Equal(t, "5001ucore", errorReply.Details["fee"])
+	require.Equal(t, "5000ucore", errorReply.Details["max_fee"])
+}
+
+func TestCheckFeeAmountCeiling(t *testing.T) {
+	require.NoError(t, checkFeeAmountCeiling(cosmossdk.NewCoins(cosmossdk.NewInt64Coin("ucore", 5000)), 5000))
+	require.NoError(t, checkFeeAmountCeiling(cosmossdk.NewCoins(cosmossdk.NewInt64Coin("ucore", 5001)), 0))
+	require.NoError(t, checkFeeAmountCeiling(cosmossdk.NewCoins(), 5000))
+
+	err := checkFeeAmountCeiling(cosmossdk.NewCoins(cosmossdk.NewInt64Coin("ucore", 5001)), 5000)
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeFeeCeilingExceeded, errorReply.ErrorCode)
+	require.Equal(t, "5001ucore", errorReply.Details["fee"])
+	require.Equal(t, "5000ucore", errorReply.Details["max_fee"])
+}
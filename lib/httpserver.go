@@ -4,29 +4,42 @@ import (
 	"context"
 	"coreumservice/go/stably_io/config"
 	"coreumservice/go/stably_io/utils"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"coreumservicemsg"
 
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const prefix = "coreumservice"
 
+// Maximum time to wait for the in-flight requests when shutting down
+const shutdownTimeout = 30 * time.Second
+
 func RunHttpServer() {
 	r := mux.NewRouter()
 
 	setupHealthCheckHandler(r)
+	setupMetricsHandler(r)
 
 	// Register endpoints
 	validateIssuanceParams(r)
 	getTreasuryAddress(r)
 	getLatestBlockStatus(r)
+	getNodeStatus(r)
 	getBlockTransactions(r)
 	getBlockTransactionsInRange(r)
 
@@ -47,15 +60,88 @@ func RunHttpServer() {
 	// Method to get calculate the hash by the parameters
 	calculateHashOfTransfer(r)
 
+	// Method to propose the gas, sequence and hash of the transfer in one call
+	proposeTransferParams(r)
+
+	// Method to pay many recipients with as few transactions as possible
+	batchTransferStablyToken(r)
+
+	// Methods to build, sign and broadcast the transaction in the separate steps
+	buildUnsignedTransfer(r)
+	signTransaction(r)
+	broadcastSignedTransaction(r)
+
+	// Method to broadcast the transaction signed outside the service
+	broadcastRawTransaction(r)
+
+	// Method to decode the transaction bytes for the investigation
+	decodeTransaction(r)
+
+	// Method to simulate any set of messages before running the treasury operations
+	simulateTransaction(r)
+
+	// Methods to let the gas-funding wallet pay the fee of the other wallets
+	grantFeeAllowance(r)
+	revokeFeeAllowance(r)
+	getFeeAllowances(r)
+
+	// Methods to let the hot operator wallet send the coins of the treasury with the authz send authorization
+	grantAuthorization(r)
+	revokeAuthorization(r)
+	getAuthorizations(r)
+
+	// Methods to sign the transactions of the multisig accounts by collecting the partial signatures
+	registerMultisigAccount(r)
+	createMultisigSession(r)
+	submitMultisigSignature(r)
+	cancelMultisigSession(r)
+	getMultisigSessions(r)
+
+	// Method to pick up the rotated secrets without restarting the service
+	refreshSecrets(r)
+
 	// Return the transaction detail by the transaction hash
 	getTransactionByHashRequest(r)
 
+	// Track the submitted transaction until it has the required number of confirmations
+	getTransactionConfirmationStatus(r)
+
+	// Return the sequences reserved and in flight of the sender, and the gaps blocking its transactions
+	getSenderSequenceStatus(r)
+
 	// Method to query the balance of the address for the denom
 	getBalanceOfAddressForDenom(r)
 
 	port := config.GetConfigDefault().Blockchain.Coreum.PRCConfig.HTTPServerPort
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: r,
+	}
+
+	// Shut down gracefully on SIGINT/SIGTERM, then release the blockchain connections
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+
+		fmt.Println("Shutting down http server")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			fmt.Printf("server.Shutdown: %v\n", err)
+		}
+		if err := GetNodePool().Close(); err != nil {
+			fmt.Printf("GetNodePool().Close: %v\n", err)
+		}
+	}()
+
 	fmt.Printf("Start http server at port %d\n", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), r))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	<-shutdownDone
 }
 
 func setupHealthCheckHandler(r *mux.Router) *mux.Route {
@@ -64,23 +150,31 @@ func setupHealthCheckHandler(r *mux.Router) *mux.Route {
 	})
 }
 
+// Expose the Prometheus metrics, e.g. the requests served by each blockchain node
+func setupMetricsHandler(r *mux.Router) *mux.Route {
+	return r.Handle(fmt.Sprintf("/%s/metrics", prefix), promhttp.Handler())
+}
+
 // Method to validate the issuance params
 func validateIssuanceParams(r *mux.Router) *mux.Route {
 	return httpEndpointProcessing(r,
 		"validate-transfer-params",
-		func(input *coreumservicemsg.ValidateTransferParamsRequest) (*coreumservicemsg.ValidateTransferParamsReply, error) {
-			return &coreumservicemsg.ValidateTransferParamsReply{}, nil
+		func(ctx context.Context, input *coreumservicemsg.ValidateTransferParamsRequest) (*coreumservicemsg.ValidateTransferParamsReply, error) {
+			reply, err := ValidateTransferParams(ctx, input)
+			if err != nil {
+				return nil, errors.Wrap(err, "ValidateTransferParams")
+			}
+			return reply, nil
 		})
 }
 
 func getAccountInfoByAddress(r *mux.Router) *mux.Route {
 	return httpEndpointProcessing(r,
 		"get-account-info",
-		func(input *coreumservicemsg.GetAccountInfoRequest) (*coreumservicemsg.GetAccountInfoReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.GetAccountInfoRequest) (*coreumservicemsg.GetAccountInfoReply, error) {
 			acc, err := GetAccountInfo(ctx, input.Address)
 			if err != nil {
-				return nil, errors.Errorf("GetAccountInfo: %v", err)
+				return nil, errors.Wrap(err, "GetAccountInfo")
 			}
 			return &coreumservicemsg.GetAccountInfoReply{
 				AccountNumber: acc.AccountNumber,
@@ -92,21 +186,38 @@ func getAccountInfoByAddress(r *mux.Router) *mux.Route {
 func getGasForTransferStablyToken(r *mux.Router) *mux.Route {
 	return httpEndpointProcessing(r,
 		"get-gas-for-transfer-stably-token",
-		func(input *coreumservicemsg.GetGasForTransferStablyTokenRequest) (*coreumservicemsg.GetGasForTransferStablyTokenReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.GetGasForTransferStablyTokenRequest) (*coreumservicemsg.GetGasForTransferStablyTokenReply, error) {
 
-			treasuryMnemonic := GetTreasuryMnemonicFromSecretID(ctx, input.SenderSecretID)
+			amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "ResolveRequestAmount")
+			}
+
+			signer, err := getTreasuryTransferSigner(ctx, input.SenderSecretID)
+			if err != nil {
+				return nil, errors.Wrap(err, "getTreasuryTransferSigner")
+			}
+
+			sequenceNumber := input.SequenceNumber
+			if input.AutoSequence {
+				// The simulation expects the committed sequence
+				accountInfo, err := GetAccountInfo(ctx, signer.signerAddress.String())
+				if err != nil {
+					return nil, errors.Wrap(err, "GetAccountInfo")
+				}
+				sequenceNumber = accountInfo.Sequence
+			}
 
-			gasUsed, gasPrice, err := CalculateGasForTransfer(ctx,
-				treasuryMnemonic,
+			gasUsed, gasPrice, err := calculateGasForTransferWithSigner(ctx,
+				signer,
 				input.RecipientAddress,
 				input.TokenDenom,
-				input.TokenAmount,
+				amount,
 				input.Memo,
-				input.SequenceNumber,
+				sequenceNumber,
 			)
 			if err != nil {
-				return nil, errors.Errorf("CalculateGasForTransfer: %v", err)
+				return nil, errors.Wrap(err, "calculateGasForTransferWithSigner")
 			}
 
 			return &coreumservicemsg.GetGasForTransferStablyTokenReply{
@@ -121,21 +232,43 @@ func transferStablyToken(r *mux.Router) *mux.Route {
 		// The endpoint
 		"transfer-stably-token",
 		// The processing function
-		func(input *coreumservicemsg.TransferStablyTokenRequest) (*coreumservicemsg.TransferStablyTokenReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.TransferStablyTokenRequest) (*coreumservicemsg.TransferStablyTokenReply, error) {
+
+			amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "ResolveRequestAmount")
+			}
+
+			if input.AutoSequence {
+				txResponse, err := TransferStablyTokenWithAutoSequence(ctx,
+					input.SenderSecretID,
+					input.RecipientAddress,
+					input.TokenDenom,
+					amount,
+					input.Memo,
+					input.GasPrice,
+					input.GasUsed,
+				)
+				if err != nil {
+					return nil, errors.Wrap(err, "TransferStablyTokenWithAutoSequence")
+				}
+				return &coreumservicemsg.TransferStablyTokenReply{
+					TxHash: txResponse.TxHash,
+				}, nil
+			}
 
 			txResponse, err := TransferStablyToken(ctx,
 				input.SenderSecretID,
 				input.RecipientAddress,
 				input.TokenDenom,
-				input.TokenAmount,
+				amount,
 				input.Memo,
 				input.SequenceNumber,
 				input.GasPrice,
 				input.GasUsed,
 			)
 			if err != nil {
-				return nil, errors.Errorf("TransferStablyToken: %v", err)
+				return nil, errors.Wrap(err, "TransferStablyToken")
 			}
 			return &coreumservicemsg.TransferStablyTokenReply{
 				TxHash: txResponse.TxHash,
@@ -149,8 +282,12 @@ func transferTokenWithMnemonic(r *mux.Router) *mux.Route {
 		// The endpoint
 		"test-only/transfer-stably-token",
 		// The processing function
-		func(input *coreumservicemsg.TransferTokenWithMnemonicRequest) (*coreumservicemsg.TransferTokenWithMnemonicReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.TransferTokenWithMnemonicRequest) (*coreumservicemsg.TransferTokenWithMnemonicReply, error) {
+
+			amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "ResolveRequestAmount")
+			}
 
 			gasPrice := input.GasPrice
 			gasUsed := input.GasUsed
@@ -160,12 +297,12 @@ func transferTokenWithMnemonic(r *mux.Router) *mux.Route {
 					input.SenderMnemonic,
 					input.RecipientAddress,
 					input.TokenDenom,
-					input.TokenAmount,
+					amount,
 					input.Memo,
 					input.SequenceNumber,
 				)
 				if err != nil {
-					return nil, errors.Errorf("CalculateGasForTransfer: %v", err)
+					return nil, errors.Wrap(err, "CalculateGasForTransfer")
 				}
 				gasPrice = newGasPrice
 				gasUsed = newGasUsed
@@ -175,14 +312,14 @@ func transferTokenWithMnemonic(r *mux.Router) *mux.Route {
 				input.SenderMnemonic,
 				input.RecipientAddress,
 				input.TokenDenom,
-				input.TokenAmount,
+				amount,
 				input.Memo,
 				input.SequenceNumber,
 				gasPrice,
 				gasUsed,
 			)
 			if err != nil {
-				return nil, errors.Errorf("TransferTokenWithMnemonic: %v", err)
+				return nil, errors.Wrap(err, "TransferTokenWithMnemonic")
 			}
 			return &coreumservicemsg.TransferTokenWithMnemonicReply{
 				TxHash: txResponse.TxHash,
@@ -196,21 +333,25 @@ func calculateHashOfTransfer(r *mux.Router) *mux.Route {
 		// The endpoint
 		"calculate-hash-of-transfer",
 		// The processing function
-		func(input *coreumservicemsg.CalculateHashOfTransactionRequest) (*coreumservicemsg.CalculateHashOfTransactionReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.CalculateHashOfTransactionRequest) (*coreumservicemsg.CalculateHashOfTransactionReply, error) {
+
+			amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "ResolveRequestAmount")
+			}
 
 			calculatedHash, err := CalculateHashForTransfer(ctx,
 				input.SenderSecretID,
 				input.RecipientAddress,
 				input.TokenDenom,
-				input.TokenAmount,
+				amount,
 				input.Memo,
 				input.SequenceNumber,
 				input.GasPrice,
 				input.GasUsed,
 			)
 			if err != nil {
-				return nil, errors.Errorf("CalculateHashForTransfer: %v", err)
+				return nil, errors.Wrap(err, "CalculateHashForTransfer")
 			}
 			return &coreumservicemsg.CalculateHashOfTransactionReply{
 				CalculatedTxHash: calculatedHash,
@@ -219,16 +360,570 @@ func calculateHashOfTransfer(r *mux.Router) *mux.Route {
 	)
 }
 
+func proposeTransferParams(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"propose-transfer-params",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.ProposeTransferParamsRequest) (*coreumservicemsg.ProposeTransferParamsReply, error) {
+			amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "ResolveRequestAmount")
+			}
+
+			transferParams, err := ProposeTransferStablyTokenParams(ctx,
+				input.SenderSecretID,
+				input.RecipientAddress,
+				input.TokenDenom,
+				amount,
+				input.Memo,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "ProposeTransferStablyTokenParams")
+			}
+			displayAmount, displayUnit := GetDisplayAmount(input.TokenDenom, amount)
+			return &coreumservicemsg.ProposeTransferParamsReply{
+				SenderSecretID:   input.SenderSecretID,
+				SenderAddress:    transferParams.SenderAddress,
+				TokenDenom:       input.TokenDenom,
+				TokenAmount:      tokenAmountInt64(amount),
+				Amount:           amount.String(),
+				DisplayAmount:    displayAmount,
+				DisplayUnit:      displayUnit,
+				Memo:             input.Memo,
+				RecipientAddress: input.RecipientAddress,
+				SequenceNumber:   transferParams.SequenceNumber,
+				GasPrice:         transferParams.GasPrice,
+				GasUsed:          transferParams.GasUsed,
+				CalculatedTxHash: transferParams.TxHash,
+				SignerAddress:    transferParams.SignerAddress,
+			}, nil
+		},
+	)
+}
+
+func batchTransferStablyToken(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"batch-transfer-stably-token",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.BatchTransferStablyTokenRequest) (*coreumservicemsg.BatchTransferStablyTokenReply, error) {
+			legs := []*BatchTransferLeg{}
+			for i, recipient := range input.Recipients {
+				_, err := cosmossdk.AccAddressFromBech32(recipient.RecipientAddress)
+				if err != nil {
+					return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, map[string]string{"index": fmt.Sprint(i)},
+						"invalid recipient address %q: %v", recipient.RecipientAddress, err)
+				}
+				amount, err := ResolveRequestAmount(input.TokenDenom, recipient.DisplayAmount, recipient.Amount, recipient.TokenAmount)
+				if err != nil {
+					return nil, errors.Wrapf(err, "ResolveRequestAmount of the recipient %d", i)
+				}
+				legs = append(legs, &BatchTransferLeg{
+					Index:            i,
+					RecipientAddress: recipient.RecipientAddress,
+					Amount:           amount,
+				})
+			}
+
+			batches, err := BatchTransferStablyToken(ctx,
+				input.SenderSecretID,
+				input.TokenDenom,
+				legs,
+				input.Memo,
+				input.MessageMode,
+				input.DryRun,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "BatchTransferStablyToken")
+			}
+			return &coreumservicemsg.BatchTransferStablyTokenReply{
+				Batches: batches,
+				Legs:    GetBatchTransferLegResults(batches),
+			}, nil
+		},
+	)
+}
+
+func buildUnsignedTransfer(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"build-unsigned-transfer",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.BuildUnsignedTransferRequest) (*coreumservicemsg.BuildUnsignedTransferReply, error) {
+			amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+			if err != nil {
+				return nil, errors.Wrap(err, "ResolveRequestAmount")
+			}
+
+			senderAddress := input.SenderAddress
+			if input.SenderSecretID != "" {
+				treasuryAddress, err := GetTreasuryAddress(ctx, input.SenderSecretID)
+				if err != nil {
+					return nil, errors.Wrap(err, "GetTreasuryAddress")
+				}
+				senderAddress = treasuryAddress.Address
+			}
+			if senderAddress == "" {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "sender_secret_id or sender_address is required")
+			}
+			if err := checkTxEncoding(input.Encoding); err != nil {
+				return nil, err
+			}
+
+			sequenceNumber := input.SequenceNumber
+			if input.AutoSequence {
+				sequenceNumber, err = GetSequenceManager().Reserve(ctx, senderAddress)
+				if err != nil {
+					return nil, errors.Wrap(err, "GetSequenceManager().Reserve")
+				}
+			}
+
+			unsignedTransfer, err := BuildUnsignedTransfer(ctx,
+				senderAddress,
+				input.RecipientAddress,
+				input.TokenDenom,
+				amount,
+				input.Memo,
+				sequenceNumber,
+				input.GasPrice,
+				input.GasUsed,
+			)
+			if err != nil {
+				if input.AutoSequence {
+					GetSequenceManager().Release(senderAddress, sequenceNumber)
+				}
+				return nil, errors.Wrap(err, "BuildUnsignedTransfer")
+			}
+
+			unsignedTx, err := EncodeTx(unsignedTransfer.Tx, input.Encoding)
+			if err != nil {
+				if input.AutoSequence {
+					GetSequenceManager().Release(senderAddress, sequenceNumber)
+				}
+				return nil, errors.Wrap(err, "EncodeTx")
+			}
+			encoding := input.Encoding
+			if encoding == "" {
+				encoding = coreumservicemsg.TxEncodingProto
+			}
+			return &coreumservicemsg.BuildUnsignedTransferReply{
+				UnsignedTx:     unsignedTx,
+				Encoding:       encoding,
+				SenderAddress:  unsignedTransfer.SenderAddress,
+				AccountNumber:  unsignedTransfer.AccountNumber,
+				SequenceNumber: unsignedTransfer.SequenceNumber,
+				ChainID:        GetChainIDByStage(),
+				GasPrice:       unsignedTransfer.GasPrice,
+				GasUsed:        unsignedTransfer.GasUsed,
+			}, nil
+		},
+	)
+}
+
+func signTransaction(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"sign-transaction",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.SignTransactionRequest) (*coreumservicemsg.SignTransactionReply, error) {
+			// Signing with the committed sequence would race with the transactions of the service
+			if input.SequenceNumber == nil {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "sequence_number is required")
+			}
+			signer, err := GetOfflineSigner(ctx, input.SignerSecretID)
+			if err != nil {
+				return nil, errors.Wrap(err, "GetOfflineSigner")
+			}
+
+			unsignedTx, err := DecodeEncodedTx(input.UnsignedTx, input.Encoding)
+			if err != nil {
+				return nil, errors.Wrap(err, "DecodeEncodedTx")
+			}
+
+			signedTxBytes, err := SignTxWithSigner(ctx, signer, unsignedTx, input.AccountNumber, *input.SequenceNumber)
+			if err != nil {
+				return nil, errors.Wrap(err, "SignTxWithSigner")
+			}
+
+			_, txHash := CalculateHashOfTransaction(signedTxBytes)
+			return &coreumservicemsg.SignTransactionReply{
+				SignedTxBytes: base64.StdEncoding.EncodeToString(signedTxBytes),
+				TxHash:        txHash,
+			}, nil
+		},
+	)
+}
+
+func broadcastSignedTransaction(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"broadcast-signed-transaction",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.BroadcastSignedTransactionRequest) (*coreumservicemsg.BroadcastSignedTransactionReply, error) {
+			if input.ExpectedTxHash == "" {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "expected_tx_hash is required")
+			}
+			signedTxBytes, err := base64.StdEncoding.DecodeString(input.SignedTxBytes)
+			if err != nil {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid base64 transaction: %v", err)
+			}
+
+			broadcastResult, err := BroadcastSignedTx(ctx, signedTxBytes, input.ExpectedTxHash)
+			if err != nil {
+				return nil, errors.Wrap(err, "BroadcastSignedTx")
+			}
+			return broadcastResult, nil
+		},
+	)
+}
+
+func broadcastRawTransaction(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"broadcast-raw-transaction",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.BroadcastRawTransactionRequest) (*coreumservicemsg.BroadcastRawTransactionReply, error) {
+			txBytes, err := DecodeRawTxBytes(input.TxBytes, input.Encoding)
+			if err != nil {
+				return nil, errors.Wrap(err, "DecodeRawTxBytes")
+			}
+
+			broadcastResult, err := BroadcastRawTransaction(ctx, txBytes)
+			if err != nil {
+				return nil, errors.Wrap(err, "BroadcastRawTransaction")
+			}
+			return broadcastResult, nil
+		},
+	)
+}
+
+func decodeTransaction(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"decode-transaction",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.DecodeTransactionRequest) (*coreumservicemsg.DecodeTransactionReply, error) {
+			txBytes, err := DecodeRawTxBytes(input.TxBytes, input.Encoding)
+			if err != nil {
+				return nil, errors.Wrap(err, "DecodeRawTxBytes")
+			}
+
+			decodedTransaction, err := DecodeTransaction(txBytes)
+			if err != nil {
+				return nil, errors.Wrap(err, "DecodeTransaction")
+			}
+			return decodedTransaction, nil
+		},
+	)
+}
+
+func simulateTransaction(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"simulate-transaction",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.SimulateTransactionRequest) (*coreumservicemsg.SimulateTransactionReply, error) {
+			msgs, err := DecodeMessages(input.Messages)
+			if err != nil {
+				return nil, errors.Wrap(err, "DecodeMessages")
+			}
+
+			simulation, err := SimulateTransaction(ctx, input.SignerAddress, msgs, input.Memo)
+			if err != nil {
+				return nil, errors.Wrap(err, "SimulateTransaction")
+			}
+			return simulation, nil
+		},
+	)
+}
+
+func grantFeeAllowance(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"grant-fee-allowance",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GrantFeeAllowanceRequest) (*coreumservicemsg.GrantFeeAllowanceReply, error) {
+			var spendLimit cosmossdk.Coins
+			if input.SpendLimit != "" {
+				var err error
+				spendLimit, err = cosmossdk.ParseCoinsNormalized(input.SpendLimit)
+				if err != nil {
+					return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid spend_limit: %v", err)
+				}
+			}
+			var expiration *time.Time
+			if input.Expiration > 0 {
+				expirationTime := time.Unix(input.Expiration, 0).UTC()
+				expiration = &expirationTime
+			}
+
+			txResult, granterAddress, err := GrantFeeAllowance(ctx,
+				input.GranterSecretID,
+				input.GranteeAddress,
+				spendLimit,
+				expiration,
+				input.AllowedMessages,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "GrantFeeAllowance")
+			}
+			return &coreumservicemsg.GrantFeeAllowanceReply{
+				TxHash:         txResult.TxHash,
+				GranterAddress: granterAddress,
+			}, nil
+		},
+	)
+}
+
+func revokeFeeAllowance(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"revoke-fee-allowance",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.RevokeFeeAllowanceRequest) (*coreumservicemsg.RevokeFeeAllowanceReply, error) {
+			txResult, granterAddress, err := RevokeFeeAllowance(ctx, input.GranterSecretID, input.GranteeAddress)
+			if err != nil {
+				return nil, errors.Wrap(err, "RevokeFeeAllowance")
+			}
+			return &coreumservicemsg.RevokeFeeAllowanceReply{
+				TxHash:         txResult.TxHash,
+				GranterAddress: granterAddress,
+			}, nil
+		},
+	)
+}
+
+func getFeeAllowances(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"get-fee-allowances",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GetFeeAllowancesRequest) (*coreumservicemsg.GetFeeAllowancesReply, error) {
+			allowances, err := GetFeeAllowances(ctx, input.GranteeAddress, input.GranterAddress)
+			if err != nil {
+				return nil, errors.Wrap(err, "GetFeeAllowances")
+			}
+			return &coreumservicemsg.GetFeeAllowancesReply{
+				Allowances: allowances,
+			}, nil
+		},
+	)
+}
+
+func grantAuthorization(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"grant-authorization",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GrantAuthorizationRequest) (*coreumservicemsg.GrantAuthorizationReply, error) {
+			spendLimit, err := cosmossdk.ParseCoinsNormalized(input.SpendLimit)
+			if err != nil {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid spend_limit: %v", err)
+			}
+
+			txResult, granterAddress, err := GrantSendAuthorization(ctx,
+				input.GranterSecretID,
+				input.GranteeAddress,
+				spendLimit,
+				time.Unix(input.Expiration, 0).UTC(),
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "GrantSendAuthorization")
+			}
+			return &coreumservicemsg.GrantAuthorizationReply{
+				TxHash:         txResult.TxHash,
+				GranterAddress: granterAddress,
+			}, nil
+		},
+	)
+}
+
+func revokeAuthorization(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"revoke-authorization",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.RevokeAuthorizationRequest) (*coreumservicemsg.RevokeAuthorizationReply, error) {
+			txResult, granterAddress, err := RevokeSendAuthorization(ctx, input.GranterSecretID, input.GranteeAddress)
+			if err != nil {
+				return nil, errors.Wrap(err, "RevokeSendAuthorization")
+			}
+			return &coreumservicemsg.RevokeAuthorizationReply{
+				TxHash:         txResult.TxHash,
+				GranterAddress: granterAddress,
+			}, nil
+		},
+	)
+}
+
+func getAuthorizations(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"get-authorizations",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GetAuthorizationsRequest) (*coreumservicemsg.GetAuthorizationsReply, error) {
+			authorizations, err := GetAuthorizations(ctx, input.GranteeAddress, input.GranterAddress)
+			if err != nil {
+				return nil, errors.Wrap(err, "GetAuthorizations")
+			}
+			return &coreumservicemsg.GetAuthorizationsReply{
+				Authorizations: authorizations,
+			}, nil
+		},
+	)
+}
+
+func registerMultisigAccount(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"register-multisig-account",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.RegisterMultisigAccountRequest) (*coreumservicemsg.RegisterMultisigAccountReply, error) {
+			account, err := RegisterMultisigAccount(input.PubKeys, input.Threshold)
+			if err != nil {
+				return nil, errors.Wrap(err, "RegisterMultisigAccount")
+			}
+			return &coreumservicemsg.RegisterMultisigAccountReply{
+				Account: account,
+			}, nil
+		},
+	)
+}
+
+func createMultisigSession(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"create-multisig-session",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.CreateMultisigSessionRequest) (*coreumservicemsg.CreateMultisigSessionReply, error) {
+			var msgs []cosmossdk.Msg
+			if len(input.Messages) > 0 {
+				var err error
+				msgs, err = DecodeMessages(input.Messages)
+				if err != nil {
+					return nil, errors.Wrap(err, "DecodeMessages")
+				}
+			} else {
+				amount, err := ResolveRequestAmount(input.TokenDenom, input.DisplayAmount, input.Amount, input.TokenAmount)
+				if err != nil {
+					return nil, errors.Wrap(err, "ResolveRequestAmount")
+				}
+				msgs = []cosmossdk.Msg{&banktypes.MsgSend{
+					FromAddress: input.MultisigAddress,
+					ToAddress:   input.RecipientAddress,
+					Amount:      cosmossdk.NewCoins(cosmossdk.NewCoin(input.TokenDenom, amount)),
+				}}
+			}
+
+			session, err := CreateMultisigSession(ctx,
+				input.MultisigAddress,
+				msgs,
+				input.Memo,
+				input.SequenceNumber,
+				input.AutoSequence,
+				input.GasPrice,
+				input.GasUsed,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "CreateMultisigSession")
+			}
+			return &coreumservicemsg.CreateMultisigSessionReply{
+				Session: session,
+			}, nil
+		},
+	)
+}
+
+func submitMultisigSignature(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"submit-multisig-signature",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.SubmitMultisigSignatureRequest) (*coreumservicemsg.SubmitMultisigSignatureReply, error) {
+			if input.SignerSecretID != "" {
+				signer, err := GetSigner(ctx, input.SignerSecretID)
+				if err != nil {
+					return nil, errors.Wrap(err, "GetSigner")
+				}
+				reply, err := SignMultisigSessionWithSigner(ctx, input.SessionID, signer)
+				if err != nil {
+					return nil, errors.Wrap(err, "SignMultisigSessionWithSigner")
+				}
+				return reply, nil
+			}
+
+			pubKey, err := decodeSecp256k1PubKey(input.PubKey)
+			if err != nil {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid pub_key: %v", err)
+			}
+			signature, err := base64.StdEncoding.DecodeString(input.Signature)
+			if err != nil {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid base64 signature: %v", err)
+			}
+			reply, err := SubmitMultisigSignature(ctx, input.SessionID, pubKey, signature)
+			if err != nil {
+				return nil, errors.Wrap(err, "SubmitMultisigSignature")
+			}
+			return reply, nil
+		},
+	)
+}
+
+func cancelMultisigSession(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"cancel-multisig-session",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.CancelMultisigSessionRequest) (*coreumservicemsg.CancelMultisigSessionReply, error) {
+			session, err := CancelMultisigSession(input.SessionID)
+			if err != nil {
+				return nil, errors.Wrap(err, "CancelMultisigSession")
+			}
+			return &coreumservicemsg.CancelMultisigSessionReply{
+				Session: session,
+			}, nil
+		},
+	)
+}
+
+func getMultisigSessions(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"get-multisig-sessions",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GetMultisigSessionsRequest) (*coreumservicemsg.GetMultisigSessionsReply, error) {
+			return &coreumservicemsg.GetMultisigSessionsReply{
+				Sessions: GetMultisigSessions(input.MultisigAddress),
+			}, nil
+		},
+	)
+}
+
+func refreshSecrets(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"refresh-secrets",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.RefreshSecretsRequest) (*coreumservicemsg.RefreshSecretsReply, error) {
+			reply, err := RefreshSecrets(ctx, input.SecretNames)
+			if err != nil {
+				return nil, errors.Wrap(err, "RefreshSecrets")
+			}
+			return reply, nil
+		},
+	)
+}
+
 func getTreasuryAddress(r *mux.Router) *mux.Route {
 	return httpEndpointProcessing(r,
 		// The endpoint
 		"get-treasury-address",
 		// The processing function
-		func(input *coreumservicemsg.GetTreasuryAddressRequest) (*coreumservicemsg.GetTreasuryAddressReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.GetTreasuryAddressRequest) (*coreumservicemsg.GetTreasuryAddressReply, error) {
 			addressInfo, err := GetTreasuryAddress(ctx, input.TreasurySecretID)
 			if err != nil {
-				return nil, errors.Errorf("GetTreasuryAddress: %v", err)
+				return nil, errors.Wrap(err, "GetTreasuryAddress")
 			}
 			return &coreumservicemsg.GetTreasuryAddressReply{
 				Address: addressInfo.Address,
@@ -243,11 +938,10 @@ func getLatestBlockStatus(r *mux.Router) *mux.Route {
 		// The endpoint
 		"get-latest-block-status",
 		// The processing function
-		func(_ *struct{}) (*coreumservicemsg.GetLatestBlockStatusReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, _ *struct{}) (*coreumservicemsg.GetLatestBlockStatusReply, error) {
 			blockStatus, err := GetLatestBlockStatus(ctx)
 			if err != nil {
-				return nil, errors.Errorf("GetLatestBlockStatus: %v", err)
+				return nil, errors.Wrap(err, "GetLatestBlockStatus")
 			}
 			return &coreumservicemsg.GetLatestBlockStatusReply{
 				BlockStatus: blockStatus,
@@ -256,16 +950,28 @@ func getLatestBlockStatus(r *mux.Router) *mux.Route {
 	)
 }
 
+func getNodeStatus(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"get-node-status",
+		// The processing function
+		func(_ context.Context, _ *struct{}) (*coreumservicemsg.GetNodeStatusReply, error) {
+			return &coreumservicemsg.GetNodeStatusReply{
+				Nodes: GetNodePool().GetNodeStatuses(),
+			}, nil
+		},
+	)
+}
+
 func getBlockTransactions(r *mux.Router) *mux.Route {
 	return httpEndpointProcessing(r,
 		// The endpoint
 		"get-block-transactions",
 		// The processing function
-		func(input *coreumservicemsg.GetBlockTransactionsRequest) (*coreumservicemsg.GetBlockTransactionsReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.GetBlockTransactionsRequest) (*coreumservicemsg.GetBlockTransactionsReply, error) {
 			transactions, err := GetBlockTransactions(ctx, int64(input.BlockNumber))
 			if err != nil {
-				return nil, errors.Errorf("GetBlockTransactions: %v", err)
+				return nil, errors.Wrap(err, "GetBlockTransactions")
 			}
 			return &coreumservicemsg.GetBlockTransactionsReply{
 				Transactions: transactions,
@@ -279,14 +985,18 @@ func getBlockTransactionsInRange(r *mux.Router) *mux.Route {
 		// The endpoint
 		"get-block-transactions-in-range",
 		// The processing function
-		func(input *coreumservicemsg.GetBlockTransactionsInRangeRequest) (*coreumservicemsg.GetBlockTransactionsInRangeReply, error) {
-			ctx := context.Background()
-			transactions, err := GetBlockTransactionsInRange(ctx, int64(input.StartBlockNumber), int64(input.EndBlockNumber))
+		func(ctx context.Context, input *coreumservicemsg.GetBlockTransactionsInRangeRequest) (*coreumservicemsg.GetBlockTransactionsInRangeReply, error) {
+			rangeTransactions, err := GetBlockTransactionsInRangeWithErrors(ctx,
+				int64(input.StartBlockNumber),
+				int64(input.EndBlockNumber),
+				input.Concurrency,
+			)
 			if err != nil {
-				return nil, errors.Errorf("GetBlockTransactionsInRange: %v", err)
+				return nil, errors.Wrap(err, "GetBlockTransactionsInRangeWithErrors")
 			}
 			return &coreumservicemsg.GetBlockTransactionsInRangeReply{
-				Transactions: transactions,
+				Transactions: rangeTransactions.Transactions,
+				FailedBlocks: rangeTransactions.FailedBlocks,
 			}, nil
 		},
 	)
@@ -297,33 +1007,72 @@ func getTransactionByHashRequest(r *mux.Router) *mux.Route {
 		// The endpoint
 		"get-transaction-by-hash",
 		// The processing function
-		func(input *coreumservicemsg.GetTransactionRequest) (*coreumservicemsg.GetTransactionReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.GetTransactionRequest) (*coreumservicemsg.GetTransactionReply, error) {
 			transactionDetail, err := GetTransactionByHash(ctx, GetClientContext(), input.TransactionHash)
 			if err != nil {
-				return nil, errors.Errorf("GetTransactionByHash: %v", err)
+				return nil, errors.Wrap(err, "GetTransactionByHash")
+			}
+			if transactionDetail.Status == coreumservicemsg.TransactionStatusNotFound ||
+				transactionDetail.Status == coreumservicemsg.TransactionStatusUnknown {
+				return &coreumservicemsg.GetTransactionReply{
+					Status: transactionDetail.Status,
+				}, nil
 			}
 			return &coreumservicemsg.GetTransactionReply{
+				Status:            transactionDetail.Status,
 				TransactionDetail: transactionDetail,
 			}, nil
 		},
 	)
 }
 
+func getTransactionConfirmationStatus(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"get-transaction-confirmation-status",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GetTransactionConfirmationStatusRequest) (*coreumservicemsg.GetTransactionConfirmationStatusReply, error) {
+			confirmationStatus, err := GetTransactionConfirmationStatus(ctx, input.TransactionHash)
+			if err != nil {
+				return nil, errors.Wrap(err, "GetTransactionConfirmationStatus")
+			}
+			return confirmationStatus, nil
+		},
+	)
+}
+
+func getSenderSequenceStatus(r *mux.Router) *mux.Route {
+	return httpEndpointProcessing(r,
+		// The endpoint
+		"get-sender-sequence-status",
+		// The processing function
+		func(ctx context.Context, input *coreumservicemsg.GetSenderSequenceStatusRequest) (*coreumservicemsg.GetSenderSequenceStatusReply, error) {
+			sequenceStatus, err := GetSequenceManager().GetStatus(ctx, input.Address)
+			if err != nil {
+				return nil, errors.Wrap(err, "GetSequenceManager().GetStatus")
+			}
+			return sequenceStatus, nil
+		},
+	)
+}
+
 func getBalanceOfAddressForDenom(r *mux.Router) *mux.Route {
 	return httpEndpointProcessing(r,
 		// The endpoint
 		"get-balance-of-address",
 		// The processing function
-		func(input *coreumservicemsg.GetBalanceOfAddressForDenomRequest) (*coreumservicemsg.GetBalanceOfAddressForDenomReply, error) {
-			ctx := context.Background()
+		func(ctx context.Context, input *coreumservicemsg.GetBalanceOfAddressForDenomRequest) (*coreumservicemsg.GetBalanceOfAddressForDenomReply, error) {
 			balanceAmount, err := GetBalanceOfAddress(ctx, input.Address, input.Denom)
 			if err != nil {
-				return nil, errors.Errorf("GetBalanceOfAddress: address(%s), denom(%s), %+v", input.Address, input.Denom, err)
+				return nil, errors.Wrapf(err, "GetBalanceOfAddress: address(%s), denom(%s)", input.Address, input.Denom)
 			}
-			return &coreumservicemsg.GetBalanceOfAddressForDenomReply{
+			reply := &coreumservicemsg.GetBalanceOfAddressForDenomReply{
 				Amount: balanceAmount,
-			}, nil
+			}
+			if amount, ok := cosmossdk.NewIntFromString(balanceAmount); ok {
+				reply.DisplayAmount, reply.DisplayUnit = GetDisplayAmount(input.Denom, amount)
+			}
+			return reply, nil
 		},
 	)
 }
@@ -331,39 +1080,48 @@ func getBalanceOfAddressForDenom(r *mux.Router) *mux.Route {
 func httpEndpointProcessing[T any, R any](
 	r *mux.Router,
 	endpoint string,
-	processFunc func(input *T) (*R, error),
+	processFunc func(ctx context.Context, input *T) (*R, error),
 ) *mux.Route {
 	return r.HandleFunc(fmt.Sprintf("/%s/%s", prefix, endpoint), func(writer http.ResponseWriter, request *http.Request) {
+		// Last resort for the unexpected panics, the expected failures are returned as the typed errors
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				handleError(writer, NewServiceError(coreumservicemsg.ErrorCodeInternal, nil, "panic: %v", recovered), endpoint)
+			}
+		}()
+
 		readBytes, err := ioutil.ReadAll(request.Body)
 		if err != nil {
-			handleBadRequest(writer, err, endpoint)
+			handleError(writer, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "failed to read the request body: %v", err), endpoint)
 			return
 		}
 		var requestParams T
 		err = json.Unmarshal(readBytes, &requestParams)
 		if err != nil {
-			handleBadRequest(writer, err, endpoint)
+			handleError(writer, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "failed to parse the request body: %v", err), endpoint)
 			return
 		}
 
 		// Process the response
-		response, err := processFunc(&requestParams)
+		// The context is cancelled when the caller disconnects
+		response, err := processFunc(request.Context(), &requestParams)
 		if err != nil {
-			handleBadRequest(writer, err, endpoint)
+			handleError(writer, err, endpoint)
 			return
 		}
 		if response == nil {
 			err = errors.Errorf("got nil response from processing")
-			handleBadRequest(writer, err, endpoint)
+			handleError(writer, err, endpoint)
 			return
 		}
 
 		responseMessage, err := json.Marshal(response)
 		if err != nil {
-			handleBadRequest(writer, err, endpoint)
+			handleError(writer, err, endpoint)
 			return
 		}
 
+		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusOK)
 		_, err = writer.Write(responseMessage)
 		if err != nil {
@@ -372,14 +1130,22 @@ func httpEndpointProcessing[T any, R any](
 	})
 }
 
-func handleBadRequest(writer http.ResponseWriter, err error, endpoint string) {
-	writer.WriteHeader(http.StatusBadRequest)
+// Write the typed error reply with the HTTP status code corresponding to the error code
+func handleError(writer http.ResponseWriter, err error, endpoint string) {
+	errorReply := ToErrorReply(err)
+	statusCode := GetHTTPStatusOfErrorCode(errorReply.ErrorCode)
+	fmt.Printf("[%v] Request failed with status %d (%s): %+v\n", endpoint, statusCode, errorReply.ErrorCode, err)
+
+	responseMessage, err := json.Marshal(errorReply)
 	if err != nil {
-		fmt.Printf("[%v] Bad request: %+v\n", endpoint, err)
+		fmt.Printf("[%v] Error from json.Marshal(errorReply): %+v\n", endpoint, err)
+		statusCode = http.StatusInternalServerError
+		responseMessage = []byte(`{"ErrorMessage":"failed to encode the error reply","error_code":"internal","retryable":false}`)
 	}
-	_, err = writer.Write([]byte(
-		fmt.Sprintf("{\"ErrorMessage\" : \"bad request: %v\"}", err),
-	))
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_, err = writer.Write(responseMessage)
 	if err != nil {
 		fmt.Printf("[%v] Error from writer.Write(responseMessage): %+v\n", endpoint, err)
 	}
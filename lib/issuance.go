@@ -11,36 +11,36 @@ import (
 )
 
 type TransferTokenParams struct {
+	SenderAddress string
+	// The sequence is the one of the signer, the operator of the treasury if configured
+	SignerAddress  string
 	GasPrice       string
 	GasUsed        uint64
 	SequenceNumber uint64
+	// The hash of the transaction signed with the params, the same as the hash after broadcasting it
+	TxHash string
 }
 
-// Propose the parameters that is used to generate the idempotent transaction
+// Propose the parameters that is used to generate the idempotent transaction.
+// The sequence is tracked with the hash of the transaction, so the params can be persisted and broadcast later:
+// the sequence is not given to the other transfers until the chain commits it or rejects a later sequence.
 func proposeTransferTokenParams(ctx context.Context,
-	senderMnemonic string,
+	signer *transferSigner,
 	recipientAddress string,
 	denom string,
-	toAmount int64,
+	toAmount cosmossdk.Int,
 	memo string,
 ) (*TransferTokenParams, error) {
-	senderInfo, senderKeyring, err := GetKeyringInfoFromMnemonic(senderMnemonic)
-	if err != nil {
-		return nil, errors.Errorf("GetKeyringInfoFromMnemonic: %v", err)
-	}
+	// Retrieve the signer address, the sequences are managed per signer
+	signerAddress := signer.signerAddress.String()
 
-	// Retrieve the sender address
-	senderAddress := senderInfo.GetAddress().String()
-
-	acc, err := GetAccountInfo(ctx, senderAddress)
+	acc, err := GetAccountInfo(ctx, signerAddress)
 	if err != nil {
 		return nil, errors.Errorf("GetAccountInfo: %v", err)
 	}
 	currentSequenceNumber := acc.Sequence
 
-	clientCtx, txFactory, msg, err := PrepareTransferTransaction(ctx,
-		senderKeyring,
-		senderAddress,
+	clientCtx, txFactory, msg, err := signer.prepareTransferTransaction(ctx,
 		recipientAddress,
 		denom,
 		toAmount,
@@ -53,16 +53,39 @@ func proposeTransferTokenParams(ctx context.Context,
 		return nil, errors.Errorf("PrepareTransferTransaction: %v", err)
 	}
 
-	// calculate gas
+	// calculate gas, the simulation expects the committed sequence
 	gasUsedForTransaction, gasPrice, err := CalculateGas(ctx, clientCtx, txFactory, msg)
 	if err != nil {
-		return nil, errors.Errorf("CalculateGas: %v", err)
+		return nil, errors.Wrap(err, "CalculateGas")
+	}
+
+	// Reserve the sequence, so the concurrent transfers of the same signer get the different sequences
+	reservedSequenceNumber, err := GetSequenceManager().Reserve(ctx, signerAddress)
+	if err != nil {
+		return nil, errors.Errorf("GetSequenceManager().Reserve: %v", err)
+	}
+
+	// Sign the transaction with the proposed params to precompute its hash
+	txFactory = txFactory.
+		WithSequence(reservedSequenceNumber).
+		WithGasPrices(gasPrice).
+		WithGas(gasUsedForTransaction)
+	_, signedTransactionInBytes, err := CreateSignedTxWithSigner(ctx, clientCtx, txFactory, signer.signer, msg)
+	if err != nil {
+		GetSequenceManager().Release(signerAddress, reservedSequenceNumber)
+		return nil, errors.Wrap(err, "CreateSignedTxWithSigner")
 	}
+	_, txHash := CalculateHashOfTransaction(signedTransactionInBytes)
+	// The proposed transaction is known, so the sequence is not abandoned before it's broadcast
+	GetSequenceManager().Track(signerAddress, reservedSequenceNumber, txHash)
 
 	transferParams := &TransferTokenParams{
+		SenderAddress:  signer.senderAddress.String(),
+		SignerAddress:  signerAddress,
 		GasPrice:       gasPrice,
 		GasUsed:        gasUsedForTransaction,
-		SequenceNumber: currentSequenceNumber,
+		SequenceNumber: reservedSequenceNumber,
+		TxHash:         txHash,
 	}
 
 	return transferParams, nil
@@ -72,11 +95,14 @@ func ProposeTransferStablyTokenParams(ctx context.Context,
 	senderSecretID string,
 	recipientAddress string,
 	assetDenom string,
-	toAmount int64,
+	toAmount cosmossdk.Int,
 	memo string,
 ) (*TransferTokenParams, error) {
-	treasuryMnemonic := GetTreasuryMnemonicFromSecretID(ctx, senderSecretID)
-	return proposeTransferTokenParams(ctx, treasuryMnemonic, recipientAddress, assetDenom, toAmount, memo)
+	signer, err := getTreasuryTransferSigner(ctx, senderSecretID)
+	if err != nil {
+		return nil, err
+	}
+	return proposeTransferTokenParams(ctx, signer, recipientAddress, assetDenom, toAmount, memo)
 }
 
 func PrepareTransferTransaction(ctx context.Context,
@@ -84,7 +110,7 @@ func PrepareTransferTransaction(ctx context.Context,
 	fromAddressStr string,
 	recipientAddress string,
 	denom string,
-	toAmount int64,
+	toAmount cosmossdk.Int,
 	memo string,
 	sequenceNumber uint64,
 	gasPrice string,
@@ -100,11 +126,10 @@ func PrepareTransferTransaction(ctx context.Context,
 		return client.Context{}, client.Factory{}, nil, errors.Errorf("GetAccountInfo: %v", err)
 	}
 
-	clientCtx := GetClientContext().
-		// Assign the keyring that has the private key to sign the transaction
-		WithKeyring(signingKeyRing).
-		// From the specific address
-		WithFromAddress(fromAddress)
+	clientCtx, err := getSenderClientContext(signingKeyRing, fromAddress)
+	if err != nil {
+		return client.Context{}, client.Factory{}, nil, errors.Errorf("getSenderClientContext: %v", err)
+	}
 
 	// Tx Factory contains the parameters that is used to generate the idempotent transaction
 	txFactory := CoreumTxFactory(clientCtx).
@@ -118,7 +143,7 @@ func PrepareTransferTransaction(ctx context.Context,
 	msg := &banktypes.MsgSend{
 		FromAddress: fromAddressStr,
 		ToAddress:   recipientAddress,
-		Amount:      cosmossdk.NewCoins(cosmossdk.NewInt64Coin(denom, toAmount)),
+		Amount:      cosmossdk.NewCoins(cosmossdk.NewCoin(denom, toAmount)),
 	}
 
 	return clientCtx, txFactory, msg, nil
@@ -131,52 +156,96 @@ func TransferStablyToken(ctx context.Context,
 	senderSecretID string,
 	recipientAddress string,
 	assetDenom string,
-	toAmount int64,
+	toAmount cosmossdk.Int,
 	memo string,
 	sequenceNumber uint64,
 	gasPrice string,
 	gasUsed uint64,
 ) (*cosmossdk.TxResponse, error) {
-	treasuryMnemonic := GetTreasuryMnemonicFromSecretID(ctx, senderSecretID)
-	cosmosTxResult, err := TransferTokenWithMnemonic(ctx,
-		treasuryMnemonic,
+	signer, err := getTreasuryTransferSigner(ctx, senderSecretID)
+	if err != nil {
+		return nil, err
+	}
+	cosmosTxResult, err := transferTokenWithSigner(ctx,
+		signer,
 		recipientAddress,
 		assetDenom,
 		toAmount,
 		memo,
 		sequenceNumber,
+		false,
 		gasPrice,
 		gasUsed,
 	)
 	if err != nil {
-		return nil, errors.Errorf("TransferStablyToken: %v", err)
+		return nil, errors.Wrap(err, "TransferStablyToken")
 	}
 	return cosmosTxResult, nil
 }
 
+// Same as TransferStablyToken, but the sequence is reserved from the sequence manager of the signer.
+// The transfer is retried once with the re-synced sequence if the chain rejects the reserved one.
+func TransferStablyTokenWithAutoSequence(ctx context.Context,
+	senderSecretID string,
+	recipientAddress string,
+	assetDenom string,
+	toAmount cosmossdk.Int,
+	memo string,
+	gasPrice string,
+	gasUsed uint64,
+) (*cosmossdk.TxResponse, error) {
+	signer, err := getTreasuryTransferSigner(ctx, senderSecretID)
+	if err != nil {
+		return nil, err
+	}
+	signerAddress := signer.signerAddress.String()
+
+	const maxAttempts = 2
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		sequenceNumber, err := GetSequenceManager().Reserve(ctx, signerAddress)
+		if err != nil {
+			return nil, errors.Errorf("GetSequenceManager().Reserve: %v", err)
+		}
+
+		cosmosTxResult, err := transferTokenWithSigner(ctx,
+			signer,
+			recipientAddress,
+			assetDenom,
+			toAmount,
+			memo,
+			sequenceNumber,
+			true,
+			gasPrice,
+			gasUsed,
+		)
+		if err == nil {
+			return cosmosTxResult, nil
+		}
+		if !IsSequenceMismatchError(err) {
+			return nil, errors.Wrap(err, "TransferStablyToken")
+		}
+		lastErr = err
+	}
+	return nil, errors.Wrap(lastErr, "TransferStablyToken")
+}
+
 func CalculateHashForTransfer(ctx context.Context,
 	senderSecretID string,
 	recipientAddress string,
 	assetDenom string,
-	toAmount int64,
+	toAmount cosmossdk.Int,
 	memo string,
 	sequenceNumber uint64,
 	gasPrice string,
 	gasUsed uint64,
 ) (string, error) {
-	treasuryMnemonic := GetTreasuryMnemonicFromSecretID(ctx, senderSecretID)
-
-	senderInfo, keyring, err := GetKeyringInfoFromMnemonic(treasuryMnemonic)
+	signer, err := getTreasuryTransferSigner(ctx, senderSecretID)
 	if err != nil {
-		return "", errors.Errorf("GetKeyringInfoFromMnemonic: %v", err)
+		return "", err
 	}
 
-	// Retrieve the sender address
-	senderAddressString := senderInfo.GetAddress().String()
-
-	clientCtx, txFactory, msg, err := PrepareTransferTransaction(ctx,
-		keyring,
-		senderAddressString,
+	clientCtx, txFactory, msg, err := signer.prepareTransferTransaction(ctx,
 		recipientAddress,
 		assetDenom,
 		toAmount,
@@ -189,9 +258,9 @@ func CalculateHashForTransfer(ctx context.Context,
 		return "", errors.Errorf("PrepareTransferTransaction: %v", err)
 	}
 
-	_, signedTransactionInBytes, err := CreateSignedTx(ctx, clientCtx, txFactory, msg)
+	_, signedTransactionInBytes, err := CreateSignedTxWithSigner(ctx, clientCtx, txFactory, signer.signer, msg)
 	if err != nil {
-		return "", errors.Errorf("CreateSignedTx: %v", err)
+		return "", errors.Wrap(err, "CreateSignedTxWithSigner")
 	}
 
 	// Calculate the hash value from the signed transaction
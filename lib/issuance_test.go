@@ -9,6 +9,7 @@ import (
 
 	"coreumservice/go/stably_io/config"
 
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -26,7 +27,7 @@ func TestSuccessfulIssuanceUSDS(t *testing.T) {
 	assetDenom := usdsConfig.TokenDenom
 
 	memo := "testing"
-	var toAmount int64 = 1
+	toAmount := cosmossdk.NewInt(1)
 
 	// Prepare the parameter for issuance
 	transferParams, err := ProposeTransferStablyTokenParams(ctx,
@@ -40,6 +41,16 @@ func TestSuccessfulIssuanceUSDS(t *testing.T) {
 	require.NotEmpty(t, transferParams.GasPrice)
 	require.NotZero(t, transferParams.GasUsed)
 	require.NotZero(t, transferParams.SequenceNumber)
+	require.NotEmpty(t, transferParams.TxHash)
+
+	// The proposed sequence is in flight with the hash until it's broadcast
+	sequenceStatus, err := GetSequenceManager().GetStatus(ctx, transferParams.SignerAddress)
+	require.NoError(t, err)
+	inFlightTxHashes := map[uint64]string{}
+	for _, inFlight := range sequenceStatus.InFlight {
+		inFlightTxHashes[inFlight.Sequence] = inFlight.TxHash
+	}
+	require.Equal(t, transferParams.TxHash, inFlightTxHashes[transferParams.SequenceNumber])
 
 	// Submit the transaction on the blockchain
 	cosmosTxResult, err := TransferStablyToken(ctx,
@@ -53,5 +64,5 @@ func TestSuccessfulIssuanceUSDS(t *testing.T) {
 		transferParams.GasUsed,
 	)
 	require.NoError(t, err)
-	require.NotEmpty(t, cosmosTxResult.TxHash)
+	require.Equal(t, transferParams.TxHash, cosmosTxResult.TxHash)
 }
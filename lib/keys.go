@@ -2,6 +2,7 @@ package coreumservicelib
 
 import (
 	"context"
+	"coreumservicemsg"
 	"fmt"
 
 	awssecretmanager "coreumservice/go/stably_io/secretmanager/aws"
@@ -12,32 +13,49 @@ import (
 	"github.com/pkg/errors"
 )
 
-var coreumTreasuryPrivateMnemonicCache = map[string]string{}
-
-// Function to retrieve the mnemonic of the treasury wallet
+// Function to retrieve the mnemonic of the treasury wallet, empty if it's not available.
+// The secrets are cached by the secret manager, the rotated mnemonic is returned once they're refreshed.
 func GetTreasuryMnemonicFromSecretID(ctx context.Context, secretID string) string {
-	secretValue := coreumTreasuryPrivateMnemonicCache[secretID]
-	if secretValue != "" {
-		return secretValue
+	mnemonic, err := getRequiredTreasuryMnemonic(ctx, secretID)
+	if err != nil {
+		fmt.Printf("[secret manager] Error from getRequiredTreasuryMnemonic: %v\n", err)
+		return ""
+	}
+	return mnemonic
+}
+
+// Same as GetTreasuryMnemonicFromSecretID, but returns the typed error when the mnemonic is not available.
+// The unknown secret ID is not found, only the secrets not loaded or empty are retryable.
+func getRequiredTreasuryMnemonic(ctx context.Context, secretID string) (string, error) {
+	secretDetails := map[string]string{"secret_id": secretID}
+	switch secretID {
+	case awssecretmanager.KeyUsdsTreasuryWalletMnemonic, awssecretmanager.KeyUsdsOperatorWalletMnemonic:
+	default:
+		return "", NewServiceError(coreumservicemsg.ErrorCodeNotFound, secretDetails, "unknown secret ID %v", secretID)
+	}
+
+	tokenizationSecrets, err := awssecretmanager.GetTokenizationSecrets(ctx)
+	if err != nil {
+		return "", NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, secretDetails,
+			"failed to load the secrets of secret ID %v: %v", secretID, err)
 	}
 
-	tokenizationSecrets := awssecretmanager.GetTokenizationSecrets()
 	coreumConfig := tokenizationSecrets.Coreum
 	treasuryMnemonicString := ""
 	switch secretID {
 	case awssecretmanager.KeyUsdsTreasuryWalletMnemonic:
 		treasuryMnemonicString = coreumConfig.USDsTreasuryWalletMnemonic
+	case awssecretmanager.KeyUsdsOperatorWalletMnemonic:
+		treasuryMnemonicString = coreumConfig.USDsOperatorWalletMnemonic
 	}
-
 	if treasuryMnemonicString == "" {
-		fmt.Printf("missing treasuryMnemonicString for secret ID %v", secretID)
-		return ""
+		return "", NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, secretDetails,
+			"missing treasury mnemonic for secret ID %v", secretID)
 	}
 
-	// Cache the fetched value
-	coreumTreasuryPrivateMnemonicCache[secretID] = treasuryMnemonicString
+	trackTreasuryKey(secretID, treasuryMnemonicString)
 
-	return treasuryMnemonicString
+	return treasuryMnemonicString, nil
 }
 
 func GetKeyringInfoFromMnemonic(mnemonic string) (keyring.Info, keyring.Keyring, error) {
@@ -0,0 +1,31 @@
+package coreumservicelib
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "coreumservice"
+
+//nolint:gochecknoglobals // The metrics are registered once in the default registry
+var (
+	// Number of the requests served by each node, result is either "success" or "failure"
+	nodeRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_requests_total",
+		Help:      "Number of the requests sent to each blockchain node",
+	}, []string{"node", "protocol", "method", "result"})
+
+	// 1 if the node is used for routing, 0 if it is ejected
+	nodeHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_healthy",
+		Help:      "Whether the blockchain node passed the last health check",
+	}, []string{"node"})
+
+	nodeLatestBlockHeight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_latest_block_height",
+		Help:      "The latest block height reported by the blockchain node",
+	}, []string{"node"})
+)
@@ -0,0 +1,557 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	multisigtypes "github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	txsigning "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+)
+
+// The multisig public key only supports the legacy amino JSON sign mode for the keys
+const multisigSignMode = txsigning.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+
+// The session not broadcast within this duration is dropped, its sequence can be used by a new session
+const multisigSessionTTL = 24 * time.Hour
+
+// The multisig accounts registered in this instance and their pending signing sessions.
+// They're kept in memory only: after a restart, the accounts are registered and the sessions are created again.
+//
+//nolint:gochecknoglobals
+var (
+	multisigMu       sync.Mutex
+	multisigAccounts = map[string]*kmultisig.LegacyAminoPubKey{}
+	multisigSessions = map[string]*multisigSession{}
+)
+
+// The unsigned transaction of the multisig account collecting the partial signatures
+type multisigSession struct {
+	// Held while the signatures are combined and broadcast
+	mu sync.Mutex
+
+	id              string
+	multisigAddress string
+	pubKey          *kmultisig.LegacyAminoPubKey
+	unsignedTxBytes []byte
+	signBytes       []byte
+	accountNumber   uint64
+	sequenceNumber  uint64
+	gasPrice        string
+	gasUsed         uint64
+	// The signatures by the index of the key in the multisig public key
+	signatures map[int][]byte
+	createdAt  time.Time
+	// Set once the combined transaction is broadcast
+	txHash string
+	// Set once the session is cancelled, the submissions holding the session must not broadcast it anymore
+	cancelled bool
+}
+
+// Register the k-of-n multisig account of the public keys, the address depends on the order of the keys.
+// The registration is lost when the service restarts, registering the same keys again gives the same account.
+func RegisterMultisigAccount(pubKeys []string, threshold uint32) (*coreumservicemsg.MultisigAccount, error) {
+	if threshold == 0 || int(threshold) > len(pubKeys) {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"the threshold %d must be between 1 and the number of public keys %d", threshold, len(pubKeys))
+	}
+	keys := []cryptotypes.PubKey{}
+	for i, pubKey := range pubKeys {
+		key, err := decodeSecp256k1PubKey(pubKey)
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, map[string]string{"index": strconv.Itoa(i)}, "invalid public key: %v", err)
+		}
+		for _, registeredKey := range keys {
+			if registeredKey.Equals(key) {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, map[string]string{"index": strconv.Itoa(i)}, "duplicate public key %v", pubKey)
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	multisigPubKey := kmultisig.NewLegacyAminoPubKey(int(threshold), keys)
+	multisigAddress := cosmossdk.AccAddress(multisigPubKey.Address()).String()
+
+	multisigMu.Lock()
+	defer multisigMu.Unlock()
+	multisigAccounts[multisigAddress] = multisigPubKey
+	return toMultisigAccount(multisigAddress, multisigPubKey), nil
+}
+
+func decodeSecp256k1PubKey(pubKey string) (*secp256k1.PubKey, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(pubKey)
+	if err != nil {
+		return nil, errors.Errorf("invalid base64: %v", err)
+	}
+	if len(keyBytes) != secp256k1.PubKeySize {
+		return nil, errors.Errorf("expected the compressed secp256k1 key of %d bytes, got %d bytes", secp256k1.PubKeySize, len(keyBytes))
+	}
+	return &secp256k1.PubKey{Key: keyBytes}, nil
+}
+
+func toMultisigAccount(multisigAddress string, multisigPubKey *kmultisig.LegacyAminoPubKey) *coreumservicemsg.MultisigAccount {
+	account := &coreumservicemsg.MultisigAccount{
+		Address:   multisigAddress,
+		PubKeys:   []string{},
+		Threshold: uint32(multisigPubKey.GetThreshold()),
+	}
+	for _, key := range multisigPubKey.GetPubKeys() {
+		account.PubKeys = append(account.PubKeys, base64.StdEncoding.EncodeToString(key.Bytes()))
+	}
+	return account
+}
+
+func getMultisigPubKey(multisigAddress string) (*kmultisig.LegacyAminoPubKey, error) {
+	multisigMu.Lock()
+	defer multisigMu.Unlock()
+	multisigPubKey, ok := multisigAccounts[multisigAddress]
+	if !ok {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeNotFound, nil, "the multisig account %v is not registered", multisigAddress)
+	}
+	return multisigPubKey, nil
+}
+
+// Build the unsigned transaction of the messages signed by the multisig account, and start collecting the signatures.
+// The gas is estimated with the signatures of all the keys if gasPrice or gasUsed is not set.
+// Only one session is pending per sequence of the account, the sessions of the committed sequences are dropped.
+// With autoSequence, the session takes the lowest sequence from the account sequence without a pending session.
+func CreateMultisigSession(ctx context.Context,
+	multisigAddress string,
+	msgs []cosmossdk.Msg,
+	memo string,
+	sequenceNumber uint64,
+	autoSequence bool,
+	gasPrice string,
+	gasUsed uint64,
+) (*coreumservicemsg.MultisigSession, error) {
+	multisigPubKey, err := getMultisigPubKey(multisigAddress)
+	if err != nil {
+		return nil, err
+	}
+	address := cosmossdk.AccAddress(multisigPubKey.Address())
+	for i, msg := range msgs {
+		// The simulation is skipped with the gas given, the invalid message would only fail once all the keys signed
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, map[string]string{"index": strconv.Itoa(i)},
+				"invalid message: %v", err)
+		}
+		for _, signer := range msg.GetSigners() {
+			if !signer.Equals(address) {
+				return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, map[string]string{"index": strconv.Itoa(i)},
+					"the message is signed by %v, not by the multisig account", signer.String())
+			}
+		}
+	}
+
+	acc, err := GetAccountInfo(ctx, multisigAddress)
+	if err != nil {
+		return nil, errors.Errorf("GetAccountInfo: %v", err)
+	}
+	dropCommittedMultisigSessions(multisigAddress, acc.Sequence)
+	if autoSequence {
+		sequenceNumber = nextMultisigSequence(multisigAddress, acc.Sequence)
+	} else if sequenceNumber < acc.Sequence {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeConflict, nil,
+			"the sequence %d of %v is already used, the account sequence is %d", sequenceNumber, multisigAddress, acc.Sequence)
+	}
+
+	// No key is needed to build the transaction
+	clientCtx, err := getSenderClientContext(keyring.NewInMemory(), address)
+	if err != nil {
+		return nil, errors.Errorf("getSenderClientContext: %v", err)
+	}
+	txFactory := CoreumTxFactory(clientCtx).
+		WithAccountNumber(acc.AccountNumber).
+		WithSequence(sequenceNumber).
+		WithMemo(memo).
+		WithSignMode(multisigSignMode)
+
+	if gasPrice == "" || gasUsed == 0 {
+		// The simulation expects the committed sequence
+		gasUsed, gasPrice, err = calculateMultisigGas(ctx, clientCtx, txFactory.WithSequence(acc.Sequence), multisigPubKey, msgs...)
+		if err != nil {
+			return nil, errors.Wrap(err, "calculateMultisigGas")
+		}
+	}
+	txFactory = txFactory.
+		WithGasPrices(gasPrice).
+		WithGas(gasUsed)
+	if err := checkFactoryFeeCeiling(txFactory); err != nil {
+		return nil, err
+	}
+
+	unsignedTx, err := txFactory.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, errors.Errorf("txFactory.BuildUnsignedTx: %v", err)
+	}
+	unsignedTx.SetFeeGranter(clientCtx.FeeGranterAddress())
+	unsignedTxBytes, err := clientCtx.TxConfig().TxEncoder()(unsignedTx.GetTx())
+	if err != nil {
+		return nil, errors.Errorf("TxEncoder: %v", err)
+	}
+	signBytes, err := getMultisigSignBytes(unsignedTx.GetTx(), acc.AccountNumber, sequenceNumber)
+	if err != nil {
+		return nil, errors.Errorf("getMultisigSignBytes: %v", err)
+	}
+
+	_, sessionID := CalculateHashOfTransaction(unsignedTxBytes)
+	session := &multisigSession{
+		id:              sessionID,
+		multisigAddress: multisigAddress,
+		pubKey:          multisigPubKey,
+		unsignedTxBytes: unsignedTxBytes,
+		signBytes:       signBytes,
+		accountNumber:   acc.AccountNumber,
+		sequenceNumber:  sequenceNumber,
+		gasPrice:        gasPrice,
+		gasUsed:         gasUsed,
+		signatures:      map[int][]byte{},
+		createdAt:       time.Now(),
+	}
+	session, err = addMultisigSession(session)
+	if err != nil {
+		return nil, err
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.toMsg(), nil
+}
+
+// Estimate the gas with the placeholder signatures of all the keys of the multisig
+func calculateMultisigGas(ctx context.Context,
+	clientCtx client.Context,
+	txf client.Factory,
+	multisigPubKey *kmultisig.LegacyAminoPubKey,
+	msgs ...cosmossdk.Msg,
+) (uint64, string, error) {
+	gasPrice, err := GetGasPriceByStrategy(ctx, clientCtx)
+	if err != nil {
+		return 0, "", errors.Errorf("GetGasPriceByStrategy: %v", err)
+	}
+
+	keys := multisigPubKey.GetPubKeys()
+	simSignatureData := multisigtypes.NewMultisig(len(keys))
+	for i := range keys {
+		// The signature isn't checked, but its size costs gas
+		multisigtypes.AddSignature(simSignatureData, &txsigning.SingleSignatureData{
+			SignMode:  multisigSignMode,
+			Signature: make([]byte, 64),
+		}, i)
+	}
+	_, gasUsed, err := calculateGasWithSignature(ctx, clientCtx, txf.WithGasPrices(gasPrice.String()), txsigning.SignatureV2{
+		PubKey:   multisigPubKey,
+		Data:     simSignatureData,
+		Sequence: txf.Sequence(),
+	}, msgs...)
+	if err != nil {
+		return 0, "", errors.Errorf("calculateGasWithSignature: %v", err)
+	}
+	if err := CheckFeeCeiling(gasPrice, gasUsed); err != nil {
+		return 0, "", err
+	}
+	return gasUsed, gasPrice.String(), nil
+}
+
+// Return the bytes every key of the multisig signs
+func getMultisigSignBytes(transaction cosmossdk.Tx, accountNumber uint64, sequenceNumber uint64) ([]byte, error) {
+	signBytes, err := GetAppEncodingConfig().TxConfig.SignModeHandler().GetSignBytes(multisigSignMode,
+		authsigning.SignerData{
+			ChainID:       GetChainIDByStage(),
+			AccountNumber: accountNumber,
+			Sequence:      sequenceNumber,
+		},
+		transaction,
+	)
+	if err != nil {
+		return nil, errors.Errorf("GetSignBytes: %v", err)
+	}
+	return signBytes, nil
+}
+
+// Return the pending session of the same transaction if any, so the collected signatures are kept
+func addMultisigSession(session *multisigSession) (*multisigSession, error) {
+	multisigMu.Lock()
+	defer multisigMu.Unlock()
+	dropExpiredMultisigSessionsLocked(time.Now())
+	if pendingSession, ok := multisigSessions[session.id]; ok {
+		return pendingSession, nil
+	}
+	for _, pendingSession := range multisigSessions {
+		if pendingSession.multisigAddress == session.multisigAddress && pendingSession.sequenceNumber == session.sequenceNumber {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeConflict, map[string]string{"session_id": pendingSession.id},
+				"the session %v of %v is already pending with the sequence %d", pendingSession.id, session.multisigAddress, session.sequenceNumber)
+		}
+	}
+	multisigSessions[session.id] = session
+	return session, nil
+}
+
+func getMultisigSession(sessionID string) (*multisigSession, error) {
+	multisigMu.Lock()
+	defer multisigMu.Unlock()
+	dropExpiredMultisigSessionsLocked(time.Now())
+	session, ok := multisigSessions[sessionID]
+	if !ok {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeNotFound, nil, "no pending multisig session %v", sessionID)
+	}
+	return session, nil
+}
+
+// Drop the sessions not broadcast within multisigSessionTTL
+func dropExpiredMultisigSessionsLocked(now time.Time) {
+	for sessionID, session := range multisigSessions {
+		if now.Sub(session.createdAt) > multisigSessionTTL {
+			delete(multisigSessions, sessionID)
+		}
+	}
+}
+
+// Drop the sessions of the account with the sequences already committed on the chain, they can't be broadcast anymore
+func dropCommittedMultisigSessions(multisigAddress string, chainSequence uint64) {
+	multisigMu.Lock()
+	defer multisigMu.Unlock()
+	for sessionID, session := range multisigSessions {
+		if session.multisigAddress == multisigAddress && session.sequenceNumber < chainSequence {
+			delete(multisigSessions, sessionID)
+		}
+	}
+}
+
+// Return the lowest sequence from the chain sequence without a pending session of the account
+func nextMultisigSequence(multisigAddress string, chainSequence uint64) uint64 {
+	multisigMu.Lock()
+	defer multisigMu.Unlock()
+	dropExpiredMultisigSessionsLocked(time.Now())
+	pendingSequences := map[uint64]bool{}
+	for _, session := range multisigSessions {
+		if session.multisigAddress == multisigAddress {
+			pendingSequences[session.sequenceNumber] = true
+		}
+	}
+	sequence := chainSequence
+	for pendingSequences[sequence] {
+		sequence++
+	}
+	return sequence
+}
+
+// Drop the pending session, so its sequence can be used by a new session
+func CancelMultisigSession(sessionID string) (*coreumservicemsg.MultisigSession, error) {
+	session, err := getMultisigSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the concurrent broadcast of the session
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if err := session.checkPendingLocked(); err != nil {
+		return nil, err
+	}
+	session.cancelled = true
+	multisigMu.Lock()
+	delete(multisigSessions, session.id)
+	multisigMu.Unlock()
+	return session.toMsg(), nil
+}
+
+// Sign the sign bytes of the session with the signer and submit the signature
+func SignMultisigSessionWithSigner(ctx context.Context,
+	sessionID string,
+	signer Signer,
+) (*coreumservicemsg.SubmitMultisigSignatureReply, error) {
+	session, err := getMultisigSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(ctx, session.signBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "signer.Sign")
+	}
+	return SubmitMultisigSignature(ctx, sessionID, signer.GetPubKey(), signature)
+}
+
+// Add the partial signature of the key to the session.
+// Once the threshold is met, the signatures are combined and the transaction is broadcast.
+func SubmitMultisigSignature(ctx context.Context,
+	sessionID string,
+	pubKey cryptotypes.PubKey,
+	signature []byte,
+) (*coreumservicemsg.SubmitMultisigSignatureReply, error) {
+	session, err := getMultisigSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return session.submitSignature(ctx, pubKey, signature)
+}
+
+func (s *multisigSession) submitSignature(ctx context.Context,
+	pubKey cryptotypes.PubKey,
+	signature []byte,
+) (*coreumservicemsg.SubmitMultisigSignatureReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The concurrent signature has already met the threshold, or the session is cancelled since it was found
+	if err := s.checkPendingLocked(); err != nil {
+		return nil, err
+	}
+	if err := s.addSignature(pubKey, signature); err != nil {
+		return nil, err
+	}
+	reply := &coreumservicemsg.SubmitMultisigSignatureReply{}
+	if len(s.signatures) < int(s.pubKey.GetThreshold()) {
+		reply.Session = s.toMsg()
+		return reply, nil
+	}
+
+	signedTxBytes, err := s.combineSignatures()
+	if err != nil {
+		return nil, errors.Errorf("combineSignatures: %v", err)
+	}
+	broadcastResult, err := broadcastSignedTxBytes(ctx, signedTxBytes, "", nil)
+	if err != nil {
+		// The session stays pending, the next signature retries the broadcast
+		return nil, errors.Wrap(err, "broadcastSignedTxBytes")
+	}
+
+	s.txHash = broadcastResult.TxHash
+	multisigMu.Lock()
+	delete(multisigSessions, s.id)
+	multisigMu.Unlock()
+
+	reply.Session = s.toMsg()
+	reply.TxHash = broadcastResult.TxHash
+	reply.State = broadcastResult.State
+	return reply, nil
+}
+
+// Verify the signature of the key of the multisig and keep it, replacing the previous signature of the key
+// Whether the session can still be signed, broadcast or cancelled
+func (s *multisigSession) checkPendingLocked() error {
+	if s.txHash != "" {
+		return NewServiceError(coreumservicemsg.ErrorCodeConflict, map[string]string{"tx_hash": s.txHash},
+			"the session %v is already broadcast as %v", s.id, s.txHash)
+	}
+	if s.cancelled {
+		return NewServiceError(coreumservicemsg.ErrorCodeNotFound, nil, "the session %v is cancelled", s.id)
+	}
+	return nil
+}
+
+func (s *multisigSession) addSignature(pubKey cryptotypes.PubKey, signature []byte) error {
+	index := -1
+	for i, key := range s.pubKey.GetPubKeys() {
+		if key.Equals(pubKey) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"%v is not a key of the multisig account %v", cosmossdk.AccAddress(pubKey.Address()).String(), s.multisigAddress)
+	}
+	if !pubKey.VerifySignature(s.signBytes, signature) {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"invalid signature of %v for the session %v", cosmossdk.AccAddress(pubKey.Address()).String(), s.id)
+	}
+	s.signatures[index] = signature
+	return nil
+}
+
+// Return the protobuf bytes of the transaction signed by the multisig with the collected signatures
+func (s *multisigSession) combineSignatures() ([]byte, error) {
+	txConfig := GetAppEncodingConfig().TxConfig
+	unsignedTx, err := txConfig.TxDecoder()(s.unsignedTxBytes)
+	if err != nil {
+		return nil, errors.Errorf("TxDecoder: %v", err)
+	}
+	txBuilder, err := txConfig.WrapTxBuilder(unsignedTx)
+	if err != nil {
+		return nil, errors.Errorf("txConfig.WrapTxBuilder: %v", err)
+	}
+
+	signatureData := multisigtypes.NewMultisig(len(s.pubKey.GetPubKeys()))
+	for index, signature := range s.signatures {
+		multisigtypes.AddSignature(signatureData, &txsigning.SingleSignatureData{
+			SignMode:  multisigSignMode,
+			Signature: signature,
+		}, index)
+	}
+	err = txBuilder.SetSignatures(txsigning.SignatureV2{
+		PubKey:   s.pubKey,
+		Data:     signatureData,
+		Sequence: s.sequenceNumber,
+	})
+	if err != nil {
+		return nil, errors.Errorf("txBuilder.SetSignatures: %v", err)
+	}
+
+	signedTxBytes, err := txConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, errors.Errorf("txConfig.TxEncoder: %v", err)
+	}
+	return signedTxBytes, nil
+}
+
+func (s *multisigSession) toMsg() *coreumservicemsg.MultisigSession {
+	session := &coreumservicemsg.MultisigSession{
+		SessionID:       s.id,
+		MultisigAddress: s.multisigAddress,
+		Threshold:       uint32(s.pubKey.GetThreshold()),
+		UnsignedTx:      base64.StdEncoding.EncodeToString(s.unsignedTxBytes),
+		SignBytes:       base64.StdEncoding.EncodeToString(s.signBytes),
+		AccountNumber:   s.accountNumber,
+		SequenceNumber:  s.sequenceNumber,
+		ChainID:         GetChainIDByStage(),
+		GasPrice:        s.gasPrice,
+		GasUsed:         s.gasUsed,
+		SignedBy:        []string{},
+		CreatedAt:       s.createdAt.Unix(),
+		ExpiresAt:       s.createdAt.Add(multisigSessionTTL).Unix(),
+	}
+	for i, key := range s.pubKey.GetPubKeys() {
+		if _, ok := s.signatures[i]; ok {
+			session.SignedBy = append(session.SignedBy, cosmossdk.AccAddress(key.Address()).String())
+		}
+	}
+	return session
+}
+
+// Return the pending sessions, only the ones of the multisig account if multisigAddress is set
+func GetMultisigSessions(multisigAddress string) []*coreumservicemsg.MultisigSession {
+	multisigMu.Lock()
+	dropExpiredMultisigSessionsLocked(time.Now())
+	pendingSessions := []*multisigSession{}
+	for _, session := range multisigSessions {
+		if multisigAddress == "" || session.multisigAddress == multisigAddress {
+			pendingSessions = append(pendingSessions, session)
+		}
+	}
+	multisigMu.Unlock()
+
+	sort.Slice(pendingSessions, func(i, j int) bool {
+		if !pendingSessions[i].createdAt.Equal(pendingSessions[j].createdAt) {
+			return pendingSessions[i].createdAt.Before(pendingSessions[j].createdAt)
+		}
+		return pendingSessions[i].id < pendingSessions[j].id
+	})
+	sessions := []*coreumservicemsg.MultisigSession{}
+	for _, session := range pendingSessions {
+		session.mu.Lock()
+		sessions = append(sessions, session.toMsg())
+		session.mu.Unlock()
+	}
+	return sessions
+}
@@ -0,0 +1,289 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var multisigTestMnemonics = []string{
+	offlineTestMnemonic,
+	offlineTestOtherMnemonic,
+	"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+}
+
+func mustPubKeyFromMnemonic(t *testing.T, mnemonic string) string {
+	keyringInfo, _, err := GetKeyringInfoFromMnemonic(mnemonic)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(keyringInfo.GetPubKey().Bytes())
+}
+
+// Sign the sign bytes of the multisig session with the key of the mnemonic
+func signMultisigBytesWithMnemonic(signerMnemonic string, signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	signer, err := NewMnemonicSigner(signerMnemonic)
+	if err != nil {
+		return nil, nil, errors.Errorf("NewMnemonicSigner: %v", err)
+	}
+	signature, err := signer.Sign(context.Background(), signBytes)
+	if err != nil {
+		return nil, nil, errors.Errorf("signer.Sign: %v", err)
+	}
+	return signature, signer.GetPubKey(), nil
+}
+
+// Register the 2-of-3 multisig account of the test mnemonics
+func mustRegisterTestMultisigAccount(t *testing.T) *coreumservicemsg.MultisigAccount {
+	pubKeys := []string{}
+	for _, mnemonic := range multisigTestMnemonics {
+		pubKeys = append(pubKeys, mustPubKeyFromMnemonic(t, mnemonic))
+	}
+	account, err := RegisterMultisigAccount(pubKeys, 2)
+	require.NoError(t, err)
+	return account
+}
+
+func TestRegisterMultisigAccount(t *testing.T) {
+	account := mustRegisterTestMultisigAccount(t)
+	require.Len(t, account.PubKeys, 3)
+	require.Equal(t, uint32(2), account.Threshold)
+
+	multisigPubKey, err := getMultisigPubKey(account.Address)
+	require.NoError(t, err)
+	require.Equal(t, account.Address, cosmossdk.AccAddress(multisigPubKey.Address()).String())
+
+	pubKey := mustPubKeyFromMnemonic(t, offlineTestMnemonic)
+	testCases := []struct {
+		name      string
+		pubKeys   []string
+		threshold uint32
+	}{
+		{name: "zero threshold", pubKeys: []string{pubKey}, threshold: 0},
+		{name: "threshold above the keys", pubKeys: []string{pubKey}, threshold: 2},
+		{name: "duplicate key", pubKeys: []string{pubKey, pubKey}, threshold: 1},
+		{name: "invalid key", pubKeys: []string{base64.StdEncoding.EncodeToString([]byte("key"))}, threshold: 1},
+	}
+	for _, testCase := range testCases {
+		_, err := RegisterMultisigAccount(testCase.pubKeys, testCase.threshold)
+		require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode, testCase.name)
+	}
+
+	_, err = getMultisigPubKey(mustAddressFromMnemonic(t, offlineTestMnemonic))
+	require.Equal(t, coreumservicemsg.ErrorCodeNotFound, ToErrorReply(err).ErrorCode)
+}
+
+func TestCreateMultisigSessionInvalidMessage(t *testing.T) {
+	account := mustRegisterTestMultisigAccount(t)
+	testCases := []struct {
+		name string
+		msg  *banktypes.MsgSend
+	}{
+		{
+			name: "invalid recipient",
+			msg: &banktypes.MsgSend{
+				FromAddress: account.Address,
+				ToAddress:   "testcore1malformed",
+				Amount:      cosmossdk.NewCoins(cosmossdk.NewCoin("utestcore", cosmossdk.NewInt(100))),
+			},
+		},
+		{
+			name: "zero amount",
+			msg: &banktypes.MsgSend{
+				FromAddress: account.Address,
+				ToAddress:   account.Address,
+				Amount:      cosmossdk.Coins{},
+			},
+		},
+	}
+	// The gas is given, so the messages are not simulated
+	for _, testCase := range testCases {
+		_, err := CreateMultisigSession(context.Background(), account.Address, []cosmossdk.Msg{testCase.msg}, "", 0, false, "0.0625utestcore", 100000)
+		errorReply := ToErrorReply(err)
+		require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, errorReply.ErrorCode, testCase.name)
+		require.Equal(t, "0", errorReply.Details["index"], testCase.name)
+	}
+}
+
+func newTestMultisigSession(t *testing.T, id string, sequenceNumber uint64) *multisigSession {
+	account := mustRegisterTestMultisigAccount(t)
+	multisigPubKey, err := getMultisigPubKey(account.Address)
+	require.NoError(t, err)
+
+	unsignedTx := newTestUnsignedTransfer(t, account.Address)
+	unsignedTxBytes, err := GetAppEncodingConfig().TxConfig.TxEncoder()(unsignedTx)
+	require.NoError(t, err)
+	signBytes, err := getMultisigSignBytes(unsignedTx, 7, sequenceNumber)
+	require.NoError(t, err)
+
+	return &multisigSession{
+		id:              id,
+		multisigAddress: account.Address,
+		pubKey:          multisigPubKey,
+		unsignedTxBytes: unsignedTxBytes,
+		signBytes:       signBytes,
+		accountNumber:   7,
+		sequenceNumber:  sequenceNumber,
+		signatures:      map[int][]byte{},
+		createdAt:       time.Now(),
+	}
+}
+
+func TestMultisigSessionSignatures(t *testing.T) {
+	session := newTestMultisigSession(t, "session", 3)
+
+	signature, pubKey, err := signMultisigBytesWithMnemonic(multisigTestMnemonics[0], session.signBytes)
+	require.NoError(t, err)
+	require.NoError(t, session.addSignature(pubKey, signature))
+
+	// The signature of the other bytes
+	otherSignature, otherPubKey, err := signMultisigBytesWithMnemonic(multisigTestMnemonics[2], []byte("other"))
+	require.NoError(t, err)
+	err = session.addSignature(otherPubKey, otherSignature)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+
+	// The key outside the multisig
+	outsiderSignature, outsiderPubKey, err := signMultisigBytesWithMnemonic(
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", session.signBytes)
+	require.NoError(t, err)
+	err = session.addSignature(outsiderPubKey, outsiderSignature)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+
+	signature, pubKey, err = signMultisigBytesWithMnemonic(multisigTestMnemonics[2], session.signBytes)
+	require.NoError(t, err)
+	require.NoError(t, session.addSignature(pubKey, signature))
+	require.Equal(t, []string{
+		mustAddressFromMnemonic(t, multisigTestMnemonics[0]),
+		mustAddressFromMnemonic(t, multisigTestMnemonics[2]),
+	}, session.toMsg().SignedBy)
+
+	signedTxBytes, err := session.combineSignatures()
+	require.NoError(t, err)
+
+	// The combined signature is valid for the multisig account
+	signedTx, err := GetAppEncodingConfig().TxConfig.TxDecoder()(signedTxBytes)
+	require.NoError(t, err)
+	sigTx, ok := signedTx.(authsigning.SigVerifiableTx)
+	require.True(t, ok)
+	signatures, err := sigTx.GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+	_, isMultisig := signatures[0].PubKey.(*kmultisig.LegacyAminoPubKey)
+	require.True(t, isMultisig)
+	require.NoError(t, authsigning.VerifySignature(signatures[0].PubKey,
+		authsigning.SignerData{ChainID: GetChainIDByStage(), AccountNumber: 7, Sequence: 3},
+		signatures[0].Data,
+		GetAppEncodingConfig().TxConfig.SignModeHandler(),
+		signedTx,
+	))
+}
+
+func TestAddMultisigSession(t *testing.T) {
+	session := newTestMultisigSession(t, "TestAddMultisigSession", 5)
+	defer func() {
+		multisigMu.Lock()
+		delete(multisigSessions, session.id)
+		multisigMu.Unlock()
+	}()
+
+	pendingSession, err := addMultisigSession(session)
+	require.NoError(t, err)
+	require.Equal(t, session, pendingSession)
+
+	// The same transaction keeps the pending session
+	pendingSession, err = addMultisigSession(newTestMultisigSession(t, session.id, 5))
+	require.NoError(t, err)
+	require.Equal(t, session, pendingSession)
+
+	// The other transaction with the same sequence
+	_, err = addMultisigSession(newTestMultisigSession(t, "other", 5))
+	require.Equal(t, coreumservicemsg.ErrorCodeConflict, ToErrorReply(err).ErrorCode)
+
+	sessions := GetMultisigSessions(session.multisigAddress)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session.id, sessions[0].SessionID)
+	require.Empty(t, GetMultisigSessions(mustAddressFromMnemonic(t, offlineTestMnemonic)))
+}
+
+func TestMultisigSessionLifetime(t *testing.T) {
+	session := newTestMultisigSession(t, "TestMultisigSessionLifetime", 5)
+	expiredSession := newTestMultisigSession(t, "TestMultisigSessionLifetime expired", 6)
+	expiredSession.createdAt = time.Now().Add(-multisigSessionTTL - time.Minute)
+	multisigMu.Lock()
+	multisigSessions[session.id] = session
+	multisigSessions[expiredSession.id] = expiredSession
+	multisigMu.Unlock()
+	defer func() {
+		multisigMu.Lock()
+		delete(multisigSessions, session.id)
+		delete(multisigSessions, expiredSession.id)
+		multisigMu.Unlock()
+	}()
+
+	t.Run("Case with the expired session", func(it *testing.T) {
+		_, err := getMultisigSession(expiredSession.id)
+		require.Equal(it, coreumservicemsg.ErrorCodeNotFound, ToErrorReply(err).ErrorCode)
+		sessions := GetMultisigSessions(session.multisigAddress)
+		require.Len(it, sessions, 1)
+		require.Equal(it, session.createdAt.Add(multisigSessionTTL).Unix(), sessions[0].ExpiresAt)
+	})
+
+	t.Run("Case with the sequence of the pending session", func(it *testing.T) {
+		require.Equal(it, uint64(4), nextMultisigSequence(session.multisigAddress, 4))
+		require.Equal(it, uint64(6), nextMultisigSequence(session.multisigAddress, 5))
+		require.Equal(it, uint64(6), nextMultisigSequence(session.multisigAddress, 6))
+	})
+
+	t.Run("Case with the committed sequence", func(it *testing.T) {
+		dropCommittedMultisigSessions(session.multisigAddress, 5)
+		_, err := getMultisigSession(session.id)
+		require.NoError(it, err)
+		dropCommittedMultisigSessions(session.multisigAddress, 6)
+		_, err = getMultisigSession(session.id)
+		require.Equal(it, coreumservicemsg.ErrorCodeNotFound, ToErrorReply(err).ErrorCode)
+	})
+}
+
+func TestCancelMultisigSession(t *testing.T) {
+	session := newTestMultisigSession(t, "TestCancelMultisigSession", 5)
+	broadcastSession := newTestMultisigSession(t, "TestCancelMultisigSession broadcast", 6)
+	broadcastSession.txHash = "ABCD"
+	multisigMu.Lock()
+	multisigSessions[session.id] = session
+	multisigSessions[broadcastSession.id] = broadcastSession
+	multisigMu.Unlock()
+	defer func() {
+		multisigMu.Lock()
+		delete(multisigSessions, broadcastSession.id)
+		multisigMu.Unlock()
+	}()
+
+	cancelledSession, err := CancelMultisigSession(session.id)
+	require.NoError(t, err)
+	require.Equal(t, session.id, cancelledSession.SessionID)
+	_, err = getMultisigSession(session.id)
+	require.Equal(t, coreumservicemsg.ErrorCodeNotFound, ToErrorReply(err).ErrorCode)
+
+	// The submission that found the session before it was cancelled doesn't add the signature
+	signature, pubKey, err := signMultisigBytesWithMnemonic(multisigTestMnemonics[0], session.signBytes)
+	require.NoError(t, err)
+	_, err = session.submitSignature(context.Background(), pubKey, signature)
+	require.Equal(t, coreumservicemsg.ErrorCodeNotFound, ToErrorReply(err).ErrorCode)
+	require.Empty(t, session.signatures)
+
+	_, err = CancelMultisigSession(session.id)
+	require.Equal(t, coreumservicemsg.ErrorCodeNotFound, ToErrorReply(err).ErrorCode)
+	_, err = CancelMultisigSession(broadcastSession.id)
+	require.Equal(t, coreumservicemsg.ErrorCodeConflict, ToErrorReply(err).ErrorCode)
+}
@@ -0,0 +1,424 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+	"coreumservicemsg"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	protobufgrpc "github.com/gogo/protobuf/grpc"
+	"github.com/pkg/errors"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	protocolGRPC          = "grpc"
+	protocolTendermintRPC = "tendermint_rpc"
+	healthCheckTimeout    = 10 * time.Second
+	// The gRPC method broadcasting the transaction, it's not failed over
+	broadcastTxMethod = "/cosmos.tx.v1beta1.Service/BroadcastTx"
+)
+
+//nolint:gochecknoglobals // The node pool is shared by all the requests
+var (
+	defaultNodePool     *NodePool
+	defaultNodePoolOnce sync.Once
+)
+
+// NodePool routes the requests to the healthy blockchain nodes.
+// The nodes are probed periodically, the ones that fail, catch up or lag behind are ejected
+// until they pass the health check again.
+type NodePool struct {
+	nodes               []*poolNode
+	maxBlockLag         int64
+	healthCheckInterval time.Duration
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+}
+
+type poolNode struct {
+	name        string
+	connections *ConnectionManager
+
+	mu           sync.RWMutex
+	healthy      bool
+	latestHeight int64
+	lastError    error
+}
+
+// The pool needs at least one node and the positive health check interval
+func NewNodePool(nodes []coreumconfig.CoreumNodeConfig, maxBlockLag int64, healthCheckInterval time.Duration) (*NodePool, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("no node is configured")
+	}
+	if healthCheckInterval <= 0 {
+		return nil, errors.Errorf("invalid node health check interval %v", healthCheckInterval)
+	}
+	pool := &NodePool{
+		maxBlockLag:         maxBlockLag,
+		healthCheckInterval: healthCheckInterval,
+		stop:                make(chan struct{}),
+	}
+	for _, node := range nodes {
+		pool.nodes = append(pool.nodes, &poolNode{
+			name:        node.Name,
+			connections: NewConnectionManager(node.GRPCNodeURL, node.TendermintRPCNodeURL),
+			// Assume healthy until the first health check
+			healthy: true,
+		})
+		nodeHealthy.WithLabelValues(node.Name).Set(1)
+	}
+	return pool, nil
+}
+
+// Return the node pool of the current stage, the health checks are started on the first call.
+// Panic if the nodes of the stage are not configured, like the unsupported stage.
+func GetNodePool() *NodePool {
+	defaultNodePoolOnce.Do(func() {
+		rpcConfig := config.GetConfigDefault().Blockchain.Coreum.PRCConfig
+		pool, err := NewNodePool(rpcConfig.Nodes, rpcConfig.MaxNodeBlockLag, rpcConfig.NodeHealthCheckInterval)
+		if err != nil {
+			panic(errors.Wrap(err, "NewNodePool"))
+		}
+		defaultNodePool = pool
+		defaultNodePool.Start()
+	})
+	return defaultNodePool
+}
+
+// Start probing the nodes in the background
+func (p *NodePool) Start() {
+	p.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(p.healthCheckInterval)
+			defer ticker.Stop()
+			for {
+				p.CheckHealth(context.Background())
+				select {
+				case <-p.stop:
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+// Probe all the nodes with their latest block status.
+// A node is healthy if it responds, is not catching up and is at most maxBlockLag blocks behind the highest node.
+func (p *NodePool) CheckHealth(ctx context.Context) {
+	type probeResult struct {
+		blockStatus *coreumservicemsg.BlockStatus
+		err         error
+	}
+	results := make([]probeResult, len(p.nodes))
+
+	wg := sync.WaitGroup{}
+	for i, node := range p.nodes {
+		wg.Add(1)
+		go func(i int, node *poolNode) {
+			defer wg.Done()
+			requestCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			rpcClient, err := node.connections.TendermintRPCClient()
+			if err != nil {
+				results[i] = probeResult{err: err}
+				return
+			}
+			blockStatus, err := GetLatestBlockStatusFromClient(requestCtx, rpcClient)
+			results[i] = probeResult{blockStatus: blockStatus, err: err}
+		}(i, node)
+	}
+	wg.Wait()
+
+	highestHeight := int64(0)
+	for _, result := range results {
+		if result.err == nil && !result.blockStatus.CatchingUp && result.blockStatus.LatestBlockHeight > highestHeight {
+			highestHeight = result.blockStatus.LatestBlockHeight
+		}
+	}
+
+	for i, node := range p.nodes {
+		result := results[i]
+		switch {
+		case result.err != nil:
+			node.setHealth(false, 0, errors.Errorf("GetLatestBlockStatusFromClient: %v", result.err))
+		case result.blockStatus.CatchingUp:
+			node.setHealth(false, result.blockStatus.LatestBlockHeight, errors.New("node is catching up"))
+		case highestHeight-result.blockStatus.LatestBlockHeight > p.maxBlockLag:
+			node.setHealth(false, result.blockStatus.LatestBlockHeight, errors.Errorf(
+				"node is %d blocks behind the highest node", highestHeight-result.blockStatus.LatestBlockHeight,
+			))
+		default:
+			node.setHealth(true, result.blockStatus.LatestBlockHeight, nil)
+		}
+	}
+}
+
+// Return the nodes to try in order: the healthy ones first, the highest first.
+// If all the nodes are ejected, all of them are returned as the last resort.
+func (p *NodePool) candidates() []*poolNode {
+	healthyNodes := []*poolNode{}
+	for _, node := range p.nodes {
+		if node.isHealthy() {
+			healthyNodes = append(healthyNodes, node)
+		}
+	}
+	if len(healthyNodes) == 0 {
+		healthyNodes = append(healthyNodes, p.nodes...)
+	}
+	sort.SliceStable(healthyNodes, func(i, j int) bool {
+		return healthyNodes[i].getLatestHeight() > healthyNodes[j].getLatestHeight()
+	})
+	return healthyNodes
+}
+
+// Return the gRPC client that fails over to the next node if the current one is unavailable
+func (p *NodePool) GRPCClient() protobufgrpc.ClientConn {
+	return &failoverGRPCClient{pool: p}
+}
+
+// Return the Tendermint RPC client that fails over to the next node if the current one is unreachable
+func (p *NodePool) TendermintRPCClient() TendermintRPCClient {
+	return &failoverTendermintRPCClient{pool: p}
+}
+
+// Return the health of every node, in the configured order
+func (p *NodePool) GetNodeStatuses() []*coreumservicemsg.NodeStatus {
+	statuses := []*coreumservicemsg.NodeStatus{}
+	for _, node := range p.nodes {
+		node.mu.RLock()
+		nodeStatus := &coreumservicemsg.NodeStatus{
+			Name:         node.name,
+			Healthy:      node.healthy,
+			LatestHeight: node.latestHeight,
+		}
+		if node.lastError != nil {
+			nodeStatus.LastError = node.lastError.Error()
+		}
+		node.mu.RUnlock()
+		statuses = append(statuses, nodeStatus)
+	}
+	return statuses
+}
+
+// Stop the health checks and release the connections of all the nodes
+func (p *NodePool) Close() error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	var lastErr error
+	for _, node := range p.nodes {
+		if err := node.connections.Close(); err != nil {
+			lastErr = errors.Errorf("node %v: %v", node.name, err)
+		}
+	}
+	return lastErr
+}
+
+func (n *poolNode) setHealth(healthy bool, latestHeight int64, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.healthy != healthy {
+		fmt.Printf("[node pool] Node %v healthy changed to %v: %v\n", n.name, healthy, err)
+	}
+	n.healthy = healthy
+	if latestHeight > 0 {
+		n.latestHeight = latestHeight
+	}
+	n.lastError = err
+
+	healthyValue := 0.0
+	if healthy {
+		healthyValue = 1
+	}
+	nodeHealthy.WithLabelValues(n.name).Set(healthyValue)
+	nodeLatestBlockHeight.WithLabelValues(n.name).Set(float64(n.latestHeight))
+}
+
+func (n *poolNode) isHealthy() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.healthy
+}
+
+func (n *poolNode) getLatestHeight() int64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.latestHeight
+}
+
+// Implement the gRPC client connection interface used by client.Context
+type failoverGRPCClient struct {
+	pool *NodePool
+}
+
+// Send the request to the best node, and retry on the next node if the node is unavailable.
+// The broadcast is not retried: the unavailable node may have received the transaction already,
+// and the next node would reject the same transaction as already in the mempool cache.
+func (c *failoverGRPCClient) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	lastErr := errNoNodeAvailable()
+	for _, node := range c.pool.candidates() {
+		grpcConn, err := node.connections.GRPCConn()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		err = grpcConn.Invoke(ctx, method, args, reply, opts...)
+		if err != nil && isNodeFailure(ctx, err) {
+			nodeRequestsTotal.WithLabelValues(node.name, protocolGRPC, method, "failure").Inc()
+			fmt.Printf("[node pool] Node %v failed on %v, failing over: %v\n", node.name, method, err)
+			// Eject the node until the next health check
+			node.setHealth(false, 0, err)
+			if method == broadcastTxMethod {
+				return err
+			}
+			lastErr = err
+			continue
+		}
+
+		// The node served the request, the error (if any) is the application error
+		nodeRequestsTotal.WithLabelValues(node.name, protocolGRPC, method, "success").Inc()
+		return err
+	}
+	return lastErr
+}
+
+// The streams are opened on the best node, the opened streams are not retried
+func (c *failoverGRPCClient) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	lastErr := errNoNodeAvailable()
+	for _, node := range c.pool.candidates() {
+		grpcConn, err := node.connections.GRPCConn()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		stream, err := grpcConn.NewStream(ctx, desc, method, opts...)
+		if err != nil && isNodeFailure(ctx, err) {
+			nodeRequestsTotal.WithLabelValues(node.name, protocolGRPC, method, "failure").Inc()
+			fmt.Printf("[node pool] Node %v failed to open %v, failing over: %v\n", node.name, method, err)
+			node.setHealth(false, 0, err)
+			lastErr = err
+			continue
+		}
+
+		nodeRequestsTotal.WithLabelValues(node.name, protocolGRPC, method, "success").Inc()
+		return stream, err
+	}
+	return nil, lastErr
+}
+
+// The error of the request no node could be tried for
+func errNoNodeAvailable() error {
+	return NewServiceError(coreumservicemsg.ErrorCodeChainUnavailable, nil, "no node available")
+}
+
+// Whether the error is caused by the node instead of the request
+func isNodeFailure(ctx context.Context, err error) bool {
+	// The caller gave up, it's not the node's fault
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// The Tendermint RPC methods used by the service
+type TendermintRPCClient interface {
+	rpcclient.StatusClient
+	BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error)
+	ConsensusParams(ctx context.Context, height *int64) (*coretypes.ResultConsensusParams, error)
+	UnconfirmedTxs(ctx context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error)
+}
+
+// Implement the Tendermint RPC client, the same way as failoverGRPCClient
+type failoverTendermintRPCClient struct {
+	pool *NodePool
+}
+
+func (c *failoverTendermintRPCClient) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	return invokeTendermintRPC(ctx, c.pool, "status", func(rpcClient *tmhttp.HTTP) (*coretypes.ResultStatus, error) {
+		return rpcClient.Status(ctx)
+	})
+}
+
+func (c *failoverTendermintRPCClient) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	return invokeTendermintRPC(ctx, c.pool, "block_results", func(rpcClient *tmhttp.HTTP) (*coretypes.ResultBlockResults, error) {
+		return rpcClient.BlockResults(ctx, height)
+	})
+}
+
+func (c *failoverTendermintRPCClient) ConsensusParams(ctx context.Context, height *int64) (*coretypes.ResultConsensusParams, error) {
+	return invokeTendermintRPC(ctx, c.pool, "consensus_params", func(rpcClient *tmhttp.HTTP) (*coretypes.ResultConsensusParams, error) {
+		return rpcClient.ConsensusParams(ctx, height)
+	})
+}
+
+func (c *failoverTendermintRPCClient) UnconfirmedTxs(ctx context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error) {
+	return invokeTendermintRPC(ctx, c.pool, "unconfirmed_txs", func(rpcClient *tmhttp.HTTP) (*coretypes.ResultUnconfirmedTxs, error) {
+		return rpcClient.UnconfirmedTxs(ctx, limit)
+	})
+}
+
+// Send the request to the best node, and retry on the next node if the node is unreachable.
+// The Tendermint RPC methods used by the service are all queries, they're safe to re-send.
+func invokeTendermintRPC[R any](ctx context.Context,
+	pool *NodePool,
+	method string,
+	call func(rpcClient *tmhttp.HTTP) (R, error),
+) (R, error) {
+	var empty R
+	lastErr := errNoNodeAvailable()
+	for _, node := range pool.candidates() {
+		rpcClient, err := node.connections.TendermintRPCClient()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		result, err := call(rpcClient)
+		if err != nil && isRPCNodeFailure(ctx, err) {
+			nodeRequestsTotal.WithLabelValues(node.name, protocolTendermintRPC, method, "failure").Inc()
+			fmt.Printf("[node pool] Node %v failed on %v, failing over: %v\n", node.name, method, err)
+			// Eject the node until the next health check
+			node.setHealth(false, 0, err)
+			lastErr = err
+			continue
+		}
+
+		// The node served the request, the error (if any) is the RPC error
+		nodeRequestsTotal.WithLabelValues(node.name, protocolTendermintRPC, method, "success").Inc()
+		return result, err
+	}
+	return empty, lastErr
+}
+
+// Whether the Tendermint RPC error is caused by the node instead of the request, i.e. the node is not reachable
+func isRPCNodeFailure(ctx context.Context, err error) bool {
+	// The caller gave up, it's not the node's fault
+	if ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
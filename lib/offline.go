@@ -0,0 +1,336 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+	"coreumservicemsg"
+	"encoding/base64"
+	"strings"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+)
+
+// The params of the transfer built without signing it
+type UnsignedTransfer struct {
+	SenderAddress  string
+	AccountNumber  uint64
+	SequenceNumber uint64
+	GasPrice       string
+	GasUsed        uint64
+	Tx             cosmossdk.Tx
+}
+
+// Build the transfer without signing it, so it can be reviewed, stored and signed in a separate step.
+// The gas is estimated if gasPrice or gasUsed is not set.
+func BuildUnsignedTransfer(ctx context.Context,
+	senderAddress string,
+	recipientAddress string,
+	assetDenom string,
+	toAmount cosmossdk.Int,
+	memo string,
+	sequenceNumber uint64,
+	gasPrice string,
+	gasUsed uint64,
+) (*UnsignedTransfer, error) {
+	acc, err := GetAccountInfo(ctx, senderAddress)
+	if err != nil {
+		return nil, errors.Errorf("GetAccountInfo: %v", err)
+	}
+
+	// No key is needed to build the transaction
+	clientCtx, txFactory, msg, err := PrepareTransferTransaction(ctx,
+		keyring.NewInMemory(),
+		senderAddress,
+		recipientAddress,
+		assetDenom,
+		toAmount,
+		memo,
+		sequenceNumber,
+		gasPrice,
+		gasUsed,
+	)
+	if err != nil {
+		return nil, errors.Errorf("PrepareTransferTransaction: %v", err)
+	}
+
+	if gasPrice == "" || gasUsed == 0 {
+		// The simulation expects the committed sequence
+		gasUsed, gasPrice, err = CalculateGas(ctx, clientCtx, txFactory.WithSequence(acc.Sequence), msg)
+		if err != nil {
+			return nil, errors.Wrap(err, "CalculateGas")
+		}
+		txFactory = txFactory.
+			WithGasPrices(gasPrice).
+			WithGas(gasUsed)
+	}
+
+	if err := checkFactoryFeeCeiling(txFactory); err != nil {
+		return nil, err
+	}
+
+	unsignedTx, err := txFactory.BuildUnsignedTx(msg)
+	if err != nil {
+		return nil, errors.Errorf("txFactory.BuildUnsignedTx: %v", err)
+	}
+	unsignedTx.SetFeeGranter(clientCtx.FeeGranterAddress())
+
+	return &UnsignedTransfer{
+		SenderAddress:  senderAddress,
+		AccountNumber:  acc.AccountNumber,
+		SequenceNumber: sequenceNumber,
+		GasPrice:       gasPrice,
+		GasUsed:        gasUsed,
+		Tx:             unsignedTx.GetTx(),
+	}, nil
+}
+
+// Encode the transaction to the base64 protobuf bytes or the JSON
+func EncodeTx(transaction cosmossdk.Tx, encoding coreumservicemsg.TxEncoding) (string, error) {
+	txConfig := GetAppEncodingConfig().TxConfig
+	switch encoding {
+	case "", coreumservicemsg.TxEncodingProto:
+		txBytes, err := txConfig.TxEncoder()(transaction)
+		if err != nil {
+			return "", errors.Errorf("txConfig.TxEncoder: %v", err)
+		}
+		return base64.StdEncoding.EncodeToString(txBytes), nil
+	case coreumservicemsg.TxEncodingJSON:
+		txJSON, err := txConfig.TxJSONEncoder()(transaction)
+		if err != nil {
+			return "", errors.Errorf("txConfig.TxJSONEncoder: %v", err)
+		}
+		return string(txJSON), nil
+	default:
+		return "", checkTxEncoding(encoding)
+	}
+}
+
+// Check the encoding before any work is done for the transaction, e.g. reserving its sequence
+func checkTxEncoding(encoding coreumservicemsg.TxEncoding) error {
+	switch encoding {
+	case "", coreumservicemsg.TxEncodingProto, coreumservicemsg.TxEncodingJSON:
+		return nil
+	default:
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "unknown encoding %q", encoding)
+	}
+}
+
+// Decode the transaction encoded by EncodeTx
+func DecodeEncodedTx(encodedTx string, encoding coreumservicemsg.TxEncoding) (cosmossdk.Tx, error) {
+	txConfig := GetAppEncodingConfig().TxConfig
+	switch encoding {
+	case "", coreumservicemsg.TxEncodingProto:
+		txBytes, err := base64.StdEncoding.DecodeString(encodedTx)
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid base64 transaction: %v", err)
+		}
+		transaction, err := txConfig.TxDecoder()(txBytes)
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid transaction bytes: %v", err)
+		}
+		return transaction, nil
+	case coreumservicemsg.TxEncodingJSON:
+		transaction, err := txConfig.TxJSONDecoder()([]byte(encodedTx))
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid transaction JSON: %v", err)
+		}
+		return transaction, nil
+	default:
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "unknown encoding %q", encoding)
+	}
+}
+
+// Sign the transaction with the mnemonic, the signatures are replaced.
+// Return the protobuf bytes of the signed transaction.
+func SignTxWithMnemonic(ctx context.Context,
+	signerMnemonic string,
+	transaction cosmossdk.Tx,
+	accountNumber *uint64,
+	sequenceNumber uint64,
+) ([]byte, error) {
+	signer, err := NewMnemonicSigner(signerMnemonic)
+	if err != nil {
+		return nil, errors.Errorf("NewMnemonicSigner: %v", err)
+	}
+	return SignTxWithSigner(ctx, signer, transaction, accountNumber, sequenceNumber)
+}
+
+// Return the signer of the secret for the sign-transaction endpoint.
+// Only the keys of the offline signing config sign the transactions built outside the service,
+// and the treasury key doesn't once its transfers are sent by the operator.
+func GetOfflineSigner(ctx context.Context, secretID string) (Signer, error) {
+	coreumConfig := config.GetConfigDefault().Blockchain.Coreum
+	if err := checkOfflineSigningSecretID(secretID, coreumConfig.OfflineSigning.SecretIDs, coreumConfig.USDS); err != nil {
+		return nil, err
+	}
+	return GetSigner(ctx, secretID)
+}
+
+func checkOfflineSigningSecretID(secretID string, allowedSecretIDs []string, assetConfig coreumconfig.CoreumAssetConfig) error {
+	details := map[string]string{"secret_id": secretID}
+	if assetConfig.OperatorSecretID != "" && secretID == assetConfig.TreasurySecretID {
+		return NewServiceError(coreumservicemsg.ErrorCodeForbidden, details,
+			"the treasury key doesn't sign the transactions built outside the service, its transfers are sent by the operator")
+	}
+	for _, allowedSecretID := range allowedSecretIDs {
+		if allowedSecretID == secretID {
+			return nil
+		}
+	}
+	return NewServiceError(coreumservicemsg.ErrorCodeForbidden, details,
+		"the key of secret ID %v doesn't sign the transactions built outside the service", secretID)
+}
+
+// Same as SignTxWithMnemonic, the transaction is signed by the signer.
+// The messages must be of the allowed types of the offline signing config and valid, and the fee below the fee ceiling.
+// The account number is fetched from the chain if it's nil.
+func SignTxWithSigner(ctx context.Context,
+	signer Signer,
+	transaction cosmossdk.Tx,
+	accountNumber *uint64,
+	sequenceNumber uint64,
+) ([]byte, error) {
+	signerAddress := signer.GetAddress()
+
+	coreumConfig := config.GetConfigDefault().Blockchain.Coreum
+	if err := checkMessageTypesAllowed(transaction.GetMsgs(), coreumConfig.OfflineSigning.AllowedMessageTypes); err != nil {
+		return nil, err
+	}
+	// GetSigners panics on the invalid addresses
+	for _, msg := range transaction.GetMsgs() {
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid message: %v", err)
+		}
+	}
+	feeTx, ok := transaction.(cosmossdk.FeeTx)
+	if !ok {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "the transaction has no fee")
+	}
+	if err := checkFeeAmountCeiling(feeTx.GetFee(), coreumConfig.Gas.MaxFeePerTx); err != nil {
+		return nil, err
+	}
+
+	isSigner := false
+	for _, msg := range transaction.GetMsgs() {
+		for _, msgSigner := range msg.GetSigners() {
+			isSigner = isSigner || msgSigner.Equals(signerAddress)
+		}
+	}
+	if !isSigner {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"%v is not a signer of the transaction", signerAddress.String())
+	}
+
+	// 0 is the account number of the first genesis account, only the missing one is fetched
+	if accountNumber == nil {
+		acc, err := GetAccountInfo(ctx, signerAddress.String())
+		if err != nil {
+			return nil, errors.Errorf("GetAccountInfo: %v", err)
+		}
+		accountNumber = &acc.AccountNumber
+	}
+
+	txConfig := GetAppEncodingConfig().TxConfig
+	txBuilder, err := txConfig.WrapTxBuilder(transaction)
+	if err != nil {
+		return nil, errors.Errorf("txConfig.WrapTxBuilder: %v", err)
+	}
+
+	txFactory := client.Factory{}.
+		WithChainID(GetChainIDByStage()).
+		WithTxConfig(txConfig).
+		WithAccountNumber(*accountNumber).
+		WithSequence(sequenceNumber)
+	err = signTxWithSigner(ctx, txConfig, txFactory, signer, txBuilder)
+	if err != nil {
+		return nil, errors.Wrap(err, "signTxWithSigner")
+	}
+
+	signedTxBytes, err := txConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, errors.Errorf("txConfig.TxEncoder: %v", err)
+	}
+	return signedTxBytes, nil
+}
+
+// Broadcast the signed transaction bytes after checking their hash.
+// Broadcasting the same bytes again is safe: the transaction already in a block is not re-broadcast,
+// and the transaction already in the mempool is reported as submitted.
+func BroadcastSignedTx(ctx context.Context, signedTxBytes []byte, expectedTxHash string) (*coreumservicemsg.BroadcastSignedTransactionReply, error) {
+	_, txHash := CalculateHashOfTransaction(signedTxBytes)
+	if expectedTxHash != "" && !strings.EqualFold(txHash, expectedTxHash) {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, map[string]string{"tx_hash": txHash},
+			"the hash of the transaction %v is not the expected hash %v", txHash, expectedTxHash)
+	}
+	return broadcastSignedTxBytes(ctx, signedTxBytes, txHash, nil)
+}
+
+// Broadcast the signed transaction unless it's already in a block.
+// The verification, if any, is run only before the actual broadcast.
+func broadcastSignedTxBytes(ctx context.Context,
+	signedTxBytes []byte,
+	txHash string,
+	verify func(ctx context.Context, signedTxBytes []byte) error,
+) (*coreumservicemsg.BroadcastSignedTransactionReply, error) {
+	if txHash == "" {
+		_, txHash = CalculateHashOfTransaction(signedTxBytes)
+	}
+
+	clientCtx := GetClientContext()
+	transactionDetail, err := GetTransactionByHash(ctx, clientCtx, txHash)
+	if err != nil {
+		return nil, errors.Errorf("GetTransactionByHash: %v", err)
+	}
+	switch transactionDetail.Status {
+	case coreumservicemsg.TransactionStatusSuccess:
+		return &coreumservicemsg.BroadcastSignedTransactionReply{
+			TxHash: txHash,
+			State:  coreumservicemsg.TransactionConfirmationStateInBlock,
+		}, nil
+	case coreumservicemsg.TransactionStatusFailed:
+		return &coreumservicemsg.BroadcastSignedTransactionReply{
+			TxHash: txHash,
+			State:  coreumservicemsg.TransactionConfirmationStateFailed,
+		}, nil
+	}
+
+	if verify != nil {
+		if err := verify(ctx, signedTxBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = client.BroadcastRawTx(ctx, clientCtx, signedTxBytes)
+	if err != nil && !isTxInMempoolCacheError(err) {
+		return nil, errors.Errorf("client.BroadcastRawTx: %v", err)
+	}
+	recordSubmittedTransaction(txHash)
+	trackSignedTxSequence(signedTxBytes, txHash)
+
+	return &coreumservicemsg.BroadcastSignedTransactionReply{
+		TxHash: txHash,
+		State:  coreumservicemsg.TransactionConfirmationStateSubmitted,
+	}, nil
+}
+
+// Let the sequence manager know the sequence of the first signer is in flight
+func trackSignedTxSequence(signedTxBytes []byte, txHash string) {
+	decodedTx, err := DecodeTx(signedTxBytes)
+	if err != nil || decodedTx.AuthInfo == nil || len(decodedTx.AuthInfo.SignerInfos) == 0 {
+		return
+	}
+	transactionDetail, err := toTransactionDetail(decodedTx)
+	if err != nil || len(transactionDetail.Signers) == 0 {
+		return
+	}
+	GetSequenceManager().Track(transactionDetail.Signers[0], decodedTx.AuthInfo.SignerInfos[0].Sequence, txHash)
+}
+
+func isTxInMempoolCacheError(err error) bool {
+	return errors.Is(err, sdkerrors.ErrTxInMempoolCache) || strings.Contains(err.Error(), sdkerrors.ErrTxInMempoolCache.Error())
+}
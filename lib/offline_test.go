@@ -0,0 +1,151 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+	"coreumservicemsg"
+	"testing"
+
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+const offlineTestMnemonic = "nut clog audit reward display era divide galaxy boil sport bless disorder total hidden pair range senior risk disorder affair dress barrel nuclear exhibit"
+const offlineTestOtherMnemonic = "hazard misery record advice ceiling clean manage ten approve render abstract horse door federal congress stadium job tribe begin shaft digital aerobic upset record"
+
+// The account number the transactions are signed for in the tests, so the chain is not queried
+func offlineTestAccountNumber() *uint64 {
+	accountNumber := uint64(12)
+	return &accountNumber
+}
+
+func newTestUnsignedTransfer(t *testing.T, fromAddress string) cosmossdk.Tx {
+	txBuilder := GetAppEncodingConfig().TxConfig.NewTxBuilder()
+	err := txBuilder.SetMsgs(&banktypes.MsgSend{
+		FromAddress: fromAddress,
+		ToAddress:   fromAddress,
+		Amount:      cosmossdk.NewCoins(cosmossdk.NewCoin("utestcore", cosmossdk.NewInt(100))),
+	})
+	require.NoError(t, err)
+	txBuilder.SetGasLimit(100000)
+	txBuilder.SetFeeAmount(cosmossdk.NewCoins(cosmossdk.NewCoin("utestcore", cosmossdk.NewInt(6250))))
+	txBuilder.SetMemo("offline")
+	return txBuilder.GetTx()
+}
+
+func TestEncodeTx(t *testing.T) {
+	unsignedTx := newTestUnsignedTransfer(t, mustAddressFromMnemonic(t, offlineTestMnemonic))
+
+	for _, encoding := range []coreumservicemsg.TxEncoding{"", coreumservicemsg.TxEncodingProto, coreumservicemsg.TxEncodingJSON} {
+		encodedTx, err := EncodeTx(unsignedTx, encoding)
+		require.NoError(t, err)
+
+		decodedTx, err := DecodeEncodedTx(encodedTx, encoding)
+		require.NoError(t, err)
+		require.Equal(t, unsignedTx.GetMsgs(), decodedTx.GetMsgs())
+		require.NoError(t, checkTxEncoding(encoding))
+	}
+
+	_, err := EncodeTx(unsignedTx, "xml")
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(checkTxEncoding("xml")).ErrorCode)
+
+	_, err = DecodeEncodedTx("not base64!", coreumservicemsg.TxEncodingProto)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+}
+
+func TestSignTxWithMnemonic(t *testing.T) {
+	ctx := context.Background()
+	unsignedTx := newTestUnsignedTransfer(t, mustAddressFromMnemonic(t, offlineTestMnemonic))
+
+	// The account number is given, so the chain is not queried
+	signedTxBytes, err := SignTxWithMnemonic(ctx, offlineTestMnemonic, unsignedTx, offlineTestAccountNumber(), 7)
+	require.NoError(t, err)
+
+	signedTx, err := DecodeTx(signedTxBytes)
+	require.NoError(t, err)
+	require.Len(t, signedTx.Signatures, 1)
+	require.NotEmpty(t, signedTx.Signatures[0])
+	require.Len(t, signedTx.AuthInfo.SignerInfos, 1)
+	require.Equal(t, uint64(7), signedTx.AuthInfo.SignerInfos[0].Sequence)
+	require.Equal(t, "offline", signedTx.Body.Memo)
+
+	// The signing is deterministic
+	signedTxBytesAgain, err := SignTxWithMnemonic(ctx, offlineTestMnemonic, unsignedTx, offlineTestAccountNumber(), 7)
+	require.NoError(t, err)
+	require.Equal(t, signedTxBytes, signedTxBytesAgain)
+
+	// Only the signer of the messages can sign
+	otherMnemonic := "hazard misery record advice ceiling clean manage ten approve render abstract horse door federal congress stadium job tribe begin shaft digital aerobic upset record"
+	_, err = SignTxWithMnemonic(ctx, otherMnemonic, unsignedTx, offlineTestAccountNumber(), 7)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+}
+
+func TestSignTxWithMnemonicChecks(t *testing.T) {
+	ctx := context.Background()
+	signerAddress := mustAddressFromMnemonic(t, offlineTestMnemonic)
+
+	// The fee above the fee ceiling of the stage is refused
+	txBuilder, err := GetAppEncodingConfig().TxConfig.WrapTxBuilder(newTestUnsignedTransfer(t, signerAddress))
+	require.NoError(t, err)
+	txBuilder.SetFeeAmount(cosmossdk.NewCoins(cosmossdk.NewCoin("utestcore", cosmossdk.NewInt(coreumconfig.TestnetMaxFeePerTx+1))))
+	_, err = SignTxWithMnemonic(ctx, offlineTestMnemonic, txBuilder.GetTx(), offlineTestAccountNumber(), 7)
+	require.Equal(t, coreumservicemsg.ErrorCodeFeeCeilingExceeded, ToErrorReply(err).ErrorCode)
+
+	// The message types not allowed by the offline signing config are refused
+	txBuilder = GetAppEncodingConfig().TxConfig.NewTxBuilder()
+	msgRevoke := authz.NewMsgRevoke(cosmossdk.MustAccAddressFromBech32(signerAddress),
+		cosmossdk.MustAccAddressFromBech32(signerAddress), cosmossdk.MsgTypeURL(&banktypes.MsgSend{}))
+	require.NoError(t, txBuilder.SetMsgs(&msgRevoke))
+	_, err = SignTxWithMnemonic(ctx, offlineTestMnemonic, txBuilder.GetTx(), offlineTestAccountNumber(), 7)
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeUnprocessable, errorReply.ErrorCode)
+	require.Equal(t, "/cosmos.authz.v1beta1.MsgRevoke", errorReply.Details["message_type"])
+
+	// The message with the malformed address is refused before its signers are read
+	_, err = SignTxWithMnemonic(ctx, offlineTestMnemonic, newTestUnsignedTransfer(t, "testcore1malformed"), offlineTestAccountNumber(), 7)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+}
+
+func TestCheckOfflineSigningSecretID(t *testing.T) {
+	assetConfig := coreumconfig.CoreumAssetConfig{TreasurySecretID: "treasury"}
+	allowedSecretIDs := []string{"treasury", "cold"}
+
+	require.NoError(t, checkOfflineSigningSecretID("treasury", allowedSecretIDs, assetConfig))
+	require.NoError(t, checkOfflineSigningSecretID("cold", allowedSecretIDs, assetConfig))
+
+	// The keys not in the offline signing config are refused
+	err := checkOfflineSigningSecretID("hot", allowedSecretIDs, assetConfig)
+	require.Equal(t, coreumservicemsg.ErrorCodeForbidden, ToErrorReply(err).ErrorCode)
+	err = checkOfflineSigningSecretID("cold", nil, assetConfig)
+	require.Equal(t, coreumservicemsg.ErrorCodeForbidden, ToErrorReply(err).ErrorCode)
+
+	// The treasury key is refused once its transfers are sent by the operator
+	assetConfig.OperatorSecretID = "operator"
+	err = checkOfflineSigningSecretID("treasury", allowedSecretIDs, assetConfig)
+	require.Equal(t, coreumservicemsg.ErrorCodeForbidden, ToErrorReply(err).ErrorCode)
+	require.NoError(t, checkOfflineSigningSecretID("cold", allowedSecretIDs, assetConfig))
+}
+
+func TestBroadcastSignedTxHashMismatch(t *testing.T) {
+	ctx := context.Background()
+	signedTxBytes, err := SignTxWithMnemonic(ctx, offlineTestMnemonic, newTestUnsignedTransfer(t, mustAddressFromMnemonic(t, offlineTestMnemonic)), offlineTestAccountNumber(), 7)
+	require.NoError(t, err)
+
+	_, txHash := CalculateHashOfTransaction(signedTxBytes)
+	_, err = BroadcastSignedTx(ctx, signedTxBytes, "ABCDEF")
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, errorReply.ErrorCode)
+	require.Equal(t, txHash, errorReply.Details["tx_hash"])
+}
+
+func mustAddressFromMnemonic(t *testing.T, mnemonic string) string {
+	keyringInfo, _, err := GetKeyringInfoFromMnemonic(mnemonic)
+	require.NoError(t, err)
+	return keyringInfo.GetAddress().String()
+}
@@ -0,0 +1,155 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	"coreumservicemsg"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/pkg/errors"
+)
+
+// Fetch the account number and the committed sequence of the address
+type accountInfoFetcher func(ctx context.Context, address string) (*coreumservicemsg.GetAccountInfoReply, error)
+
+// Decode the tx bytes from base64 (default) or hex
+func DecodeRawTxBytes(txBytes string, encoding coreumservicemsg.RawTxEncoding) ([]byte, error) {
+	switch encoding {
+	case "", coreumservicemsg.RawTxEncodingBase64:
+		decoded, err := base64.StdEncoding.DecodeString(txBytes)
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid base64 transaction: %v", err)
+		}
+		return decoded, nil
+	case coreumservicemsg.RawTxEncodingHex:
+		decoded, err := hex.DecodeString(strings.TrimPrefix(txBytes, "0x"))
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid hex transaction: %v", err)
+		}
+		return decoded, nil
+	default:
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "unknown encoding %q", encoding)
+	}
+}
+
+// Broadcast the transaction signed outside the service after verifying it against the chain of the stage.
+// The transaction already included in a block is not verified nor re-broadcast, its state is returned.
+func BroadcastRawTransaction(ctx context.Context, txBytes []byte) (*coreumservicemsg.BroadcastRawTransactionReply, error) {
+	broadcastResult, err := broadcastSignedTxBytes(ctx, txBytes, "", func(ctx context.Context, txBytes []byte) error {
+		return VerifyRawTx(ctx,
+			txBytes,
+			GetChainIDByStage(),
+			config.GetConfigDefault().Blockchain.Coreum.AllowedRawTxMessageTypes,
+			GetAccountInfo,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &coreumservicemsg.BroadcastRawTransactionReply{
+		TxHash: broadcastResult.TxHash,
+		State:  broadcastResult.State,
+	}, nil
+}
+
+// Verify the signed transaction before broadcasting it:
+// the messages are valid and of the allowed types (all the types if allowedMessageTypes is empty),
+// every signer signed with its account number and the chain ID, and the sequence is not already used.
+func VerifyRawTx(ctx context.Context,
+	txBytes []byte,
+	chainID string,
+	allowedMessageTypes []string,
+	fetchAccountInfo accountInfoFetcher,
+) error {
+	txConfig := GetAppEncodingConfig().TxConfig
+	transaction, err := txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid transaction bytes: %v", err)
+	}
+	if err := transaction.ValidateBasic(); err != nil {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid transaction: %v", err)
+	}
+	if err := checkMessageTypesAllowed(transaction.GetMsgs(), allowedMessageTypes); err != nil {
+		return err
+	}
+
+	sigTx, ok := transaction.(authsigning.SigVerifiableTx)
+	if !ok {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "the transaction has no signature")
+	}
+	signatures, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid signatures: %v", err)
+	}
+	signers := sigTx.GetSigners()
+	if len(signatures) != len(signers) {
+		return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"the transaction has %d signatures for %d signers", len(signatures), len(signers))
+	}
+
+	for i, signature := range signatures {
+		signer := signers[i].String()
+		details := map[string]string{"signer": signer}
+		if signature.PubKey == nil {
+			return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "the public key of %v is missing", signer)
+		}
+		if !signers[i].Equals(cosmossdk.AccAddress(signature.PubKey.Address())) {
+			return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "the public key doesn't belong to %v", signer)
+		}
+
+		acc, err := fetchAccountInfo(ctx, signer)
+		if err != nil {
+			return errors.Errorf("fetchAccountInfo(%v): %v", signer, err)
+		}
+		if signature.Sequence < acc.Sequence {
+			return NewServiceError(coreumservicemsg.ErrorCodeConflict, details,
+				"the sequence %d of %v is already used, the account sequence is %d", signature.Sequence, signer, acc.Sequence)
+		}
+
+		signerData := authsigning.SignerData{
+			ChainID:       chainID,
+			AccountNumber: acc.AccountNumber,
+			Sequence:      signature.Sequence,
+		}
+		err = authsigning.VerifySignature(signature.PubKey, signerData, signature.Data, txConfig.SignModeHandler(), transaction)
+		if err != nil {
+			return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details,
+				"the signature of %v is not valid for the chain %v and the account number %d: %v", signer, chainID, acc.AccountNumber, err)
+		}
+	}
+	return nil
+}
+
+// Check the messages, including the messages executed on behalf of the granter, against the allowlist
+func checkMessageTypesAllowed(msgs []cosmossdk.Msg, allowedMessageTypes []string) error {
+	if len(allowedMessageTypes) == 0 {
+		return nil
+	}
+	for _, msg := range msgs {
+		msgType := cosmossdk.MsgTypeURL(msg)
+		allowed := false
+		for _, allowedMessageType := range allowedMessageTypes {
+			allowed = allowed || allowedMessageType == msgType
+		}
+		if !allowed {
+			return NewServiceError(coreumservicemsg.ErrorCodeUnprocessable, map[string]string{"message_type": msgType},
+				"the message type %v is not allowed", msgType)
+		}
+
+		if msgExec, ok := msg.(*authz.MsgExec); ok {
+			innerMsgs, err := msgExec.GetMessages()
+			if err != nil {
+				return NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid authz messages: %v", err)
+			}
+			if err := checkMessageTypesAllowed(innerMsgs, allowedMessageTypes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRawTxBytes(t *testing.T) {
+	txBytes := []byte{0x0a, 0x01, 0xff}
+
+	decoded, err := DecodeRawTxBytes(base64.StdEncoding.EncodeToString(txBytes), "")
+	require.NoError(t, err)
+	require.Equal(t, txBytes, decoded)
+
+	decoded, err = DecodeRawTxBytes(hex.EncodeToString(txBytes), coreumservicemsg.RawTxEncodingHex)
+	require.NoError(t, err)
+	require.Equal(t, txBytes, decoded)
+
+	decoded, err = DecodeRawTxBytes("0x0A01FF", coreumservicemsg.RawTxEncodingHex)
+	require.NoError(t, err)
+	require.Equal(t, txBytes, decoded)
+
+	_, err = DecodeRawTxBytes("zz", coreumservicemsg.RawTxEncodingHex)
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+
+	_, err = DecodeRawTxBytes("AA==", "base32")
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+}
+
+func TestVerifyRawTx(t *testing.T) {
+	ctx := context.Background()
+	chainID := GetChainIDByStage()
+	signerAddress := mustAddressFromMnemonic(t, offlineTestMnemonic)
+
+	// Signed with the account number 12 and the sequence 7
+	signedTxBytes, err := SignTxWithMnemonic(ctx, offlineTestMnemonic, newTestUnsignedTransfer(t, signerAddress), offlineTestAccountNumber(), 7)
+	require.NoError(t, err)
+
+	accountInfo := func(accountNumber, sequence uint64) accountInfoFetcher {
+		return func(ctx context.Context, address string) (*coreumservicemsg.GetAccountInfoReply, error) {
+			require.Equal(t, signerAddress, address)
+			return &coreumservicemsg.GetAccountInfoReply{AccountNumber: accountNumber, Sequence: sequence}, nil
+		}
+	}
+
+	// The account number 0 of the first genesis account is signed as is
+	genesisAccountNumber := uint64(0)
+	genesisSignedTxBytes, err := SignTxWithMnemonic(ctx, offlineTestMnemonic, newTestUnsignedTransfer(t, signerAddress), &genesisAccountNumber, 7)
+	require.NoError(t, err)
+	require.NoError(t, VerifyRawTx(ctx, genesisSignedTxBytes, chainID, nil, accountInfo(0, 7)))
+
+	// The sequence ahead of the chain is valid, the earlier transactions may still be in the mempool
+	require.NoError(t, VerifyRawTx(ctx, signedTxBytes, chainID, nil, accountInfo(12, 7)))
+	require.NoError(t, VerifyRawTx(ctx, signedTxBytes, chainID, nil, accountInfo(12, 5)))
+	require.NoError(t, VerifyRawTx(ctx, signedTxBytes, chainID, []string{cosmossdk.MsgTypeURL(&banktypes.MsgSend{})}, accountInfo(12, 7)))
+
+	testCases := []struct {
+		name                string
+		txBytes             []byte
+		chainID             string
+		allowedMessageTypes []string
+		fetchAccountInfo    accountInfoFetcher
+		expectedErrorCode   coreumservicemsg.ErrorCode
+	}{
+		{
+			name:              "sequence already used",
+			txBytes:           signedTxBytes,
+			chainID:           chainID,
+			fetchAccountInfo:  accountInfo(12, 8),
+			expectedErrorCode: coreumservicemsg.ErrorCodeConflict,
+		},
+		{
+			name:              "wrong account number",
+			txBytes:           signedTxBytes,
+			chainID:           chainID,
+			fetchAccountInfo:  accountInfo(13, 7),
+			expectedErrorCode: coreumservicemsg.ErrorCodeInvalidRequest,
+		},
+		{
+			name:              "wrong chain",
+			txBytes:           signedTxBytes,
+			chainID:           "coreum-other-1",
+			fetchAccountInfo:  accountInfo(12, 7),
+			expectedErrorCode: coreumservicemsg.ErrorCodeInvalidRequest,
+		},
+		{
+			name:                "message type not allowed",
+			txBytes:             signedTxBytes,
+			chainID:             chainID,
+			allowedMessageTypes: []string{cosmossdk.MsgTypeURL(&banktypes.MsgMultiSend{})},
+			fetchAccountInfo:    accountInfo(12, 7),
+			expectedErrorCode:   coreumservicemsg.ErrorCodeUnprocessable,
+		},
+		{
+			name:              "not a transaction",
+			txBytes:           []byte("not a transaction"),
+			chainID:           chainID,
+			fetchAccountInfo:  accountInfo(12, 7),
+			expectedErrorCode: coreumservicemsg.ErrorCodeInvalidRequest,
+		},
+	}
+	for _, testCase := range testCases {
+		err := VerifyRawTx(ctx, testCase.txBytes, testCase.chainID, testCase.allowedMessageTypes, testCase.fetchAccountInfo)
+		require.Error(t, err, testCase.name)
+		require.Equal(t, testCase.expectedErrorCode, ToErrorReply(err).ErrorCode, testCase.name)
+	}
+}
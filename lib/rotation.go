@@ -0,0 +1,101 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	awssecretmanager "coreumservice/go/stably_io/secretmanager/aws"
+)
+
+// The treasury key of the mnemonic of the secret, only the hash of the mnemonic is kept to detect the rotation
+type treasuryKey struct {
+	mnemonicHash [sha256.Size]byte
+	address      string
+}
+
+//nolint:gochecknoglobals
+var (
+	treasuryKeysMu sync.Mutex
+	// The last treasury key by secret ID, to detect the address change after the secret rotation
+	treasuryKeys = map[string]*treasuryKey{}
+)
+
+// Record the treasury key of the mnemonic of the secret, the address change of the rotated mnemonic is logged
+func trackTreasuryKey(secretID string, mnemonic string) *treasuryKey {
+	treasuryKeysMu.Lock()
+	defer treasuryKeysMu.Unlock()
+
+	mnemonicHash := sha256.Sum256([]byte(mnemonic))
+	lastKey := treasuryKeys[secretID]
+	if lastKey != nil && lastKey.mnemonicHash == mnemonicHash {
+		return lastKey
+	}
+
+	key := &treasuryKey{mnemonicHash: mnemonicHash}
+	keyringInfo, _, err := GetKeyringInfoFromMnemonic(mnemonic)
+	if err != nil {
+		fmt.Printf("[secret rotation] Failed to derive the treasury address of secret ID %v: %v\n", secretID, err)
+	} else {
+		key.address = keyringInfo.GetAddress().String()
+	}
+	treasuryKeys[secretID] = key
+
+	if lastKey != nil && lastKey.address != key.address {
+		fmt.Printf("[secret rotation] The treasury address of secret ID %v changed from %v to %v\n", secretID, lastKey.address, key.address)
+	}
+	return key
+}
+
+// Fetch the secrets again without waiting for the cache TTL, all the cached secrets if secretNames is empty.
+// The remote signers fetch their public keys again, and the treasury addresses are derived again from the rotated mnemonics.
+func RefreshSecrets(ctx context.Context, secretNames []string) (*coreumservicemsg.RefreshSecretsReply, error) {
+	// The addresses before the refresh
+	treasuryKeysMu.Lock()
+	lastAddresses := map[string]string{}
+	secretIDs := []string{}
+	for secretID, key := range treasuryKeys {
+		lastAddresses[secretID] = key.address
+		secretIDs = append(secretIDs, secretID)
+	}
+	treasuryKeysMu.Unlock()
+	sort.Strings(secretIDs)
+
+	versions, err := awssecretmanager.RefreshSecrets(ctx, secretNames)
+	if err != nil {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, nil, "failed to refresh the secrets: %v", err)
+	}
+	resetRemoteSigners()
+
+	reply := &coreumservicemsg.RefreshSecretsReply{
+		Secrets: []*coreumservicemsg.SecretVersion{},
+		Wallets: []*coreumservicemsg.TreasuryWallet{},
+	}
+	for _, version := range versions {
+		reply.Secrets = append(reply.Secrets, &coreumservicemsg.SecretVersion{
+			SecretName:        version.SecretName,
+			CurrentVersionID:  version.CurrentVersionID,
+			PreviousVersionID: version.PreviousVersionID,
+			RefreshedAt:       version.RefreshedAt.Unix(),
+		})
+	}
+
+	for _, secretID := range secretIDs {
+		mnemonic, err := getRequiredTreasuryMnemonic(ctx, secretID)
+		if err != nil {
+			return nil, err
+		}
+		wallet := &coreumservicemsg.TreasuryWallet{
+			SecretID: secretID,
+			Address:  trackTreasuryKey(secretID, mnemonic).address,
+		}
+		if wallet.Address != lastAddresses[secretID] {
+			wallet.PreviousAddress = lastAddresses[secretID]
+		}
+		reply.Wallets = append(reply.Wallets, wallet)
+	}
+	return reply, nil
+}
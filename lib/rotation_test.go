@@ -0,0 +1,85 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	awssecretmanager "coreumservice/go/stably_io/secretmanager/aws"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackTreasuryKey(t *testing.T) {
+	secretID := "TestTrackTreasuryKey"
+	defer func() {
+		treasuryKeysMu.Lock()
+		delete(treasuryKeys, secretID)
+		treasuryKeysMu.Unlock()
+	}()
+
+	// The concurrent reads of the same mnemonic derive the same address
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.Equal(t, mustAddressFromMnemonic(t, offlineTestMnemonic), trackTreasuryKey(secretID, offlineTestMnemonic).address)
+		}()
+	}
+	wg.Wait()
+
+	// The rotated mnemonic has the other address
+	key := trackTreasuryKey(secretID, offlineTestOtherMnemonic)
+	require.Equal(t, mustAddressFromMnemonic(t, offlineTestOtherMnemonic), key.address)
+	require.Same(t, key, trackTreasuryKey(secretID, offlineTestOtherMnemonic))
+	// Only the hash of the mnemonic is kept
+	require.NotContains(t, fmt.Sprintf("%+v", *key), offlineTestOtherMnemonic)
+
+	// The invalid mnemonic has no address
+	require.Empty(t, trackTreasuryKey(secretID, "not a mnemonic").address)
+}
+
+func TestGetRequiredTreasuryMnemonicUnavailable(t *testing.T) {
+	t.Setenv("SECRET_PROVIDER", "file")
+	t.Setenv("SECRETS_FILE", filepath.Join(t.TempDir(), "missing.json"))
+
+	_, err := getRequiredTreasuryMnemonic(context.Background(), awssecretmanager.KeyUsdsTreasuryWalletMnemonic)
+	if err == nil {
+		t.Skip("the secrets are already cached")
+	}
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeSecretUnavailable, errorReply.ErrorCode)
+	require.True(t, errorReply.Retryable)
+	require.Equal(t, awssecretmanager.KeyUsdsTreasuryWalletMnemonic, errorReply.Details["secret_id"])
+}
+
+func TestGetRequiredTreasuryMnemonicUnknownSecretID(t *testing.T) {
+	_, err := getRequiredTreasuryMnemonic(context.Background(), "unknown_wallet_mnemonic")
+	errorReply := ToErrorReply(err)
+	require.Equal(t, coreumservicemsg.ErrorCodeNotFound, errorReply.ErrorCode)
+	require.False(t, errorReply.Retryable)
+	require.Equal(t, "unknown_wallet_mnemonic", errorReply.Details["secret_id"])
+}
+
+func TestResetRemoteSigners(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRemoteSigner(t, map[string]string{"treasury": offlineTestMnemonic})
+	signer, err := NewRemoteSigner(ctx, server.URL, "treasury", fakeRemoteSignerToken, server.Client())
+	require.NoError(t, err)
+
+	remoteSignersMu.Lock()
+	remoteSigners["TestResetRemoteSigners"] = signer
+	remoteSignersMu.Unlock()
+
+	resetRemoteSigners()
+	remoteSignersMu.Lock()
+	defer remoteSignersMu.Unlock()
+	require.Empty(t, remoteSigners)
+}
@@ -0,0 +1,332 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The committed sequence of the sender is re-fetched from the chain at most once per interval
+	sequenceSyncInterval = 30 * time.Second
+	// The reserved sequence not broadcast within this duration blocks the later sequences of the sender,
+	// it's given back on the next sync if no later sequence is reserved
+	sequenceReservationTimeout = 2 * time.Minute
+)
+
+// e.g. "account sequence mismatch, expected 12, got 10: incorrect account sequence"
+var sequenceMismatchPattern = regexp.MustCompile(`account sequence mismatch, expected (\d+), got (\d+)`)
+
+//nolint:gochecknoglobals // The sequences are shared by all the requests of the same sender
+var (
+	defaultSequenceManager     *SequenceManager
+	defaultSequenceManagerOnce sync.Once
+)
+
+// SequenceManager allocates the account sequences of the senders, so the concurrent transfers of the same sender
+// don't collide with "account sequence mismatch".
+// The sequences are reserved in order, tracked until the chain commits them,
+// and re-synced from the chain when the chain rejects a sequence.
+type SequenceManager struct {
+	// Return the committed sequence of the address on chain
+	fetchSequence func(ctx context.Context, address string) (uint64, error)
+
+	mu      sync.Mutex
+	senders map[string]*senderSequences
+}
+
+type senderSequences struct {
+	mu            sync.Mutex
+	synced        bool
+	lastSyncedAt  time.Time
+	chainSequence uint64
+	nextSequence  uint64
+	inFlight      map[uint64]*inFlightSequence
+	// The gaps found by the last sync, they're logged only when they change
+	lastGaps []uint64
+}
+
+type inFlightSequence struct {
+	txHash     string
+	reservedAt time.Time
+}
+
+// Whether the sequence is reserved but not broadcast in time
+func (s *inFlightSequence) isAbandoned() bool {
+	return s.txHash == "" && time.Since(s.reservedAt) > sequenceReservationTimeout
+}
+
+func NewSequenceManager(fetchSequence func(ctx context.Context, address string) (uint64, error)) *SequenceManager {
+	return &SequenceManager{
+		fetchSequence: fetchSequence,
+		senders:       map[string]*senderSequences{},
+	}
+}
+
+// Return the sequence manager shared by the service, the sequences are fetched from the chain
+func GetSequenceManager() *SequenceManager {
+	defaultSequenceManagerOnce.Do(func() {
+		defaultSequenceManager = NewSequenceManager(func(ctx context.Context, address string) (uint64, error) {
+			acc, err := GetAccountInfo(ctx, address)
+			if err != nil {
+				return 0, errors.Errorf("GetAccountInfo: %v", err)
+			}
+			return acc.Sequence, nil
+		})
+	})
+	return defaultSequenceManager
+}
+
+func (m *SequenceManager) getSender(address string) *senderSequences {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sender, ok := m.senders[address]
+	if !ok {
+		sender = &senderSequences{
+			inFlight: map[uint64]*inFlightSequence{},
+		}
+		m.senders[address] = sender
+	}
+	return sender
+}
+
+// Reserve the next sequence of the sender, it must be followed by Track or Release
+func (m *SequenceManager) Reserve(ctx context.Context, address string) (uint64, error) {
+	sender := m.getSender(address)
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	if !sender.synced || time.Since(sender.lastSyncedAt) > sequenceSyncInterval {
+		err := m.syncLocked(ctx, address, sender)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	sequence := sender.nextSequence
+	sender.nextSequence++
+	sender.inFlight[sequence] = &inFlightSequence{
+		reservedAt: time.Now(),
+	}
+	return sequence, nil
+}
+
+// Record the transaction broadcast with the sequence.
+// The sequence chosen by the caller (not reserved) is tracked too, so it's not reserved again.
+func (m *SequenceManager) Track(address string, sequence uint64, txHash string) {
+	sender := m.getSender(address)
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	if !sender.synced || sequence < sender.chainSequence {
+		return
+	}
+	inFlight, ok := sender.inFlight[sequence]
+	if !ok {
+		inFlight = &inFlightSequence{
+			reservedAt: time.Now(),
+		}
+		sender.inFlight[sequence] = inFlight
+	}
+	inFlight.txHash = txHash
+	if sequence >= sender.nextSequence {
+		sender.nextSequence = sequence + 1
+	}
+}
+
+// Give back the reserved sequence that is not broadcast.
+// Only the last reserved sequence can be reused, the others are left as the gaps.
+func (m *SequenceManager) Release(address string, sequence uint64) {
+	sender := m.getSender(address)
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	inFlight, ok := sender.inFlight[sequence]
+	if !ok || inFlight.txHash != "" {
+		return
+	}
+	if sequence+1 == sender.nextSequence {
+		delete(sender.inFlight, sequence)
+		sender.nextSequence--
+	}
+}
+
+// Update the sender after the broadcast of the sequence failed.
+// On the sequence mismatch the next sequence is taken from the error (it includes the transactions in the mempool),
+// or fetched from the chain if the error doesn't have it.
+// The sequence is released only if the node rejected the transaction in CheckTx. On the transport errors
+// (e.g. the timeout) the transaction may be in the mempool already, so the sequence is kept in flight
+// until a sync finds it committed or abandoned.
+func (m *SequenceManager) HandleBroadcastError(ctx context.Context, address string, sequence uint64, broadcastErr error) error {
+	expectedSequence, ok := parseExpectedSequence(broadcastErr)
+	if !ok {
+		switch {
+		case IsSequenceMismatchError(broadcastErr):
+			return m.Resync(ctx, address)
+		case isCheckTxRejection(broadcastErr):
+			m.Release(address, sequence)
+		default:
+			fmt.Printf("[sequence manager] Sequence %d of %v kept in flight, the broadcast may have reached the mempool: %v\n",
+				sequence, address, broadcastErr)
+		}
+		return nil
+	}
+
+	sender := m.getSender(address)
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	fmt.Printf("[sequence manager] Sequence %d of %v rejected, resync to %d\n", sequence, address, expectedSequence)
+	// The mempool expects the sequences from expectedSequence, so the later ones are not in the mempool either.
+	// Their holders get the mismatch error on broadcast and resync again.
+	for inFlightSequence := range sender.inFlight {
+		if inFlightSequence >= expectedSequence || inFlightSequence == sequence {
+			delete(sender.inFlight, inFlightSequence)
+		}
+	}
+	sender.nextSequence = expectedSequence
+	return nil
+}
+
+// Fetch the committed sequence from the chain, the reservations not committed are dropped
+func (m *SequenceManager) Resync(ctx context.Context, address string) error {
+	sender := m.getSender(address)
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.synced = false
+	sender.inFlight = map[uint64]*inFlightSequence{}
+	return m.syncLocked(ctx, address, sender)
+}
+
+// Sync the committed sequence and drop the committed in-flight sequences.
+// The next sequence is moved forward if the sender is used outside the service,
+// and moved back over the abandoned reservations at the end, e.g. the proposed params never broadcast.
+func (m *SequenceManager) syncLocked(ctx context.Context, address string, sender *senderSequences) error {
+	chainSequence, err := m.fetchSequence(ctx, address)
+	if err != nil {
+		return errors.Errorf("fetchSequence: %v", err)
+	}
+
+	for sequence := range sender.inFlight {
+		if sequence < chainSequence {
+			delete(sender.inFlight, sequence)
+		}
+	}
+	if !sender.synced || sender.nextSequence < chainSequence {
+		sender.nextSequence = chainSequence
+	}
+	for sender.nextSequence > chainSequence {
+		inFlight, ok := sender.inFlight[sender.nextSequence-1]
+		if !ok || !inFlight.isAbandoned() {
+			break
+		}
+		delete(sender.inFlight, sender.nextSequence-1)
+		sender.nextSequence--
+	}
+	sender.chainSequence = chainSequence
+	sender.synced = true
+	sender.lastSyncedAt = time.Now()
+
+	gaps := sender.gapsLocked()
+	if !equalSequences(gaps, sender.lastGaps) {
+		if len(gaps) > 0 {
+			fmt.Printf("[sequence manager] Sender %v has the gaps %v, the later transactions are stuck\n", address, gaps)
+		} else {
+			fmt.Printf("[sequence manager] Sender %v has no gaps anymore\n", address)
+		}
+	}
+	sender.lastGaps = gaps
+	return nil
+}
+
+// The sequences between the committed and the next sequence that will not reach the chain:
+// not reserved at all, or reserved but not broadcast in time
+func (s *senderSequences) gapsLocked() []uint64 {
+	gaps := []uint64{}
+	for sequence := s.chainSequence; sequence < s.nextSequence; sequence++ {
+		inFlight, ok := s.inFlight[sequence]
+		if !ok || inFlight.isAbandoned() {
+			gaps = append(gaps, sequence)
+		}
+	}
+	return gaps
+}
+
+// Return the sequences of the sender, the committed sequence is fetched from the chain to detect the gaps
+func (m *SequenceManager) GetStatus(ctx context.Context, address string) (*coreumservicemsg.GetSenderSequenceStatusReply, error) {
+	sender := m.getSender(address)
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	err := m.syncLocked(ctx, address, sender)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &coreumservicemsg.GetSenderSequenceStatusReply{
+		Address:       address,
+		ChainSequence: sender.chainSequence,
+		NextSequence:  sender.nextSequence,
+		InFlight:      []*coreumservicemsg.InFlightSequence{},
+		Gaps:          sender.gapsLocked(),
+	}
+	for sequence, inFlight := range sender.inFlight {
+		status.InFlight = append(status.InFlight, &coreumservicemsg.InFlightSequence{
+			Sequence:   sequence,
+			TxHash:     inFlight.txHash,
+			ReservedAt: inFlight.reservedAt.Unix(),
+		})
+	}
+	sort.Slice(status.InFlight, func(i, j int) bool {
+		return status.InFlight[i].Sequence < status.InFlight[j].Sequence
+	})
+	return status, nil
+}
+
+func equalSequences(a []uint64, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether the node replied the transaction failed CheckTx, i.e. the transaction is not in the mempool
+func isCheckTxRejection(err error) bool {
+	var abciErr *sdkerrors.Error
+	return errors.As(err, &abciErr) && abciErr.ABCICode() != sdkerrors.SuccessABCICode
+}
+
+func IsSequenceMismatchError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "account sequence mismatch")
+}
+
+func parseExpectedSequence(err error) (uint64, bool) {
+	if err == nil {
+		return 0, false
+	}
+	matches := sequenceMismatchPattern.FindStringSubmatch(err.Error())
+	if len(matches) != 3 {
+		return 0, false
+	}
+	expectedSequence, err := strconv.ParseUint(matches[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return expectedSequence, true
+}
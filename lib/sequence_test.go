@@ -0,0 +1,188 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const sequenceTestAddress = "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e"
+
+func newTestSequenceManager(chainSequence *uint64) *SequenceManager {
+	return NewSequenceManager(func(ctx context.Context, address string) (uint64, error) {
+		return *chainSequence, nil
+	})
+}
+
+func TestSequenceManagerReserve(t *testing.T) {
+	ctx := context.Background()
+	chainSequence := uint64(10)
+	manager := newTestSequenceManager(&chainSequence)
+
+	// The concurrent reservations get the different sequences
+	const reservations = 20
+	sequences := make(chan uint64, reservations)
+	wg := sync.WaitGroup{}
+	for i := 0; i < reservations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sequence, err := manager.Reserve(ctx, sequenceTestAddress)
+			require.NoError(t, err)
+			sequences <- sequence
+		}()
+	}
+	wg.Wait()
+	close(sequences)
+
+	seen := map[uint64]bool{}
+	for sequence := range sequences {
+		require.False(t, seen[sequence], "sequence %d reserved twice", sequence)
+		require.GreaterOrEqual(t, sequence, chainSequence)
+		require.Less(t, sequence, chainSequence+reservations)
+		seen[sequence] = true
+	}
+}
+
+func TestSequenceManagerRelease(t *testing.T) {
+	ctx := context.Background()
+	chainSequence := uint64(5)
+	manager := newTestSequenceManager(&chainSequence)
+
+	first, err := manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	second, err := manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), first)
+	require.Equal(t, uint64(6), second)
+
+	// The last reserved sequence is reused
+	manager.Release(sequenceTestAddress, second)
+	third, err := manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), third)
+
+	// The broadcast sequence is not released
+	manager.Track(sequenceTestAddress, third, "HASH")
+	manager.Release(sequenceTestAddress, third)
+	fourth, err := manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), fourth)
+}
+
+func TestSequenceManagerReclaimAbandonedReservations(t *testing.T) {
+	ctx := context.Background()
+	chainSequence := uint64(5)
+	manager := newTestSequenceManager(&chainSequence)
+
+	for i := 0; i < 4; i++ {
+		_, err := manager.Reserve(ctx, sequenceTestAddress)
+		require.NoError(t, err)
+	}
+	manager.Track(sequenceTestAddress, 6, "HASH6")
+	// The sequences 5, 7 and 8 are reserved but never broadcast
+	sender := manager.getSender(sequenceTestAddress)
+	for _, sequence := range []uint64{5, 7, 8} {
+		sender.inFlight[sequence].reservedAt = sender.inFlight[sequence].reservedAt.Add(-2 * sequenceReservationTimeout)
+	}
+
+	// The abandoned sequences at the end are given back, the sequence 5 is a gap before the broadcast sequence 6
+	status, err := manager.GetStatus(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), status.NextSequence)
+	require.Equal(t, []uint64{5}, status.Gaps)
+
+	next, err := manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), next)
+}
+
+func TestSequenceManagerHandleBroadcastError(t *testing.T) {
+	ctx := context.Background()
+	chainSequence := uint64(5)
+	manager := newTestSequenceManager(&chainSequence)
+
+	for i := 0; i < 3; i++ {
+		_, err := manager.Reserve(ctx, sequenceTestAddress)
+		require.NoError(t, err)
+	}
+
+	// The sequence in the error takes precedence
+	err := manager.HandleBroadcastError(ctx, sequenceTestAddress, 7,
+		errors.New("rpc error: code = Unknown desc = account sequence mismatch, expected 9, got 7: incorrect account sequence"))
+	require.NoError(t, err)
+	sequence, err := manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), sequence)
+
+	// Without the sequence in the error, the sequence is fetched from the chain
+	chainSequence = 12
+	err = manager.HandleBroadcastError(ctx, sequenceTestAddress, 9, errors.New("account sequence mismatch"))
+	require.NoError(t, err)
+	sequence, err = manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), sequence)
+
+	// The transaction rejected by CheckTx is not in the mempool, its sequence is given back
+	err = manager.HandleBroadcastError(ctx, sequenceTestAddress, 12,
+		errors.Wrap(sdkerrors.ABCIError(sdkerrors.RootCodespace, sdkerrors.ErrInsufficientFunds.ABCICode(), "insufficient funds"), "transaction failed"))
+	require.NoError(t, err)
+	sequence, err = manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), sequence)
+
+	// The transaction of the timed out broadcast may be in the mempool, its sequence is kept
+	err = manager.HandleBroadcastError(ctx, sequenceTestAddress, 12, errors.WithStack(context.DeadlineExceeded))
+	require.NoError(t, err)
+	err = manager.HandleBroadcastError(ctx, sequenceTestAddress, 12, errors.New("rpc error: code = Unavailable desc = connection reset"))
+	require.NoError(t, err)
+	sequence, err = manager.Reserve(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), sequence)
+}
+
+func TestSequenceManagerGetStatus(t *testing.T) {
+	ctx := context.Background()
+	chainSequence := uint64(1)
+	manager := newTestSequenceManager(&chainSequence)
+
+	for i := 0; i < 3; i++ {
+		_, err := manager.Reserve(ctx, sequenceTestAddress)
+		require.NoError(t, err)
+	}
+	manager.Track(sequenceTestAddress, 1, "HASH1")
+	manager.Track(sequenceTestAddress, 3, "HASH3")
+	// The sequence 2 is reserved but never broadcast, it can't be released since the sequence 3 is reserved after it
+	manager.Release(sequenceTestAddress, 2)
+	inFlight := manager.getSender(sequenceTestAddress).inFlight[2]
+	inFlight.reservedAt = inFlight.reservedAt.Add(-2 * sequenceReservationTimeout)
+
+	// The sequence 1 is committed
+	chainSequence = 2
+	status, err := manager.GetStatus(ctx, sequenceTestAddress)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), status.ChainSequence)
+	require.Equal(t, uint64(4), status.NextSequence)
+	require.Len(t, status.InFlight, 2)
+	require.Equal(t, uint64(2), status.InFlight[0].Sequence)
+	require.Equal(t, "HASH3", status.InFlight[1].TxHash)
+	require.Equal(t, []uint64{2}, status.Gaps)
+	// The gaps are remembered, so the same gaps are not logged on every sync
+	require.Equal(t, []uint64{2}, manager.getSender(sequenceTestAddress).lastGaps)
+}
+
+func TestParseExpectedSequence(t *testing.T) {
+	sequence, ok := parseExpectedSequence(errors.New("account sequence mismatch, expected 42, got 40: incorrect account sequence"))
+	require.True(t, ok)
+	require.Equal(t, uint64(42), sequence)
+
+	_, ok = parseExpectedSequence(errors.New("insufficient funds"))
+	require.False(t, ok)
+}
@@ -0,0 +1,314 @@
+package coreumservicelib
+
+import (
+	"bytes"
+	"context"
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
+	"coreumservice/go/stably_io/utils"
+	"coreumservicemsg"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/CoreumFoundation/coreum/pkg/client"
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	txsigning "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+)
+
+// The secp256k1 key signing the transactions.
+// The private key may be held outside the service, only the sign bytes are sent to it.
+type Signer interface {
+	GetAddress() cosmossdk.AccAddress
+	GetPubKey() cryptotypes.PubKey
+	// Return the 64-byte signature (R || S, low S) of the SHA-256 of the sign bytes
+	Sign(ctx context.Context, signBytes []byte) ([]byte, error)
+}
+
+// The signer with the private key in the keyring
+type keyringSigner struct {
+	keyring keyring.Keyring
+	info    keyring.Info
+}
+
+func newKeyringSigner(signingKeyring keyring.Keyring, name string) (*keyringSigner, error) {
+	if signingKeyring == nil {
+		return nil, errors.New("keybase must be set prior to signing a transaction")
+	}
+	info, err := signingKeyring.Key(name)
+	if err != nil {
+		return nil, errors.Errorf("signingKeyring.Key: %v", err)
+	}
+	return &keyringSigner{keyring: signingKeyring, info: info}, nil
+}
+
+// The signer with the private key of the mnemonic in memory
+func NewMnemonicSigner(mnemonic string) (Signer, error) {
+	info, signingKeyring, err := GetKeyringInfoFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, errors.Errorf("GetKeyringInfoFromMnemonic: %v", err)
+	}
+	return &keyringSigner{keyring: signingKeyring, info: info}, nil
+}
+
+func (s *keyringSigner) GetAddress() cosmossdk.AccAddress {
+	return s.info.GetAddress()
+}
+
+func (s *keyringSigner) GetPubKey() cryptotypes.PubKey {
+	return s.info.GetPubKey()
+}
+
+func (s *keyringSigner) Sign(_ context.Context, signBytes []byte) ([]byte, error) {
+	signature, _, err := s.keyring.Sign(s.info.GetName(), signBytes)
+	if err != nil {
+		return nil, errors.Errorf("keyring.Sign: %v", err)
+	}
+	return signature, nil
+}
+
+// The request and the reply of the remote signer API
+type remoteSignerPubKeyRequest struct {
+	KeyID string `json:"key_id"`
+}
+
+type remoteSignerPubKeyReply struct {
+	// Base64 of the 33-byte compressed secp256k1 public key
+	PubKey string `json:"pub_key"`
+}
+
+type remoteSignerSignRequest struct {
+	KeyID string `json:"key_id"`
+	// Base64 of the sign bytes
+	SignBytes string `json:"sign_bytes"`
+	// Base64 of the SHA-256 of the sign bytes, for the signers signing the digest (e.g. AWS KMS)
+	Digest string `json:"digest"`
+}
+
+type remoteSignerSignReply struct {
+	// Base64 of the 64-byte signature (R || S, low S)
+	Signature string `json:"signature"`
+}
+
+// The signer sending the sign bytes to the external signer holding the private key
+type remoteSigner struct {
+	url        string
+	keyID      string
+	authToken  string
+	httpClient *http.Client
+	pubKey     cryptotypes.PubKey
+}
+
+// Return the signer of the key at the remote signer API:
+//   - POST {url}/pubkey {"key_id"} returns {"pub_key"}
+//   - POST {url}/sign {"key_id", "sign_bytes", "digest"} returns {"signature"}
+//
+// Every request is authenticated with the "Authorization: Bearer {authToken}" header.
+func NewRemoteSigner(ctx context.Context, url string, keyID string, authToken string, httpClient *http.Client) (Signer, error) {
+	signer := &remoteSigner{
+		url:        strings.TrimSuffix(url, "/"),
+		keyID:      keyID,
+		authToken:  authToken,
+		httpClient: httpClient,
+	}
+
+	reply := &remoteSignerPubKeyReply{}
+	if err := signer.call(ctx, "pubkey", &remoteSignerPubKeyRequest{KeyID: keyID}, reply); err != nil {
+		return nil, err
+	}
+	pubKeyBytes, err := base64.StdEncoding.DecodeString(reply.PubKey)
+	if err != nil {
+		return nil, errors.Errorf("invalid base64 public key of the key %v: %v", keyID, err)
+	}
+	if len(pubKeyBytes) != secp256k1.PubKeySize {
+		return nil, errors.Errorf("the public key of the key %v is not a compressed secp256k1 key", keyID)
+	}
+	signer.pubKey = &secp256k1.PubKey{Key: pubKeyBytes}
+	return signer, nil
+}
+
+func (s *remoteSigner) GetAddress() cosmossdk.AccAddress {
+	return cosmossdk.AccAddress(s.pubKey.Address())
+}
+
+func (s *remoteSigner) GetPubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+// The signature is verified, the transaction with the invalid signature would only fail on the chain
+func (s *remoteSigner) Sign(ctx context.Context, signBytes []byte) ([]byte, error) {
+	digest := sha256.Sum256(signBytes)
+	reply := &remoteSignerSignReply{}
+	if err := s.call(ctx, "sign", &remoteSignerSignRequest{
+		KeyID:     s.keyID,
+		SignBytes: base64.StdEncoding.EncodeToString(signBytes),
+		Digest:    base64.StdEncoding.EncodeToString(digest[:]),
+	}, reply); err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(reply.Signature)
+	if err != nil {
+		return nil, errors.Errorf("invalid base64 signature of the key %v: %v", s.keyID, err)
+	}
+	if !s.pubKey.VerifySignature(signBytes, signature) {
+		return nil, errors.Errorf("invalid signature of the key %v", s.keyID)
+	}
+	return signature, nil
+}
+
+// The signer not reachable is retryable, like the secret not loaded
+func (s *remoteSigner) call(ctx context.Context, method string, request interface{}, reply interface{}) error {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return errors.Errorf("json.Marshal: %v", err)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/"+method, bytes.NewReader(requestBody))
+	if err != nil {
+		return errors.Errorf("http.NewRequestWithContext: %v", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Authorization", "Bearer "+s.authToken)
+
+	unavailableDetails := map[string]string{"key_id": s.keyID}
+	httpResponse, err := s.httpClient.Do(httpRequest)
+	if err != nil {
+		return NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, unavailableDetails,
+			"the remote signer is not reachable: %v", err)
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, unavailableDetails,
+			"failed to read the reply of the remote signer: %v", err)
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, unavailableDetails,
+			"the remote signer replied %v to %v: %s", httpResponse.StatusCode, method, responseBody)
+	}
+	if err := json.Unmarshal(responseBody, reply); err != nil {
+		return errors.Errorf("invalid reply of the remote signer to %v: %v", method, err)
+	}
+	return nil
+}
+
+//nolint:gochecknoglobals
+var (
+	remoteSignersMu sync.Mutex
+	// The remote signers by key ID, their public keys are fetched once
+	remoteSigners = map[string]Signer{}
+)
+
+// Return the signer of the secret: the remote signer if the secret has a key ID at the remote signer, the mnemonic otherwise
+func GetSigner(ctx context.Context, secretID string) (Signer, error) {
+	remoteSignerConfig := config.GetConfigDefault().Blockchain.Coreum.RemoteSigner
+	keyID := remoteSignerConfig.KeyIDs[secretID]
+	if keyID == "" {
+		mnemonic, err := getRequiredTreasuryMnemonic(ctx, secretID)
+		if err != nil {
+			return nil, err
+		}
+		return NewMnemonicSigner(mnemonic)
+	}
+
+	remoteSignersMu.Lock()
+	signer, ok := remoteSigners[keyID]
+	remoteSignersMu.Unlock()
+	if ok {
+		return signer, nil
+	}
+
+	// The sign bytes of the treasury keys are only sent with the token
+	tokenEnvVar := remoteSignerConfig.TokenEnvVar
+	if tokenEnvVar == "" {
+		tokenEnvVar = coreumconfig.RemoteSignerTokenEnvVar
+	}
+	authToken := utils.GetEnvVar(tokenEnvVar, false)
+	if authToken == "" {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeSecretUnavailable, map[string]string{"key_id": keyID},
+			"missing %v environment variable of the remote signer token", tokenEnvVar)
+	}
+
+	// The public key is fetched without the lock, so the unreachable signer doesn't block the other keys
+	timeout := remoteSignerConfig.Timeout
+	if timeout == 0 {
+		timeout = coreumconfig.RemoteSignerTimeout
+	}
+	signer, err := NewRemoteSigner(ctx, remoteSignerConfig.URL, keyID, authToken, &http.Client{Timeout: timeout})
+	if err != nil {
+		return nil, errors.Wrapf(err, "NewRemoteSigner(%v)", keyID)
+	}
+
+	remoteSignersMu.Lock()
+	defer remoteSignersMu.Unlock()
+	// The concurrent call may have fetched the same key first
+	if publishedSigner, ok := remoteSigners[keyID]; ok {
+		return publishedSigner, nil
+	}
+	remoteSigners[keyID] = signer
+	return signer, nil
+}
+
+// Forget the remote signers, so their public keys are fetched again
+func resetRemoteSigners() {
+	remoteSignersMu.Lock()
+	defer remoteSignersMu.Unlock()
+	remoteSigners = map[string]Signer{}
+}
+
+// Same as tx.Sign, the sign bytes are signed by the signer, the signatures are replaced
+func signTxWithSigner(ctx context.Context,
+	txConfig sdkclient.TxConfig,
+	txf client.Factory,
+	signer Signer,
+	txBuilder sdkclient.TxBuilder,
+) error {
+	signMode := txf.SignMode()
+	if signMode == txsigning.SignMode_SIGN_MODE_UNSPECIFIED {
+		// use the SignModeHandler's default mode if unspecified
+		signMode = txConfig.SignModeHandler().DefaultMode()
+	}
+	if signMode == txsigning.SignMode_SIGN_MODE_DIRECT && len(txBuilder.GetTx().GetSigners()) > 1 {
+		return errors.New("signing in the direct mode is only supported for the transactions with one signer")
+	}
+	signerData := authsigning.SignerData{
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+	}
+
+	// The signer infos are part of the sign bytes in the direct mode, they're set with the empty signature first
+	sig := txsigning.SignatureV2{
+		PubKey:   signer.GetPubKey(),
+		Data:     &txsigning.SingleSignatureData{SignMode: signMode},
+		Sequence: txf.Sequence(),
+	}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return errors.Errorf("txBuilder.SetSignatures: %v", err)
+	}
+
+	signBytes, err := txConfig.SignModeHandler().GetSignBytes(signMode, signerData, txBuilder.GetTx())
+	if err != nil {
+		return errors.Errorf("GetSignBytes: %v", err)
+	}
+	signature, err := signer.Sign(ctx, signBytes)
+	if err != nil {
+		return errors.Wrap(err, "signer.Sign")
+	}
+
+	sig.Data = &txsigning.SingleSignatureData{SignMode: signMode, Signature: signature}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return errors.Errorf("txBuilder.SetSignatures: %v", err)
+	}
+	return nil
+}
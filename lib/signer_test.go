@@ -0,0 +1,160 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/stretchr/testify/require"
+)
+
+// The bearer token the fake remote signer expects
+const fakeRemoteSignerToken = "fake-remote-signer-token"
+
+// The fake remote signer holding the keys of the mnemonics by key ID.
+// The signature of the key "tampered" is the one of the other sign bytes.
+func newFakeRemoteSigner(t *testing.T, mnemonics map[string]string) *httptest.Server {
+	signers := map[string]Signer{}
+	for keyID, mnemonic := range mnemonics {
+		signer, err := NewMnemonicSigner(mnemonic)
+		require.NoError(t, err)
+		signers[keyID] = signer
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pubkey", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeRemoteSignerToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		request := &remoteSignerPubKeyRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(request))
+		signer, ok := signers[request.KeyID]
+		if !ok {
+			http.Error(w, "unknown key", http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(&remoteSignerPubKeyReply{
+			PubKey: base64.StdEncoding.EncodeToString(signer.GetPubKey().Bytes()),
+		}))
+	})
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+fakeRemoteSignerToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		request := &remoteSignerSignRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(request))
+		signBytes, err := base64.StdEncoding.DecodeString(request.SignBytes)
+		require.NoError(t, err)
+		if request.KeyID == "tampered" {
+			signBytes = append(signBytes, 0)
+		}
+		signature, err := signers[request.KeyID].Sign(r.Context(), signBytes)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(&remoteSignerSignReply{
+			Signature: base64.StdEncoding.EncodeToString(signature),
+		}))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRemoteSigner(t, map[string]string{
+		"treasury": offlineTestMnemonic,
+		"tampered": offlineTestOtherMnemonic,
+	})
+
+	signer, err := NewRemoteSigner(ctx, server.URL+"/", "treasury", fakeRemoteSignerToken, server.Client())
+	require.NoError(t, err)
+	require.Equal(t, mustAddressFromMnemonic(t, offlineTestMnemonic), signer.GetAddress().String())
+
+	signature, err := signer.Sign(ctx, []byte("sign bytes"))
+	require.NoError(t, err)
+	require.True(t, signer.GetPubKey().VerifySignature([]byte("sign bytes"), signature))
+
+	// The invalid signature is refused
+	tamperedSigner, err := NewRemoteSigner(ctx, server.URL, "tampered", fakeRemoteSignerToken, server.Client())
+	require.NoError(t, err)
+	_, err = tamperedSigner.Sign(ctx, []byte("sign bytes"))
+	require.Error(t, err)
+
+	// The unknown key and the unreachable signer are retryable
+	_, err = NewRemoteSigner(ctx, server.URL, "unknown", fakeRemoteSignerToken, server.Client())
+	require.Equal(t, coreumservicemsg.ErrorCodeSecretUnavailable, ToErrorReply(err).ErrorCode)
+	require.Equal(t, "unknown", ToErrorReply(err).Details["key_id"])
+	_, err = NewRemoteSigner(ctx, "http://127.0.0.1:1", "treasury", fakeRemoteSignerToken, server.Client())
+	require.Equal(t, coreumservicemsg.ErrorCodeSecretUnavailable, ToErrorReply(err).ErrorCode)
+
+	// The signer refuses the requests without the token
+	_, err = NewRemoteSigner(ctx, server.URL, "treasury", "other-token", server.Client())
+	require.Equal(t, coreumservicemsg.ErrorCodeSecretUnavailable, ToErrorReply(err).ErrorCode)
+	require.ErrorContains(t, err, "401")
+}
+
+func TestSignTxWithRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRemoteSigner(t, map[string]string{"treasury": offlineTestMnemonic})
+	signer, err := NewRemoteSigner(ctx, server.URL, "treasury", fakeRemoteSignerToken, server.Client())
+	require.NoError(t, err)
+	unsignedTx := newTestUnsignedTransfer(t, signer.GetAddress().String())
+
+	signedTxBytes, err := SignTxWithSigner(ctx, signer, unsignedTx, offlineTestAccountNumber(), 7)
+	require.NoError(t, err)
+
+	// The same transaction as the one signed with the mnemonic
+	mnemonicSignedTxBytes, err := SignTxWithMnemonic(ctx, offlineTestMnemonic, unsignedTx, offlineTestAccountNumber(), 7)
+	require.NoError(t, err)
+	require.Equal(t, mnemonicSignedTxBytes, signedTxBytes)
+
+	signedTx, err := GetAppEncodingConfig().TxConfig.TxDecoder()(signedTxBytes)
+	require.NoError(t, err)
+	sigTx, ok := signedTx.(authsigning.SigVerifiableTx)
+	require.True(t, ok)
+	signatures, err := sigTx.GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+	require.Equal(t, signer.GetPubKey(), signatures[0].PubKey)
+	require.NoError(t, authsigning.VerifySignature(signatures[0].PubKey,
+		authsigning.SignerData{ChainID: GetChainIDByStage(), AccountNumber: 12, Sequence: 7},
+		signatures[0].Data,
+		GetAppEncodingConfig().TxConfig.SignModeHandler(),
+		signedTx,
+	))
+}
+
+func TestCreateSignedTxWithSigner(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRemoteSigner(t, map[string]string{"treasury": offlineTestMnemonic})
+	remoteSigner, err := NewRemoteSigner(ctx, server.URL, "treasury", fakeRemoteSignerToken, server.Client())
+	require.NoError(t, err)
+
+	keyringInfo, signingKeyring, err := GetKeyringInfoFromMnemonic(offlineTestMnemonic)
+	require.NoError(t, err)
+	clientCtx := GetClientContext().
+		WithKeyring(signingKeyring).
+		WithFromAddress(keyringInfo.GetAddress())
+	txFactory := CoreumTxFactory(clientCtx).
+		WithAccountNumber(12).
+		WithSequence(7).
+		WithGas(100000)
+	msg := newTestUnsignedTransfer(t, keyringInfo.GetAddress().String()).GetMsgs()[0]
+
+	// The keyring and the remote signer holding the same key sign the same transaction
+	_, signedTxBytes, err := CreateSignedTx(ctx, clientCtx, txFactory, msg)
+	require.NoError(t, err)
+	_, remoteSignedTxBytes, err := CreateSignedTxWithSigner(ctx, clientCtx.WithKeyring(nil), txFactory.WithKeybase(nil), remoteSigner, msg)
+	require.NoError(t, err)
+	require.Equal(t, signedTxBytes, remoteSignedTxBytes)
+}
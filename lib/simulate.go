@@ -0,0 +1,100 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// Decode the messages from their type URL and JSON value, with the codec of all the Coreum modules
+func DecodeMessages(messages []*coreumservicemsg.CoreumTransactionMessage) ([]cosmossdk.Msg, error) {
+	if len(messages) == 0 {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "no message")
+	}
+
+	encodingConfig := GetAppEncodingConfig()
+	msgs := []cosmossdk.Msg{}
+	for i, message := range messages {
+		details := map[string]string{"index": strconv.Itoa(i)}
+		if message == nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "message %d is empty", i)
+		}
+
+		resolved, err := encodingConfig.InterfaceRegistry.Resolve(message.TypeURL)
+		if err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "unknown message type %q", message.TypeURL)
+		}
+		msg, ok := resolved.(cosmossdk.Msg)
+		if !ok {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "%v is not a message type", message.TypeURL)
+		}
+		if err := encodingConfig.Codec.UnmarshalJSON(message.Value, msg); err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "invalid %v: %v", message.TypeURL, err)
+		}
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, details, "invalid %v: %v", message.TypeURL, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// Simulate the messages as a transaction of the signer, nothing is signed or broadcast.
+// The signer is the first signer of the first message if signerAddress is empty.
+func SimulateTransaction(ctx context.Context,
+	signerAddress string,
+	msgs []cosmossdk.Msg,
+	memo string,
+) (*coreumservicemsg.SimulateTransactionReply, error) {
+	if signerAddress == "" {
+		signers := msgs[0].GetSigners()
+		if len(signers) == 0 {
+			return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "the first message has no signer")
+		}
+		signerAddress = signers[0].String()
+	}
+	signer, err := cosmossdk.AccAddressFromBech32(signerAddress)
+	if err != nil {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid signer address: %v", err)
+	}
+
+	acc, err := GetAccountInfo(ctx, signerAddress)
+	if err != nil {
+		return nil, errors.Errorf("GetAccountInfo: %v", err)
+	}
+
+	clientCtx, err := getSenderClientContext(keyring.NewInMemory(), signer)
+	if err != nil {
+		return nil, errors.Errorf("getSenderClientContext: %v", err)
+	}
+	txFactory := CoreumTxFactory(clientCtx).
+		WithAccountNumber(acc.AccountNumber).
+		WithSequence(acc.Sequence).
+		WithMemo(memo)
+	simRes, gasAdjusted, gasPrice, err := simulateMsgs(ctx, clientCtx, txFactory, msgs...)
+	if err != nil {
+		return nil, errors.Errorf("simulateMsgs: %v", err)
+	}
+
+	feeAmount := gasPrice.Amount.MulInt64(int64(gasAdjusted)).Ceil().TruncateInt()
+	simulation := &coreumservicemsg.SimulateTransactionReply{
+		SignerAddress: signerAddress,
+		GasAdjusted:   gasAdjusted,
+		GasPrice:      gasPrice.String(),
+		SuggestedFee:  toMsgCoins(cosmossdk.Coins{cosmossdk.NewCoin(gasPrice.Denom, feeAmount)})[0],
+		// The simulation is not refused, the caller decides what to do with the expensive transaction
+		ExceedsFeeCeiling: CheckFeeCeiling(gasPrice, gasAdjusted) != nil,
+		Events:            []*coreumservicemsg.CoreumTransactionEvent{},
+	}
+	if simRes.GasInfo != nil {
+		simulation.GasUsed = simRes.GasInfo.GasUsed
+	}
+	if simRes.Result != nil {
+		simulation.Events = toTransactionEvents(simRes.Result.Events)
+	}
+	return simulation, nil
+}
@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"coreumservice/go/stably_io/config"
+
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMsgSendMessage(fromAddress string, toAddress string, amount string) *coreumservicemsg.CoreumTransactionMessage {
+	return &coreumservicemsg.CoreumTransactionMessage{
+		TypeURL: "/cosmos.bank.v1beta1.MsgSend",
+		Value: json.RawMessage(fmt.Sprintf(
+			`{"from_address": %q, "to_address": %q, "amount": [{"denom": "utestcore", "amount": %q}]}`,
+			fromAddress, toAddress, amount,
+		)),
+	}
+}
+
+func TestDecodeMessages(t *testing.T) {
+	address := mustAddressFromMnemonic(t, offlineTestMnemonic)
+
+	msgs, err := DecodeMessages([]*coreumservicemsg.CoreumTransactionMessage{newTestMsgSendMessage(address, address, "100")})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	msgSend, ok := msgs[0].(*banktypes.MsgSend)
+	require.True(t, ok)
+	require.Equal(t, address, msgSend.FromAddress)
+	require.Equal(t, "100utestcore", msgSend.Amount.String())
+
+	testCases := []struct {
+		name     string
+		messages []*coreumservicemsg.CoreumTransactionMessage
+	}{
+		{name: "no message", messages: nil},
+		{name: "unknown type", messages: []*coreumservicemsg.CoreumTransactionMessage{{TypeURL: "/cosmos.bank.v1beta1.MsgUnknown", Value: json.RawMessage(`{}`)}}},
+		{name: "invalid JSON", messages: []*coreumservicemsg.CoreumTransactionMessage{{TypeURL: "/cosmos.bank.v1beta1.MsgSend", Value: json.RawMessage(`{"amount": 1}`)}}},
+		{name: "invalid message", messages: []*coreumservicemsg.CoreumTransactionMessage{newTestMsgSendMessage(address, address, "0")}},
+	}
+	for _, testCase := range testCases {
+		_, err := DecodeMessages(testCase.messages)
+		require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode, testCase.name)
+	}
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	ctx := context.Background()
+	treasuryAddress, err := GetTreasuryAddress(ctx, config.GetConfigDefault().Blockchain.Coreum.USDS.TreasurySecretID)
+	require.NoError(t, err)
+
+	msgs, err := DecodeMessages([]*coreumservicemsg.CoreumTransactionMessage{
+		newTestMsgSendMessage(treasuryAddress.Address, "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e", "1"),
+	})
+	require.NoError(t, err)
+
+	simulation, err := SimulateTransaction(ctx, treasuryAddress.Address, msgs, "TestSimulateTransaction")
+	require.NoError(t, err)
+	t.Log("simulation", simulation)
+
+	require.Equal(t, treasuryAddress.Address, simulation.SignerAddress)
+	require.NotZero(t, simulation.GasUsed)
+	require.GreaterOrEqual(t, simulation.GasAdjusted, simulation.GasUsed)
+	require.NotEmpty(t, simulation.GasPrice)
+	require.NotEmpty(t, simulation.SuggestedFee.Amount)
+	require.NotEmpty(t, simulation.Events)
+}
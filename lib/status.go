@@ -2,30 +2,31 @@ package coreumservicelib
 
 import (
 	"context"
+	"coreumservice/go/stably_io/config"
+	coreumconfig "coreumservice/go/stably_io/config/blockchain/coreum"
 	"coreumservicemsg"
 	"crypto/sha256"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	app "github.com/CoreumFoundation/coreum/app"
 	"github.com/pkg/errors"
 
 	"github.com/CoreumFoundation/coreum/pkg/client"
-	"github.com/CoreumFoundation/coreum/pkg/config"
 	cosmossdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/x/bank"
-	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
 )
 
 func GetLatestBlockStatus(ctx context.Context) (*coreumservicemsg.BlockStatus, error) {
-	rpcClient, err := GetTendermintRPCClient()
-	if err != nil {
-		return nil, errors.Errorf("GetTendermintRPCClient: %v", err)
-	}
+	return GetLatestBlockStatusFromClient(ctx, GetTendermintRPCClient())
+}
 
+// Get the latest block status from the specific node, it's also used to probe the health of the node
+func GetLatestBlockStatusFromClient(ctx context.Context, rpcClient rpcclient.StatusClient) (*coreumservicemsg.BlockStatus, error) {
 	// Reference from Coreum team: https://pastebin.com/MgJS98Jz
 	status, err := rpcClient.Status(ctx)
 	if err != nil {
@@ -47,6 +48,8 @@ func GetLatestBlockStatus(ctx context.Context) (*coreumservicemsg.BlockStatus, e
 	}, nil
 }
 
+// Return the transfer legs of the block, each one carries the execution result of its transaction.
+// The legs of the failed transactions are flagged with Success=false, they must not be credited.
 func GetBlockTransactions(ctx context.Context, blockNumber int64) ([]*coreumservicemsg.Transaction, error) {
 	grpcClient := GetGRPCClient()
 	modules := module.NewBasicManager(
@@ -55,6 +58,12 @@ func GetBlockTransactions(ctx context.Context, blockNumber int64) ([]*coreumserv
 	clientCtx := client.NewContext(client.DefaultContextConfig(), modules).WithGRPCClient(grpcClient)
 	txClient := txtypes.NewServiceClient(clientCtx)
 
+	// The execution results are in the same order as the transactions of the block
+	blockRes, err := GetTendermintRPCClient().BlockResults(ctx, &blockNumber)
+	if err != nil {
+		return nil, errors.Errorf("tendermintRPCClient.BlockResults(%v): %v", blockNumber, err)
+	}
+
 	blockResults, err := txClient.GetBlockWithTxs(ctx, &txtypes.GetBlockWithTxsRequest{
 		Height: int64(blockNumber),
 	})
@@ -64,14 +73,6 @@ func GetBlockTransactions(ctx context.Context, blockNumber int64) ([]*coreumserv
 			- Thus, to detect if the call was failed due to 0 txns, we double check it with the tendermintRPCClient.BlockResults
 			- If the block has 0 transaction, we can return an empty list without returning an error
 		*/
-		tendermintRPCClient, err := GetTendermintRPCClient()
-		if err != nil {
-			return nil, errors.Errorf("GetTendermintRPCClient(%v): %v", blockNumber, err)
-		}
-		blockRes, err := tendermintRPCClient.BlockResults(ctx, &blockNumber)
-		if err != nil {
-			return nil, errors.Errorf("tendermintRPCClient.BlockResults(%v): %v", blockNumber, err)
-		}
 		if len(blockRes.TxsResults) == 0 {
 			// Return empty list of detected transactions
 			return []*coreumservicemsg.Transaction{}, nil
@@ -79,16 +80,29 @@ func GetBlockTransactions(ctx context.Context, blockNumber int64) ([]*coreumserv
 		return nil, errors.Errorf("txClient.GetBlockWithTxs(%v): %v", blockNumber, err)
 	}
 
+	blockTxs := blockResults.Block.Data.Txs
+	if len(blockTxs) != len(blockRes.TxsResults) {
+		return nil, errors.Errorf("block %v has %d transactions but %d results", blockNumber, len(blockTxs), len(blockRes.TxsResults))
+	}
+	blockTime := blockResults.Block.Header.Time.Unix()
+
 	res := []*coreumservicemsg.Transaction{}
-	for _, txBytes := range blockResults.Block.Data.Txs {
-		tx, err := GetTransactionFromTxBytes(txBytes)
+	for i, txBytes := range blockTxs {
+		txs, err := GetTransactionFromTxBytes(txBytes)
 		if err != nil {
 			return nil, errors.Errorf("GetTransactionFromTxBytes failed with txBytes [%v]: %v", txBytes, err)
 		}
-		if tx != nil {
+		txResult := blockRes.TxsResults[i]
+		for _, tx := range txs {
 			// Assign the block number to Coreum transaction
 			// because there's no block number embedded in the transaction's body
 			tx.BlockNumber = uint64(blockNumber)
+			tx.BlockTime = blockTime
+			tx.Code = txResult.Code
+			tx.Codespace = txResult.Codespace
+			tx.GasUsed = txResult.GasUsed
+			tx.GasWanted = txResult.GasWanted
+			tx.Success = txResult.IsOK()
 			res = append(res, tx)
 		}
 	}
@@ -96,97 +110,181 @@ func GetBlockTransactions(ctx context.Context, blockNumber int64) ([]*coreumserv
 	return res, nil
 }
 
-type getBlockTransactionsChannelOutput struct {
+type blockFetchResult struct {
 	Res         []*coreumservicemsg.Transaction
 	BlockNumber int64
 	Err         error
 }
 
+// The transactions of a block range.
+// The transactions of the failed blocks are not included, the caller can resume from the failed blocks.
+type BlockRangeTransactions struct {
+	Transactions []*coreumservicemsg.Transaction
+	FailedBlocks []*coreumservicemsg.BlockError
+}
+
+// Return error if any block in the range cannot be fetched
 func GetBlockTransactionsInRange(ctx context.Context, startblockNumber int64, endblockNumber int64) ([]*coreumservicemsg.Transaction, error) {
-	// Speed up the queries with multiple goroutines
-	channels := []chan getBlockTransactionsChannelOutput{}
-	for i := startblockNumber; i <= endblockNumber; i++ {
-		ch := make(chan getBlockTransactionsChannelOutput)
-		go fetchTransactionInBlock(ctx, ch, i)
-		channels = append(channels, ch)
+	rangeTransactions, err := GetBlockTransactionsInRangeWithErrors(ctx, startblockNumber, endblockNumber, 0)
+	if err != nil {
+		return nil, err
 	}
+	if len(rangeTransactions.FailedBlocks) > 0 {
+		failedBlock := rangeTransactions.FailedBlocks[0]
+		return nil, errors.Errorf("error from goroutine at block %v: %v", failedBlock.BlockNumber, failedBlock.ErrorMessage)
+	}
+	return rangeTransactions.Transactions, nil
+}
 
-	// Join threads
-	res := []*coreumservicemsg.Transaction{}
-	for _, ch := range channels {
-		out := <-ch
+// Fetch the blocks with at most `concurrency` workers (the configured maximum if it's 0 or larger than the maximum).
+// The error is returned only if the range is invalid, the errors of the blocks are returned in FailedBlocks.
+func GetBlockTransactionsInRangeWithErrors(ctx context.Context,
+	startblockNumber int64,
+	endblockNumber int64,
+	concurrency int,
+) (*BlockRangeTransactions, error) {
+	scanConfig := config.GetConfigDefault().Blockchain.Coreum.BlockScan
+
+	rangeSize := endblockNumber - startblockNumber + 1
+	if startblockNumber <= 0 || rangeSize <= 0 {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil,
+			"invalid block range [%d, %d]", startblockNumber, endblockNumber)
+	}
+	if rangeSize > scanConfig.MaxRangeSize {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest,
+			map[string]string{"max_range_size": fmt.Sprintf("%d", scanConfig.MaxRangeSize)},
+			"block range [%d, %d] has %d blocks, more than the maximum %d", startblockNumber, endblockNumber, rangeSize, scanConfig.MaxRangeSize)
+	}
+	if concurrency <= 0 || concurrency > scanConfig.MaxConcurrency {
+		concurrency = scanConfig.MaxConcurrency
+	}
+	if int64(concurrency) > rangeSize {
+		concurrency = int(rangeSize)
+	}
+
+	// Each worker writes to its own slot, so the results keep the order of the blocks
+	results := make([]blockFetchResult, rangeSize)
+	blockNumbers := make(chan int64)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for blockNumber := range blockNumbers {
+				results[blockNumber-startblockNumber] = fetchTransactionInBlock(ctx, scanConfig, blockNumber)
+			}
+		}()
+	}
+
+	// Stop scheduling the blocks once the context is cancelled
+	next := startblockNumber
+scheduling:
+	for ; next <= endblockNumber; next++ {
+		select {
+		case blockNumbers <- next:
+		case <-ctx.Done():
+			break scheduling
+		}
+	}
+	close(blockNumbers)
+	wg.Wait()
+
+	for ; next <= endblockNumber; next++ {
+		results[next-startblockNumber] = blockFetchResult{
+			BlockNumber: next,
+			Err:         errors.Errorf("not fetched: %v", ctx.Err()),
+		}
+	}
+
+	res := &BlockRangeTransactions{
+		Transactions: []*coreumservicemsg.Transaction{},
+		FailedBlocks: []*coreumservicemsg.BlockError{},
+	}
+	for _, out := range results {
 		if out.Err != nil {
-			return nil, errors.Errorf("error from goroutine at block %v: %v", out.BlockNumber, out.Err)
+			res.FailedBlocks = append(res.FailedBlocks, &coreumservicemsg.BlockError{
+				BlockNumber:  uint64(out.BlockNumber),
+				ErrorMessage: out.Err.Error(),
+			})
+			continue
 		}
-		res = append(res, out.Res...)
+		res.Transactions = append(res.Transactions, out.Res...)
 	}
 
 	return res, nil
 }
 
-func fetchTransactionInBlock(ctx context.Context, ich chan getBlockTransactionsChannelOutput, blockNumber int64) {
-	maxTrials := 10
-	for {
-		maxTrials -= 1
+// Fetch the transactions of the block, retry with exponential backoff until the context is cancelled
+func fetchTransactionInBlock(ctx context.Context, scanConfig coreumconfig.CoreumBlockScanConfig, blockNumber int64) blockFetchResult {
+	backoff := scanConfig.InitialRetryBackoff
+	var err error
+	for trial := 1; ; trial++ {
+		var txs []*coreumservicemsg.Transaction
+		txs, err = GetBlockTransactions(ctx, blockNumber)
+		if err == nil {
+			return blockFetchResult{
+				Res:         txs,
+				BlockNumber: blockNumber,
+			}
+		}
+		if trial >= scanConfig.MaxRetries {
+			break
+		}
 
-		txs, err := GetBlockTransactions(ctx, blockNumber)
-		if err != nil {
-			if maxTrials < 1 {
-				ich <- getBlockTransactionsChannelOutput{
-					Res:         nil,
-					BlockNumber: blockNumber,
-					Err:         errors.Errorf("GetBlockTransactions(%v): %v", blockNumber, err),
-				}
-				break
+		select {
+		case <-ctx.Done():
+			return blockFetchResult{
+				BlockNumber: blockNumber,
+				Err:         errors.Errorf("GetBlockTransactions(%v): %v, then cancelled: %v", blockNumber, err, ctx.Err()),
 			}
-			time.Sleep(5 * time.Second)
-			continue
+		case <-time.After(backoff):
 		}
-		ich <- getBlockTransactionsChannelOutput{
-			Res:         txs,
-			BlockNumber: blockNumber,
-			Err:         nil,
+		backoff *= 2
+		if backoff > scanConfig.MaxRetryBackoff {
+			backoff = scanConfig.MaxRetryBackoff
 		}
-		break
+	}
+	return blockFetchResult{
+		BlockNumber: blockNumber,
+		Err:         errors.Errorf("GetBlockTransactions(%v): %v", blockNumber, err),
 	}
 }
 
-// Try to find a MsgSend message from the txBytes, return nil if there is no such kinda of message
-func GetTransactionFromTxBytes(txBytes []byte) (*coreumservicemsg.Transaction, error) {
-	modules := app.ModuleBasics
-	encodingConfig := config.NewEncodingConfig(modules)
-
-	tx := &txtypes.Tx{}
-	err := encodingConfig.Codec.Unmarshal(txBytes, tx)
+// Return one transaction per transfer leg of every message in the txBytes, see ExtractTransferLegs.
+// Return empty if there is no transfer in the transaction.
+func GetTransactionFromTxBytes(txBytes []byte) ([]*coreumservicemsg.Transaction, error) {
+	tx, err := DecodeTx(txBytes)
 	if err != nil {
-		return nil, errors.Errorf("encodingConfig.Codec.Unmarshal: %v", err)
+		return nil, errors.Errorf("DecodeTx: %v", err)
 	}
 
-	for _, msg := range tx.GetMsgs() {
-		bankSend, ok := msg.(*banktypes.MsgSend)
-		if !ok {
-			continue
-		}
+	txHash := strings.ToUpper(fmt.Sprintf("%x", sha256.Sum256(txBytes)))
+	fee := []*coreumservicemsg.Coin{}
+	if tx.AuthInfo != nil && tx.AuthInfo.Fee != nil {
+		fee = toMsgCoins(tx.AuthInfo.Fee.Amount)
+	}
 
-		coins := []*coreumservicemsg.Coin{}
-		for _, amount := range bankSend.Amount {
-			coins = append(coins, &coreumservicemsg.Coin{
-				Amount: amount.Amount.String(),
-				Denom:  amount.Denom,
+	res := []*coreumservicemsg.Transaction{}
+	for messageIndex, msg := range tx.GetMsgs() {
+		legs, err := ExtractTransferLegs(msg)
+		if err != nil {
+			return nil, errors.Errorf("ExtractTransferLegs(message %d): %v", messageIndex, err)
+		}
+		for legIndex, leg := range legs {
+			res = append(res, &coreumservicemsg.Transaction{
+				TxHash:       txHash,
+				FromAddress:  leg.FromAddress,
+				ToAddress:    leg.ToAddress,
+				Memo:         tx.Body.Memo,
+				Coins:        toMsgCoins(leg.Coins),
+				MessageIndex: messageIndex,
+				LegIndex:     legIndex,
+				MessageType:  leg.MessageType,
+				Fee:          fee,
 			})
 		}
-
-		txHash := strings.ToUpper(fmt.Sprintf("%x", sha256.Sum256(txBytes)))
-
-		return &coreumservicemsg.Transaction{
-			TxHash:      txHash,
-			FromAddress: bankSend.FromAddress,
-			ToAddress:   bankSend.ToAddress,
-			Memo:        tx.Body.Memo,
-			Coins:       coins,
-		}, nil
 	}
-	return nil, nil
+	return res, nil
 }
 
 func GetAccountInfo(ctx context.Context, address string) (*coreumservicemsg.GetAccountInfoReply, error) {
@@ -9,6 +9,8 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	"coreumservice/go/stably_io/config"
+
 	"github.com/stretchr/testify/require"
 
 	"context"
@@ -42,11 +44,16 @@ func TestGetBlockTransactions(t *testing.T) {
 					Memo:        "testing",
 					Coins: []*coreumservicemsg.Coin{
 						{
-							Amount: "123",
-							Denom:  "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2",
+							Amount:        "123",
+							Denom:         "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2",
+							DisplayAmount: "0.000123",
+							DisplayUnit:   "USDS",
 						},
 					},
 					BlockNumber: 4169066,
+					MessageType: "/cosmos.bank.v1beta1.MsgSend",
+					Fee:         []*coreumservicemsg.Coin{{Amount: "3952", Denom: "utestcore"}},
+					Success:     true,
 				},
 			},
 		},
@@ -72,7 +79,7 @@ func TestGetBlockTransactions(t *testing.T) {
 		t.Run(fmt.Sprintf("Case %v", testCase.blockNumber), func(it *testing.T) {
 			transactions, err := lib.GetBlockTransactions(ctx, testCase.blockNumber)
 			require.NoError(it, err)
-			require.Equal(it, &testCase.expected, &transactions)
+			requireBlockTransactionsEqual(it, testCase.expected, transactions)
 		})
 	}
 }
@@ -96,11 +103,16 @@ func TestGetBlockTransactionsInRange(t *testing.T) {
 					Memo:        "testing",
 					Coins: []*coreumservicemsg.Coin{
 						{
-							Amount: "123",
-							Denom:  "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2",
+							Amount:        "123",
+							Denom:         "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2",
+							DisplayAmount: "0.000123",
+							DisplayUnit:   "USDS",
 						},
 					},
 					BlockNumber: 4169066,
+					MessageType: "/cosmos.bank.v1beta1.MsgSend",
+					Fee:         []*coreumservicemsg.Coin{{Amount: "3952", Denom: "utestcore"}},
+					Success:     true,
 				},
 			},
 		},
@@ -123,24 +135,70 @@ func TestGetBlockTransactionsInRange(t *testing.T) {
 			func(it *testing.T) {
 				transactions, err := lib.GetBlockTransactionsInRange(ctx, testCase.startBlockNumber, testCase.endBlockNumber)
 				require.NoError(it, err)
-				require.NoError(it, err)
-				require.Equal(it, &testCase.expected, &transactions)
+				requireBlockTransactionsEqual(it, testCase.expected, transactions)
 			})
 	}
 }
 
+// The execution result depends on the chain, check it is filled then compare the rest
+func requireBlockTransactionsEqual(t *testing.T, expected []*coreumservicemsg.Transaction, actual []*coreumservicemsg.Transaction) {
+	for _, tx := range actual {
+		require.Greater(t, tx.BlockTime, int64(0))
+		require.Greater(t, tx.GasUsed, int64(0))
+		require.Greater(t, tx.GasWanted, int64(0))
+		tx.BlockTime = 0
+		tx.GasUsed = 0
+		tx.GasWanted = 0
+	}
+	require.Equal(t, &expected, &actual)
+}
+
+func TestGetBlockTransactionsInRangeWithErrors(t *testing.T) {
+	ctx := context.Background()
+	scanConfig := config.GetConfigDefault().Blockchain.Coreum.BlockScan
+
+	t.Run("Range larger than the maximum", func(it *testing.T) {
+		_, err := lib.GetBlockTransactionsInRangeWithErrors(ctx, 4169066, 4169066+scanConfig.MaxRangeSize, 0)
+		require.Error(it, err)
+		require.Equal(it, coreumservicemsg.ErrorCodeInvalidRequest, lib.ToErrorReply(err).ErrorCode)
+	})
+
+	t.Run("Limited concurrency", func(it *testing.T) {
+		rangeTransactions, err := lib.GetBlockTransactionsInRangeWithErrors(ctx, 4169066, 4169076, 2)
+		require.NoError(it, err)
+		require.Empty(it, rangeTransactions.FailedBlocks)
+		require.Len(it, rangeTransactions.Transactions, 1)
+	})
+
+	t.Run("Cancelled context returns every block as failed", func(it *testing.T) {
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		rangeTransactions, err := lib.GetBlockTransactionsInRangeWithErrors(cancelledCtx, 4169066, 4169076, 2)
+		require.NoError(it, err)
+		require.Empty(it, rangeTransactions.Transactions)
+		require.Len(it, rangeTransactions.FailedBlocks, 11)
+		require.Equal(it, uint64(4169066), rangeTransactions.FailedBlocks[0].BlockNumber)
+	})
+}
+
 func TestGetTransactionFromTxBytes(t *testing.T) {
 	t.Run("Case with MsgSend transaction", func(it *testing.T) {
-		expected := &coreumservicemsg.Transaction{
-			TxHash:      "DD4814669E9BFEEBFFDEEB4264BAB30E85FDDC5DA0158CD1194228E6569E4CAB",
-			FromAddress: "testcore1av2q6yuaeqw5rqy958842fu6u9xzw62qjy8j3u",
-			ToAddress:   "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e",
-			Memo:        "testing",
-			Coins: []*coreumservicemsg.Coin{
-				{
-					Amount: "123",
-					Denom:  "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2",
+		expected := []*coreumservicemsg.Transaction{
+			{
+				TxHash:      "DD4814669E9BFEEBFFDEEB4264BAB30E85FDDC5DA0158CD1194228E6569E4CAB",
+				FromAddress: "testcore1av2q6yuaeqw5rqy958842fu6u9xzw62qjy8j3u",
+				ToAddress:   "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e",
+				Memo:        "testing",
+				Coins: []*coreumservicemsg.Coin{
+					{
+						Amount:        "123",
+						Denom:         "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2",
+						DisplayAmount: "0.000123",
+						DisplayUnit:   "USDS",
+					},
 				},
+				MessageType: "/cosmos.bank.v1beta1.MsgSend",
+				Fee:         []*coreumservicemsg.Coin{{Amount: "3952", Denom: "utestcore"}},
 			},
 		}
 
@@ -153,14 +211,30 @@ func TestGetTransactionFromTxBytes(t *testing.T) {
 		require.Equal(it, expected, tx)
 	})
 
-	t.Run("Case without MsgSend transaction", func(it *testing.T) {
+	t.Run("Case with MsgMultiSend transaction", func(it *testing.T) {
+		expected := []*coreumservicemsg.Transaction{
+			{
+				TxHash:      "C3093EE4C212667D8C5A07C6016AC318F265B5F21C96047F78D4960EE9983FFD",
+				FromAddress: "testcore1344jh7kgg4q4fzpuamrtqjesuxeyen700nlve6",
+				ToAddress:   "testcore1xjehmty2z5j7mfmpzxe8dgrf506c70n3747c95",
+				Coins: []*coreumservicemsg.Coin{
+					{
+						Amount: "100000000",
+						Denom:  "utestcore",
+					},
+				},
+				MessageType: "/cosmos.bank.v1beta1.MsgMultiSend",
+				Fee:         []*coreumservicemsg.Coin{{Amount: "3286", Denom: "utestcore"}},
+			},
+		}
+
 		txBytesHex := "0abf010abc010a212f636f736d6f732e62616e6b2e763162657461312e4d73674d756c746953656e641296010a490a2f74657374636f7265313334346a68376b6767347134667a7075616d7274716a657375786579656e3730306e6c76653612160a097574657374636f7265120931303030303030303012490a2f74657374636f726531786a65686d7479327a356a376d666d707a786538646772663530366337306e3337343763393512160a097574657374636f72651209313030303030303030126d0a520a460a1f2f636f736d6f732e63727970746f2e736563703235366b312e5075624b657912230a21022429fe19f84c6f389f6c4b172b5ade512d8aee1aebc5c3eb6f72d97720870bf712040a02080118ffe40912170a110a097574657374636f726512043332383610c0b2041a40cbd0527dd20a60ac602bb48abea538ea802cfb749f785e1aed7f5cf177b3c6fc5ef65f9abb4328b6ddacefe999eae1a7278cb5661a91f76c1ea89cbf93c8e34a"
 		txBytes, err := hex.DecodeString(txBytesHex)
 		require.NoError(it, err)
 
 		tx, err := lib.GetTransactionFromTxBytes(txBytes)
 		require.NoError(it, err)
-		require.Nil(it, tx)
+		require.Equal(it, expected, tx)
 	})
 }
 
@@ -3,16 +3,19 @@ package coreumservicelib
 import (
 	"context"
 	"coreumservicemsg"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/CoreumFoundation/coreum/pkg/client"
-	"github.com/cosmos/cosmos-sdk/client/tx"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/x/auth/signing"
 	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
 	tmtypes "github.com/tendermint/tendermint/types"
 )
 
@@ -20,18 +23,27 @@ func CalculateGasForTransfer(ctx context.Context,
 	senderMnemonic string,
 	recipientAddress string,
 	assetDenom string,
-	toAmount int64,
+	toAmount sdk.Int,
 	memo string,
 	sequenceNumber uint64,
 ) (uint64, string, error) {
-	senderInfo, signingKeyRing, err := GetKeyringInfoFromMnemonic(senderMnemonic)
+	signer, err := NewMnemonicSigner(senderMnemonic)
 	if err != nil {
-		return 0, "", errors.Errorf("GetKeyringInfoFromMnemonic: %v", err)
+		return 0, "", errors.Errorf("NewMnemonicSigner: %v", err)
 	}
+	return calculateGasForTransferWithSigner(ctx, newTransferSigner(signer), recipientAddress, assetDenom, toAmount, memo, sequenceNumber)
+}
 
-	clientCtx, txFactory, msg, err := PrepareTransferTransaction(ctx,
-		signingKeyRing,
-		senderInfo.GetAddress().String(),
+// Same as CalculateGasForTransfer, the sequence is the one of the signer
+func calculateGasForTransferWithSigner(ctx context.Context,
+	signer *transferSigner,
+	recipientAddress string,
+	assetDenom string,
+	toAmount sdk.Int,
+	memo string,
+	sequenceNumber uint64,
+) (uint64, string, error) {
+	clientCtx, txFactory, msg, err := signer.prepareTransferTransaction(ctx,
 		recipientAddress,
 		assetDenom,
 		toAmount,
@@ -47,7 +59,7 @@ func CalculateGasForTransfer(ctx context.Context,
 	// calculate gas
 	gasUsedForTransaction, gasPrice, err := CalculateGas(ctx, clientCtx, txFactory, msg)
 	if err != nil {
-		return 0, "", errors.Errorf("CalculateGas: %v", err)
+		return 0, "", errors.Wrap(err, "CalculateGas")
 	}
 
 	return gasUsedForTransaction, gasPrice, nil
@@ -59,36 +71,45 @@ client.CalculateGas returns error if the sequence number has been taken by the s
 So we need to calculate the gas value seperately, and save to DB before submitting the transaction to the blockchain
 */
 func CalculateGas(ctx context.Context, clientCtx client.Context, txf client.Factory, msgs ...sdk.Msg) (uint64, string, error) {
-	gasPrice, err := client.GetGasPrice(ctx, clientCtx)
+	_, adjusted, gasPrice, err := simulateMsgs(ctx, clientCtx, txf, msgs...)
 	if err != nil {
-		return 0, "", errors.Errorf("client.GetGasPrice: %v", err)
+		return 0, "", err
+	}
+	// Refuse the transaction before it's signed if the fee spikes
+	if err := CheckFeeCeiling(gasPrice, adjusted); err != nil {
+		return 0, "", err
 	}
-	gasPrice.Amount = gasPrice.Amount.Mul(clientCtx.GasPriceAdjustment())
+	return adjusted, gasPrice.String(), nil
+}
 
-	gasPriceStr := gasPrice.String()
+// Simulate the messages with the gas price of the configured strategy.
+// Return the simulation response, the adjusted gas and the gas price.
+func simulateMsgs(ctx context.Context, clientCtx client.Context, txf client.Factory, msgs ...sdk.Msg) (*sdktx.SimulateResponse, uint64, sdk.DecCoin, error) {
+	gasPrice, err := GetGasPriceByStrategy(ctx, clientCtx)
+	if err != nil {
+		return nil, 0, sdk.DecCoin{}, errors.Errorf("GetGasPriceByStrategy: %v", err)
+	}
 
-	// Save the previous value of gas price
-	oldGasPrice := txf.GasPrices()
-	txf = txf.WithGasPrices(gasPriceStr)
+	// The factory is passed by value, the gas price of the caller is not changed
+	txf = txf.WithGasPrices(gasPrice.String())
 
-	_, adjusted, err := client.CalculateGas(ctx, clientCtx, txf, msgs...)
+	// client.CalculateGas doesn't set the fee granter, using the grant costs gas
+	if len(clientCtx.FeeGranterAddress()) > 0 {
+		simRes, adjusted, err := calculateGasWithFeeGranter(ctx, clientCtx, txf, msgs...)
+		if err != nil {
+			return nil, 0, sdk.DecCoin{}, errors.Errorf("calculateGasWithFeeGranter: %v", err)
+		}
+		return simRes, adjusted, gasPrice, nil
+	}
 
-	// Revert the old gas price
-	txf.WithGasPrices(oldGasPrice.String())
+	simRes, adjusted, err := client.CalculateGas(ctx, clientCtx, txf, msgs...)
 	if err != nil {
-		return 0, "", errors.Errorf("client.CalculateGas: %v", err)
+		return nil, 0, sdk.DecCoin{}, errors.Errorf("client.CalculateGas: %v", err)
 	}
-	return adjusted, gasPriceStr, nil
+	return simRes, adjusted, gasPrice, nil
 }
 
 func CreateSignedTx(ctx context.Context, clientCtx client.Context, txf client.Factory, msgs ...sdk.Msg) (signing.Tx, []byte, error) {
-	unsignedTx, err := txf.BuildUnsignedTx(msgs...)
-	if err != nil {
-		return nil, nil, errors.Errorf("txf.BuildUnsignedTx: %v", err)
-	}
-
-	unsignedTx.SetFeeGranter(clientCtx.FeeGranterAddress())
-
 	// in case the name is not provided by that address, take the name by the address
 	fromName := clientCtx.FromName()
 	if fromName == "" && len(clientCtx.FromAddress()) > 0 {
@@ -99,9 +120,35 @@ func CreateSignedTx(ctx context.Context, clientCtx client.Context, txf client.Fa
 		fromName = key.GetName()
 	}
 
-	err = tx.Sign(txf, fromName, unsignedTx, true)
+	signer, err := newKeyringSigner(txf.Keybase(), fromName)
 	if err != nil {
-		return nil, nil, errors.Errorf("tx.Sign: %v", err)
+		return nil, nil, errors.Errorf("newKeyringSigner: %v", err)
+	}
+	return CreateSignedTxWithSigner(ctx, clientCtx, txf, signer, msgs...)
+}
+
+// Same as CreateSignedTx, the transaction is signed by the signer instead of the keyring
+func CreateSignedTxWithSigner(ctx context.Context,
+	clientCtx client.Context,
+	txf client.Factory,
+	signer Signer,
+	msgs ...sdk.Msg,
+) (signing.Tx, []byte, error) {
+	// The gas price and gas may be given by the caller instead of CalculateGas
+	if err := checkFactoryFeeCeiling(txf); err != nil {
+		return nil, nil, err
+	}
+
+	unsignedTx, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, nil, errors.Errorf("txf.BuildUnsignedTx: %v", err)
+	}
+
+	unsignedTx.SetFeeGranter(clientCtx.FeeGranterAddress())
+
+	err = signTxWithSigner(ctx, clientCtx.TxConfig(), txf, signer, unsignedTx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "signTxWithSigner")
 	}
 
 	signedTx := unsignedTx.GetTx()
@@ -125,7 +172,12 @@ func CalculateHashOfTransaction(transactionInBytes []byte) ([]byte, string) {
 	return hashByte, fmt.Sprintf("%X", hashByte)
 }
 
-// Return nil if there's no transaction with the corresponding hash.
+// The number of the mempool transactions checked for the pending transaction
+const unconfirmedTxsLimit = 100
+
+// Return the transaction with the corresponding hash and its status.
+// The status is not_found if the transaction is neither in a block nor in the mempool of the node,
+// unknown if the mempool has more transactions than the checked ones.
 // There's error returned only if there's error when communicating with the blockchain server.
 func GetTransactionByHash(ctx context.Context, clientCtx client.Context, transactionHash string) (*coreumservicemsg.CoreumTransactionDetail, error) {
 	txSvcClient := sdktx.NewServiceClient(clientCtx)
@@ -137,24 +189,235 @@ func GetTransactionByHash(ctx context.Context, clientCtx client.Context, transac
 		Hash: transactionHash,
 	})
 	if err != nil {
-		// there's no transaction with the provided hash on the blockchain
+		// there's no transaction with the provided hash on the blockchain, it may still be in the mempool
 		if strings.Contains(err.Error(), "tx not found") {
-			return nil, nil
+			return getPendingTransactionByHash(requestCtx, transactionHash)
 		}
 		return nil, err
 	}
 	txResponse := res.TxResponse
 
+	// The gRPC client doesn't unpack the messages, so the transaction is decoded from its bytes
+	if txResponse.Tx == nil {
+		return nil, errors.Errorf("transaction %v has no body", transactionHash)
+	}
+	tx, err := DecodeTx(txResponse.Tx.Value)
+	if err != nil {
+		return nil, errors.Errorf("DecodeTx: %v", err)
+	}
+
+	transactionDetail, err := toTransactionDetail(tx)
+	if err != nil {
+		return nil, errors.Errorf("toTransactionDetail: %v", err)
+	}
+	transactionDetail.TxHash = txResponse.TxHash
+	transactionDetail.Height = txResponse.Height
+	transactionDetail.Code = txResponse.Code
+	transactionDetail.Codespace = txResponse.Codespace
+	transactionDetail.RawLog = txResponse.RawLog
+	transactionDetail.GasUsed = txResponse.GasUsed
+	transactionDetail.GasWanted = txResponse.GasWanted
+
+	transactionDetail.Status = coreumservicemsg.TransactionStatusSuccess
 	if txResponse.Code != 0 {
-		// there's no transaction with the provided hash on the blockchain
-		return nil, nil
+		transactionDetail.Status = coreumservicemsg.TransactionStatusFailed
+	}
+
+	if txResponse.Timestamp != "" {
+		blockTime, err := time.Parse(time.RFC3339, txResponse.Timestamp)
+		if err != nil {
+			return nil, errors.Errorf("time.Parse(%v): %v", txResponse.Timestamp, err)
+		}
+		transactionDetail.Timestamp = blockTime.Unix()
 	}
 
+	transactionDetail.Events = toTransactionEvents(txResponse.Events)
+
+	return transactionDetail, nil
+}
+
+func toTransactionEvents(events []abci.Event) []*coreumservicemsg.CoreumTransactionEvent {
+	transactionEvents := []*coreumservicemsg.CoreumTransactionEvent{}
+	for _, event := range events {
+		transactionEvent := &coreumservicemsg.CoreumTransactionEvent{
+			Type:       event.Type,
+			Attributes: []*coreumservicemsg.CoreumTransactionEventAttribute{},
+		}
+		for _, attribute := range event.Attributes {
+			transactionEvent.Attributes = append(transactionEvent.Attributes, &coreumservicemsg.CoreumTransactionEventAttribute{
+				Key:   string(attribute.Key),
+				Value: string(attribute.Value),
+			})
+		}
+		transactionEvents = append(transactionEvents, transactionEvent)
+	}
+	return transactionEvents
+}
+
+// Look for the transaction in the mempool of the node.
+// The transaction submitted by this instance is pending, the mempool of the node may not have received it yet.
+func getPendingTransactionByHash(ctx context.Context, transactionHash string) (*coreumservicemsg.CoreumTransactionDetail, error) {
+	if isSubmittedTransaction(transactionHash) {
+		return &coreumservicemsg.CoreumTransactionDetail{
+			TxHash: strings.ToUpper(transactionHash),
+			Status: coreumservicemsg.TransactionStatusPending,
+		}, nil
+	}
+
+	limit := unconfirmedTxsLimit
+	unconfirmedTxs, err := GetTendermintRPCClient().UnconfirmedTxs(ctx, &limit)
+	if err != nil {
+		return nil, errors.Errorf("rpcClient.UnconfirmedTxs: %v", err)
+	}
+	return findPendingTransaction(transactionHash, unconfirmedTxs)
+}
+
+// Look for the transaction in the checked transactions of the mempool
+func findPendingTransaction(transactionHash string, unconfirmedTxs *coretypes.ResultUnconfirmedTxs) (*coreumservicemsg.CoreumTransactionDetail, error) {
+	for _, txBytes := range unconfirmedTxs.Txs {
+		_, hash := CalculateHashOfTransaction(txBytes)
+		if !strings.EqualFold(hash, transactionHash) {
+			continue
+		}
+
+		tx, err := DecodeTx(txBytes)
+		if err != nil {
+			return nil, errors.Errorf("DecodeTx: %v", err)
+		}
+		transactionDetail, err := toTransactionDetail(tx)
+		if err != nil {
+			return nil, errors.Errorf("toTransactionDetail: %v", err)
+		}
+		transactionDetail.TxHash = hash
+		transactionDetail.Status = coreumservicemsg.TransactionStatusPending
+		return transactionDetail, nil
+	}
+
+	// The transaction may be in the mempool beyond the checked transactions
+	status := coreumservicemsg.TransactionStatusNotFound
+	if unconfirmedTxs.Total > len(unconfirmedTxs.Txs) {
+		status = coreumservicemsg.TransactionStatusUnknown
+	}
+	return &coreumservicemsg.CoreumTransactionDetail{
+		TxHash: strings.ToUpper(transactionHash),
+		Status: status,
+	}, nil
+}
+
+// Convert the fields of the decoded transaction, the result fields (height, code, events,...) are filled by the caller
+func toTransactionDetail(tx *sdktx.Tx) (*coreumservicemsg.CoreumTransactionDetail, error) {
 	transactionDetail := &coreumservicemsg.CoreumTransactionDetail{
+		Fee:     []*coreumservicemsg.Coin{},
+		Signers: []string{},
 		Body: coreumservicemsg.CoreumTransactionBody{
-			Memo: "",
+			Messages: []*coreumservicemsg.CoreumTransactionMessage{},
 		},
 	}
 
+	if tx.AuthInfo != nil && tx.AuthInfo.Fee != nil {
+		transactionDetail.Fee = toMsgCoins(tx.AuthInfo.Fee.Amount)
+		transactionDetail.GasWanted = int64(tx.AuthInfo.Fee.GasLimit)
+		transactionDetail.FeePayer = tx.AuthInfo.Fee.Payer
+		transactionDetail.FeeGranter = tx.AuthInfo.Fee.Granter
+	}
+
+	// The signers are derived from the public keys, tx.GetSigners() panics on the address of the other network
+	if tx.AuthInfo != nil {
+		for _, signerInfo := range tx.AuthInfo.SignerInfos {
+			if signerInfo.PublicKey == nil {
+				continue
+			}
+			pubKey, ok := signerInfo.PublicKey.GetCachedValue().(cryptotypes.PubKey)
+			if !ok {
+				return nil, errors.Errorf("unexpected public key type %v", signerInfo.PublicKey.TypeUrl)
+			}
+			transactionDetail.Signers = append(transactionDetail.Signers, sdk.AccAddress(pubKey.Address()).String())
+		}
+	}
+
+	if tx.Body != nil {
+		transactionDetail.Body.Memo = tx.Body.Memo
+		transactionDetail.Body.TimeoutHeight = tx.Body.TimeoutHeight
+	}
+
+	codec := GetAppEncodingConfig().Codec
+	for _, msg := range tx.GetMsgs() {
+		value, err := codec.MarshalJSON(msg)
+		if err != nil {
+			return nil, errors.Errorf("codec.MarshalJSON(%v): %v", sdk.MsgTypeURL(msg), err)
+		}
+		transactionDetail.Body.Messages = append(transactionDetail.Body.Messages, &coreumservicemsg.CoreumTransactionMessage{
+			TypeURL: sdk.MsgTypeURL(msg),
+			Value:   value,
+		})
+	}
+
 	return transactionDetail, nil
 }
+
+// Decode the transaction bytes to the messages, signer infos and fee, for the investigation of any transaction
+func DecodeTransaction(txBytes []byte) (*coreumservicemsg.DecodeTransactionReply, error) {
+	if len(txBytes) == 0 {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "empty transaction bytes")
+	}
+	tx, err := DecodeTx(txBytes)
+	if err != nil {
+		return nil, NewServiceError(coreumservicemsg.ErrorCodeInvalidRequest, nil, "invalid transaction bytes: %v", err)
+	}
+	transactionDetail, err := toTransactionDetail(tx)
+	if err != nil {
+		return nil, errors.Errorf("toTransactionDetail: %v", err)
+	}
+	signerInfos, err := toSignerInfos(tx)
+	if err != nil {
+		return nil, errors.Errorf("toSignerInfos: %v", err)
+	}
+
+	signatures := []string{}
+	for _, signature := range tx.Signatures {
+		signatures = append(signatures, base64.StdEncoding.EncodeToString(signature))
+	}
+
+	_, txHash := CalculateHashOfTransaction(txBytes)
+	return &coreumservicemsg.DecodeTransactionReply{
+		TxHash:      txHash,
+		Body:        transactionDetail.Body,
+		SignerInfos: signerInfos,
+		Fee:         transactionDetail.Fee,
+		GasLimit:    uint64(transactionDetail.GasWanted),
+		FeePayer:    transactionDetail.FeePayer,
+		FeeGranter:  transactionDetail.FeeGranter,
+		Signatures:  signatures,
+	}, nil
+}
+
+func toSignerInfos(tx *sdktx.Tx) ([]*coreumservicemsg.CoreumSignerInfo, error) {
+	signerInfos := []*coreumservicemsg.CoreumSignerInfo{}
+	if tx.AuthInfo == nil {
+		return signerInfos, nil
+	}
+	for _, signerInfo := range tx.AuthInfo.SignerInfos {
+		msgSignerInfo := &coreumservicemsg.CoreumSignerInfo{
+			Sequence: signerInfo.Sequence,
+		}
+		if signerInfo.PublicKey != nil {
+			pubKey, ok := signerInfo.PublicKey.GetCachedValue().(cryptotypes.PubKey)
+			if !ok {
+				return nil, errors.Errorf("unexpected public key type %v", signerInfo.PublicKey.TypeUrl)
+			}
+			msgSignerInfo.Address = sdk.AccAddress(pubKey.Address()).String()
+			msgSignerInfo.PubKeyType = signerInfo.PublicKey.TypeUrl
+			msgSignerInfo.PubKey = base64.StdEncoding.EncodeToString(pubKey.Bytes())
+		}
+		if signerInfo.ModeInfo != nil {
+			switch modeInfo := signerInfo.ModeInfo.Sum.(type) {
+			case *sdktx.ModeInfo_Single_:
+				msgSignerInfo.SignMode = modeInfo.Single.Mode.String()
+			case *sdktx.ModeInfo_Multi_:
+				msgSignerInfo.SignMode = "multi"
+			}
+		}
+		signerInfos = append(signerInfos, msgSignerInfo)
+	}
+	return signerInfos, nil
+}
@@ -5,6 +5,8 @@ package coreumservicelib
 
 import (
 	"context"
+	"coreumservicemsg"
+	"encoding/hex"
 	"strconv"
 	"strings"
 	"testing"
@@ -12,7 +14,10 @@ import (
 	"coreumservice/go/stably_io/config"
 
 	coreumconstant "github.com/CoreumFoundation/coreum/pkg/config/constant"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 )
 
 func TestCalculateGasForTransfer(t *testing.T) {
@@ -34,7 +39,7 @@ func TestCalculateGasForTransfer(t *testing.T) {
 		senderMnemonic,
 		"testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e",
 		usdsConfig.TokenDenom,
-		2000000,
+		cosmossdk.NewInt(2000000),
 		"testing",
 		accountInfo.Sequence,
 	)
@@ -52,3 +57,128 @@ func TestCalculateGasForTransfer(t *testing.T) {
 	require.Greater(t, gasPriceValue, float64(0))
 	require.Greater(t, gasUsed, uint64(0))
 }
+
+func TestGetTransactionByHash(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Case with the successful transaction", func(it *testing.T) {
+		transactionDetail, err := GetTransactionByHash(ctx, GetClientContext(), "DD4814669E9BFEEBFFDEEB4264BAB30E85FDDC5DA0158CD1194228E6569E4CAB")
+		require.NoError(it, err)
+
+		require.Equal(it, coreumservicemsg.TransactionStatusSuccess, transactionDetail.Status)
+		require.Equal(it, int64(4169066), transactionDetail.Height)
+		require.Greater(it, transactionDetail.Timestamp, int64(0))
+		require.Equal(it, uint32(0), transactionDetail.Code)
+		require.Greater(it, transactionDetail.GasUsed, int64(0))
+		require.Equal(it, []*coreumservicemsg.Coin{{Amount: "3952", Denom: "utestcore"}}, transactionDetail.Fee)
+		require.Len(it, transactionDetail.Signers, 1)
+		require.Equal(it, "testing", transactionDetail.Body.Memo)
+		require.Len(it, transactionDetail.Body.Messages, 1)
+		require.Equal(it, "/cosmos.bank.v1beta1.MsgSend", transactionDetail.Body.Messages[0].TypeURL)
+		require.NotEmpty(it, transactionDetail.Events)
+	})
+
+	t.Run("Case with the unknown transaction", func(it *testing.T) {
+		transactionDetail, err := GetTransactionByHash(ctx, GetClientContext(), "0000000000000000000000000000000000000000000000000000000000000000")
+		require.NoError(it, err)
+		require.Equal(it, coreumservicemsg.TransactionStatusNotFound, transactionDetail.Status)
+	})
+}
+
+// The transfer of 123 microusds with the memo "testing", signed with the sequence 8
+const testTransferTxBytesHex = "0ad1010ac5010a1c2f636f736d6f732e62616e6b2e763162657461312e4d736753656e6412a4010a2f74657374636f7265316176327136797561657177357271793935383834326675367539787a773632716a79386a3375122f74657374636f726531756e30306c366e7a6467353868746a366539666d7832343433337372637870676466743537651a400a396d6963726f757364732d74657374636f72653136327273336b6c78373365786d797570786c716a6a7530753761676763703066737765746e321203313233120774657374696e67126b0a500a460a1f2f636f736d6f732e63727970746f2e736563703235366b312e5075624b657912230a21035841d46c964f7356e38bf912ecf5e9834913420ef12bc01f1639e1fddbf331de12040a020801180812170a110a097574657374636f72651204333935321090c2041a40e714315cb9391b563394aa29bc79bb77a7d9d7fe84ce01f3128b3d20f25c330e35680132c5c41ee7f1ac9ff5d3616e4b8861abb8936d6340f931d4073e1b940b"
+
+func TestToTransactionDetail(t *testing.T) {
+	txBytes, err := hex.DecodeString(testTransferTxBytesHex)
+	require.NoError(t, err)
+
+	tx, err := DecodeTx(txBytes)
+	require.NoError(t, err)
+
+	transactionDetail, err := toTransactionDetail(tx)
+	require.NoError(t, err)
+
+	require.Equal(t, []*coreumservicemsg.Coin{{Amount: "3952", Denom: "utestcore"}}, transactionDetail.Fee)
+	require.Equal(t, int64(74000), transactionDetail.GasWanted)
+	require.Len(t, transactionDetail.Signers, 1)
+	require.Equal(t, "testing", transactionDetail.Body.Memo)
+	require.Len(t, transactionDetail.Body.Messages, 1)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", transactionDetail.Body.Messages[0].TypeURL)
+	require.JSONEq(t, `{
+		"from_address": "testcore1av2q6yuaeqw5rqy958842fu6u9xzw62qjy8j3u",
+		"to_address": "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e",
+		"amount": [{"denom": "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2", "amount": "123"}]
+	}`, string(transactionDetail.Body.Messages[0].Value))
+}
+
+func TestFindPendingTransaction(t *testing.T) {
+	txBytes, err := hex.DecodeString(testTransferTxBytesHex)
+	require.NoError(t, err)
+	_, txHash := CalculateHashOfTransaction(txBytes)
+	otherTxHash := "0000000000000000000000000000000000000000000000000000000000000000"
+
+	t.Run("Case with the transaction in the mempool", func(it *testing.T) {
+		transactionDetail, err := findPendingTransaction(strings.ToLower(txHash), &coretypes.ResultUnconfirmedTxs{
+			Total: 1,
+			Txs:   []tmtypes.Tx{txBytes},
+		})
+		require.NoError(it, err)
+		require.Equal(it, coreumservicemsg.TransactionStatusPending, transactionDetail.Status)
+		require.Equal(it, txHash, transactionDetail.TxHash)
+		require.Equal(it, "testing", transactionDetail.Body.Memo)
+	})
+
+	t.Run("Case with all the mempool checked", func(it *testing.T) {
+		transactionDetail, err := findPendingTransaction(otherTxHash, &coretypes.ResultUnconfirmedTxs{
+			Total: 1,
+			Txs:   []tmtypes.Tx{txBytes},
+		})
+		require.NoError(it, err)
+		require.Equal(it, coreumservicemsg.TransactionStatusNotFound, transactionDetail.Status)
+	})
+
+	t.Run("Case with the mempool larger than the checked transactions", func(it *testing.T) {
+		transactionDetail, err := findPendingTransaction(otherTxHash, &coretypes.ResultUnconfirmedTxs{
+			Total: unconfirmedTxsLimit + 1,
+			Txs:   []tmtypes.Tx{txBytes},
+		})
+		require.NoError(it, err)
+		require.Equal(it, coreumservicemsg.TransactionStatusUnknown, transactionDetail.Status)
+	})
+}
+
+func TestGetPendingTransactionOfSubmittedTransaction(t *testing.T) {
+	txHash := "1111111111111111111111111111111111111111111111111111111111111111"
+	recordSubmittedTransaction(txHash)
+	defer forgetSubmittedTransaction(txHash)
+
+	// The mempool isn't queried for the transaction submitted by this instance
+	transactionDetail, err := getPendingTransactionByHash(context.Background(), txHash)
+	require.NoError(t, err)
+	require.Equal(t, coreumservicemsg.TransactionStatusPending, transactionDetail.Status)
+	require.Equal(t, txHash, transactionDetail.TxHash)
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	txBytes, err := hex.DecodeString(testTransferTxBytesHex)
+	require.NoError(t, err)
+
+	decodedTransaction, err := DecodeTransaction(txBytes)
+	require.NoError(t, err)
+
+	_, expectedTxHash := CalculateHashOfTransaction(txBytes)
+	require.Equal(t, expectedTxHash, decodedTransaction.TxHash)
+	require.Equal(t, uint64(74000), decodedTransaction.GasLimit)
+	require.Equal(t, "testing", decodedTransaction.Body.Memo)
+	require.Len(t, decodedTransaction.Body.Messages, 1)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", decodedTransaction.Body.Messages[0].TypeURL)
+	require.Len(t, decodedTransaction.Signatures, 1)
+	require.Len(t, decodedTransaction.SignerInfos, 1)
+	require.Equal(t, uint64(8), decodedTransaction.SignerInfos[0].Sequence)
+	require.Equal(t, "SIGN_MODE_DIRECT", decodedTransaction.SignerInfos[0].SignMode)
+	require.Equal(t, "/cosmos.crypto.secp256k1.PubKey", decodedTransaction.SignerInfos[0].PubKeyType)
+	require.NotEmpty(t, decodedTransaction.SignerInfos[0].Address)
+
+	_, err = DecodeTransaction([]byte("not a transaction"))
+	require.Equal(t, coreumservicemsg.ErrorCodeInvalidRequest, ToErrorReply(err).ErrorCode)
+}
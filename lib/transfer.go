@@ -2,6 +2,7 @@ package coreumservicelib
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/CoreumFoundation/coreum/pkg/client"
 	cosmossdk "github.com/cosmos/cosmos-sdk/types"
@@ -15,23 +16,43 @@ func TransferTokenWithMnemonic(ctx context.Context,
 	senderMnemonic string,
 	recipientAddress string,
 	assetDenom string,
-	toAmount int64,
+	toAmount cosmossdk.Int,
 	memo string,
 	sequenceNumber uint64,
 	gasPrice string,
 	gasUsed uint64,
 ) (*cosmossdk.TxResponse, error) {
-	senderInfo, signingKeyRing, err := GetKeyringInfoFromMnemonic(senderMnemonic)
+	signer, err := NewMnemonicSigner(senderMnemonic)
 	if err != nil {
-		return nil, errors.Errorf("GetKeyringInfoFromMnemonic: %v", err)
+		return nil, errors.Errorf("NewMnemonicSigner: %v", err)
 	}
+	return transferTokenWithSigner(ctx, newTransferSigner(signer), recipientAddress, assetDenom, toAmount, memo, sequenceNumber, false, gasPrice, gasUsed)
+}
 
-	// Retrieve the sender address
-	fromAddressStr := senderInfo.GetAddress().String()
+// Same as TransferTokenWithMnemonic, the sequence is the one of the signer.
+// The sequence is given back to the sequence manager on failure only if it's reserved by the caller,
+// the sequence chosen by the caller may be reserved by another transfer.
+// The transfer of the operator is refused before broadcasting if it exceeds the remaining spend limit.
+func transferTokenWithSigner(ctx context.Context,
+	signer *transferSigner,
+	recipientAddress string,
+	assetDenom string,
+	toAmount cosmossdk.Int,
+	memo string,
+	sequenceNumber uint64,
+	reserved bool,
+	gasPrice string,
+	gasUsed uint64,
+) (*cosmossdk.TxResponse, error) {
+	// Retrieve the signer address, the sequences are managed per signer
+	fromAddressStr := signer.signerAddress.String()
+	release := func() {
+		if reserved {
+			GetSequenceManager().Release(fromAddressStr, sequenceNumber)
+		}
+	}
 
-	clientCtx, txFactory, msg, err := PrepareTransferTransaction(ctx,
-		signingKeyRing,
-		fromAddressStr,
+	clientCtx, txFactory, msg, err := signer.prepareTransferTransaction(ctx,
 		recipientAddress,
 		assetDenom,
 		toAmount,
@@ -44,9 +65,100 @@ func TransferTokenWithMnemonic(ctx context.Context,
 		return nil, errors.Errorf("PrepareTransferTransaction: %v", err)
 	}
 
-	cosmosTxResult, err := client.BroadcastTx(ctx, clientCtx, txFactory, msg)
+	// The gas price and gas may be given by the caller instead of CalculateGas
+	if err := checkFactoryFeeCeiling(txFactory); err != nil {
+		release()
+		return nil, err
+	}
+	if err := signer.checkSpendLimit(ctx, cosmossdk.NewCoins(cosmossdk.NewCoin(assetDenom, toAmount))); err != nil {
+		release()
+		return nil, err
+	}
+
+	_, signedTransactionInBytes, err := CreateSignedTxWithSigner(ctx, clientCtx, txFactory, signer.signer, msg)
+	if err != nil {
+		release()
+		return nil, errors.Wrap(err, "CreateSignedTxWithSigner")
+	}
+
+	cosmosTxResult, err := client.BroadcastRawTx(ctx, clientCtx, signedTransactionInBytes)
+	if err != nil {
+		// Resync the sequences of the sender on the mismatch, or give back the sequence rejected by CheckTx
+		if reserved {
+			if syncErr := GetSequenceManager().HandleBroadcastError(ctx, fromAddressStr, sequenceNumber, err); syncErr != nil {
+				fmt.Printf("[sequence manager] Error from HandleBroadcastError(%v): %v\n", fromAddressStr, syncErr)
+			}
+		}
+		return nil, errors.Errorf("client.BroadcastRawTx: %v", err)
+	}
+	GetSequenceManager().Track(fromAddressStr, sequenceNumber, cosmosTxResult.TxHash)
+	recordSubmittedTransaction(cosmosTxResult.TxHash)
+	return cosmosTxResult, nil
+}
+
+// Sign the messages with the mnemonic and broadcast them, with the gas estimated and the sequence reserved for the signer.
+// The fee is paid by the fee granter of the signer if any.
+func BroadcastMsgsWithMnemonic(ctx context.Context,
+	signerMnemonic string,
+	memo string,
+	msgs ...cosmossdk.Msg,
+) (*cosmossdk.TxResponse, error) {
+	signer, err := NewMnemonicSigner(signerMnemonic)
+	if err != nil {
+		return nil, errors.Errorf("NewMnemonicSigner: %v", err)
+	}
+	return BroadcastMsgsWithSigner(ctx, signer, memo, msgs...)
+}
+
+// Same as BroadcastMsgsWithMnemonic, the transaction is signed by the signer
+func BroadcastMsgsWithSigner(ctx context.Context,
+	signer Signer,
+	memo string,
+	msgs ...cosmossdk.Msg,
+) (*cosmossdk.TxResponse, error) {
+	signerAddress := signer.GetAddress()
+
+	acc, err := GetAccountInfo(ctx, signerAddress.String())
+	if err != nil {
+		return nil, errors.Errorf("GetAccountInfo: %v", err)
+	}
+
+	clientCtx, err := getSenderClientContext(nil, signerAddress)
+	if err != nil {
+		return nil, errors.Errorf("getSenderClientContext: %v", err)
+	}
+	// The simulation expects the committed sequence
+	txFactory := CoreumTxFactory(clientCtx).
+		WithAccountNumber(acc.AccountNumber).
+		WithSequence(acc.Sequence).
+		WithMemo(memo)
+	gasUsed, gasPrice, err := CalculateGas(ctx, clientCtx, txFactory, msgs...)
+	if err != nil {
+		return nil, errors.Wrap(err, "CalculateGas")
+	}
+
+	sequenceNumber, err := GetSequenceManager().Reserve(ctx, signerAddress.String())
+	if err != nil {
+		return nil, errors.Errorf("GetSequenceManager().Reserve: %v", err)
+	}
+	txFactory = txFactory.
+		WithSequence(sequenceNumber).
+		WithGasPrices(gasPrice).
+		WithGas(gasUsed)
+	_, signedTransactionInBytes, err := CreateSignedTxWithSigner(ctx, clientCtx, txFactory, signer, msgs...)
+	if err != nil {
+		GetSequenceManager().Release(signerAddress.String(), sequenceNumber)
+		return nil, errors.Wrap(err, "CreateSignedTxWithSigner")
+	}
+
+	cosmosTxResult, err := client.BroadcastRawTx(ctx, clientCtx, signedTransactionInBytes)
 	if err != nil {
-		return nil, errors.Errorf("client.BroadcastTx: %v", err)
+		if syncErr := GetSequenceManager().HandleBroadcastError(ctx, signerAddress.String(), sequenceNumber, err); syncErr != nil {
+			fmt.Printf("[sequence manager] Error from HandleBroadcastError(%v): %v\n", signerAddress.String(), syncErr)
+		}
+		return nil, errors.Errorf("client.BroadcastRawTx: %v", err)
 	}
+	GetSequenceManager().Track(signerAddress.String(), sequenceNumber, cosmosTxResult.TxHash)
+	recordSubmittedTransaction(cosmosTxResult.TxHash)
 	return cosmosTxResult, nil
 }
@@ -0,0 +1,189 @@
+package coreumservicelib
+
+import (
+	"coreumservicemsg"
+	"sync"
+
+	assetfttypes "github.com/CoreumFoundation/coreum/x/asset/ft/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+)
+
+// A single movement of coins extracted from a message
+type TransferLeg struct {
+	// Empty for the minted coins
+	FromAddress string
+	// Empty for the burnt coins
+	ToAddress string
+	Coins     cosmossdk.Coins
+	// The type URL of the message moving the coins, e.g. the inner message of MsgExec
+	MessageType string
+}
+
+// Extract the transfer legs from a message of the registered type
+type TransferExtractor func(msg cosmossdk.Msg) ([]*TransferLeg, error)
+
+//nolint:gochecknoglobals // The extractors are registered once at the start
+var (
+	transferExtractorsMu sync.RWMutex
+	transferExtractors   = map[string]TransferExtractor{}
+)
+
+func init() {
+	RegisterTransferExtractor(&banktypes.MsgSend{}, extractMsgSend)
+	RegisterTransferExtractor(&banktypes.MsgMultiSend{}, extractMsgMultiSend)
+	RegisterTransferExtractor(&authz.MsgExec{}, extractMsgExec)
+	RegisterTransferExtractor(&assetfttypes.MsgIssue{}, extractAssetFTMsgIssue)
+	RegisterTransferExtractor(&assetfttypes.MsgMint{}, extractAssetFTMsgMint)
+	RegisterTransferExtractor(&assetfttypes.MsgBurn{}, extractAssetFTMsgBurn)
+}
+
+// Register the extractor of the message type, it replaces the existing extractor of the same type
+func RegisterTransferExtractor(msg cosmossdk.Msg, extractor TransferExtractor) {
+	transferExtractorsMu.Lock()
+	defer transferExtractorsMu.Unlock()
+	transferExtractors[cosmossdk.MsgTypeURL(msg)] = extractor
+}
+
+// Extract the transfer legs of the message, return empty if the message type has no registered extractor
+func ExtractTransferLegs(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	msgType := cosmossdk.MsgTypeURL(msg)
+
+	transferExtractorsMu.RLock()
+	extractor, ok := transferExtractors[msgType]
+	transferExtractorsMu.RUnlock()
+	if !ok {
+		return []*TransferLeg{}, nil
+	}
+
+	legs, err := extractor(msg)
+	if err != nil {
+		return nil, errors.Errorf("extractor of %v: %v", msgType, err)
+	}
+	for _, leg := range legs {
+		if leg.MessageType == "" {
+			leg.MessageType = msgType
+		}
+	}
+	return legs, nil
+}
+
+func extractMsgSend(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	bankSend, ok := msg.(*banktypes.MsgSend)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", msg)
+	}
+	return []*TransferLeg{{
+		FromAddress: bankSend.FromAddress,
+		ToAddress:   bankSend.ToAddress,
+		Coins:       bankSend.Amount,
+	}}, nil
+}
+
+// One leg per output.
+// The sender is only known if there's a single input, it's empty if the coins come from multiple inputs.
+func extractMsgMultiSend(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	multiSend, ok := msg.(*banktypes.MsgMultiSend)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", msg)
+	}
+
+	fromAddress := ""
+	if len(multiSend.Inputs) == 1 {
+		fromAddress = multiSend.Inputs[0].Address
+	}
+
+	legs := []*TransferLeg{}
+	for _, output := range multiSend.Outputs {
+		legs = append(legs, &TransferLeg{
+			FromAddress: fromAddress,
+			ToAddress:   output.Address,
+			Coins:       output.Coins,
+		})
+	}
+	return legs, nil
+}
+
+// The legs of the messages executed on behalf of the granter
+func extractMsgExec(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	msgExec, ok := msg.(*authz.MsgExec)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", msg)
+	}
+
+	innerMsgs, err := msgExec.GetMessages()
+	if err != nil {
+		return nil, errors.Errorf("msgExec.GetMessages: %v", err)
+	}
+
+	legs := []*TransferLeg{}
+	for _, innerMsg := range innerMsgs {
+		innerLegs, err := ExtractTransferLegs(innerMsg)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, innerLegs...)
+	}
+	return legs, nil
+}
+
+// The initial amount is sent to the issuer
+func extractAssetFTMsgIssue(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	msgIssue, ok := msg.(*assetfttypes.MsgIssue)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", msg)
+	}
+	if msgIssue.InitialAmount.IsNil() || !msgIssue.InitialAmount.IsPositive() {
+		return []*TransferLeg{}, nil
+	}
+
+	issuer, err := cosmossdk.AccAddressFromBech32(msgIssue.Issuer)
+	if err != nil {
+		return nil, errors.Errorf("cosmossdk.AccAddressFromBech32: %v", err)
+	}
+	denom := assetfttypes.BuildDenom(msgIssue.Subunit, issuer)
+	return []*TransferLeg{{
+		ToAddress: msgIssue.Issuer,
+		Coins:     cosmossdk.NewCoins(cosmossdk.NewCoin(denom, msgIssue.InitialAmount)),
+	}}, nil
+}
+
+// The minted coins are sent to the sender (the issuer)
+func extractAssetFTMsgMint(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	msgMint, ok := msg.(*assetfttypes.MsgMint)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", msg)
+	}
+	return []*TransferLeg{{
+		ToAddress: msgMint.Sender,
+		Coins:     cosmossdk.NewCoins(msgMint.Coin),
+	}}, nil
+}
+
+// The burnt coins are taken from the sender
+func extractAssetFTMsgBurn(msg cosmossdk.Msg) ([]*TransferLeg, error) {
+	msgBurn, ok := msg.(*assetfttypes.MsgBurn)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", msg)
+	}
+	return []*TransferLeg{{
+		FromAddress: msgBurn.Sender,
+		Coins:       cosmossdk.NewCoins(msgBurn.Coin),
+	}}, nil
+}
+
+func toMsgCoins(coins cosmossdk.Coins) []*coreumservicemsg.Coin {
+	res := []*coreumservicemsg.Coin{}
+	for _, amount := range coins {
+		displayAmount, displayUnit := GetDisplayAmount(amount.Denom, amount.Amount)
+		res = append(res, &coreumservicemsg.Coin{
+			Amount:        amount.Amount.String(),
+			Denom:         amount.Denom,
+			DisplayAmount: displayAmount,
+			DisplayUnit:   displayUnit,
+		})
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib_test
+
+import (
+	lib "coreumservice/go/lib"
+	"testing"
+
+	assetfttypes "github.com/CoreumFoundation/coreum/x/asset/ft/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractTransferLegs(t *testing.T) {
+	treasuryAddress := "testcore1av2q6yuaeqw5rqy958842fu6u9xzw62qjy8j3u"
+	recipientAddress := "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e"
+	coins := cosmossdk.NewCoins(cosmossdk.NewInt64Coin("utestcore", 123))
+
+	t.Run("MsgSend wrapped in MsgExec", func(it *testing.T) {
+		msgExec := authz.NewMsgExec(cosmossdk.AccAddress("grantee_address_____"), []cosmossdk.Msg{
+			&banktypes.MsgSend{FromAddress: treasuryAddress, ToAddress: recipientAddress, Amount: coins},
+			&banktypes.MsgSend{FromAddress: treasuryAddress, ToAddress: treasuryAddress, Amount: coins},
+		})
+
+		legs, err := lib.ExtractTransferLegs(&msgExec)
+		require.NoError(it, err)
+		require.Equal(it, []*lib.TransferLeg{
+			{FromAddress: treasuryAddress, ToAddress: recipientAddress, Coins: coins, MessageType: "/cosmos.bank.v1beta1.MsgSend"},
+			{FromAddress: treasuryAddress, ToAddress: treasuryAddress, Coins: coins, MessageType: "/cosmos.bank.v1beta1.MsgSend"},
+		}, legs)
+	})
+
+	t.Run("Assetft mint and burn", func(it *testing.T) {
+		coin := cosmossdk.NewInt64Coin("utestcore", 123)
+
+		legs, err := lib.ExtractTransferLegs(&assetfttypes.MsgMint{Sender: treasuryAddress, Coin: coin})
+		require.NoError(it, err)
+		require.Equal(it, []*lib.TransferLeg{
+			{ToAddress: treasuryAddress, Coins: coins, MessageType: "/coreum.asset.ft.v1.MsgMint"},
+		}, legs)
+
+		legs, err = lib.ExtractTransferLegs(&assetfttypes.MsgBurn{Sender: treasuryAddress, Coin: coin})
+		require.NoError(it, err)
+		require.Equal(it, []*lib.TransferLeg{
+			{FromAddress: treasuryAddress, Coins: coins, MessageType: "/coreum.asset.ft.v1.MsgBurn"},
+		}, legs)
+	})
+
+	t.Run("Message without extractor", func(it *testing.T) {
+		legs, err := lib.ExtractTransferLegs(&assetfttypes.MsgGloballyFreeze{Sender: treasuryAddress, Denom: "utestcore"})
+		require.NoError(it, err)
+		require.Empty(it, legs)
+	})
+}
@@ -11,13 +11,14 @@ import (
 
 	"coreumservice/go/stably_io/config"
 
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTokenWithMnemonic(t *testing.T) {
 	ctx := context.Background()
 
-	amount := int64(2000000)
+	amount := cosmossdk.NewInt(2000000)
 	recipientAddress := "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e"
 	senderMnemonic := lib.GetTreasuryMnemonicFromSecretID(ctx, config.GetConfigDefault().Blockchain.Coreum.USDS.TreasurySecretID)
 	memo := "TestTransferTokenWithMnemonic"
@@ -36,7 +37,7 @@ func runTransferTokenTest(ctx context.Context,
 	senderMnemonic string,
 	recipientAddress string,
 	tokenDenom string,
-	amount int64,
+	amount cosmossdk.Int,
 	memo string,
 ) {
 	accountInfo, err := lib.GetAccountInfoFromMnemonic(ctx, senderMnemonic)
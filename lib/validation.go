@@ -0,0 +1,214 @@
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"fmt"
+	"strings"
+
+	assetfttypes "github.com/CoreumFoundation/coreum/x/asset/ft/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+)
+
+// Check the transfer params before they reach the signing path.
+// The failed checks are returned in the reply, the error is returned only if the chain cannot be queried.
+func ValidateTransferParams(ctx context.Context, input *coreumservicemsg.ValidateTransferParamsRequest) (*coreumservicemsg.ValidateTransferParamsReply, error) {
+	failedChecks := []*coreumservicemsg.TransferParamsCheckFailure{}
+	addFailedCheck := func(code coreumservicemsg.TransferParamsCheckCode, format string, args ...interface{}) {
+		failedChecks = append(failedChecks, newTransferParamsCheckFailure(code, format, args...))
+	}
+
+	// Check the recipient address
+	validAddress := false
+	addressPrefix, _, err := bech32.DecodeAndConvert(input.ToAddress)
+	switch {
+	case err != nil:
+		addFailedCheck(coreumservicemsg.TransferParamsCheckInvalidAddress, "invalid bech32 address %q: %v", input.ToAddress, err)
+	case addressPrefix != GetAddressPrefixByStage():
+		addFailedCheck(coreumservicemsg.TransferParamsCheckWrongAddressPrefix, "address prefix %q is not %q", addressPrefix, GetAddressPrefixByStage())
+	default:
+		validAddress = true
+	}
+
+	// Check the denom exists on chain
+	token, denomExists, err := getTokenByDenom(ctx, input.ToTokenDenom)
+	if err != nil {
+		return nil, errors.Wrap(err, "getTokenByDenom")
+	}
+	if !denomExists {
+		addFailedCheck(coreumservicemsg.TransferParamsCheckUnknownDenom, "denom %q does not exist on chain", input.ToTokenDenom)
+	}
+
+	// Check the amount, the configured decimal takes precedence over the on-chain precision
+	var amount cosmossdk.Int
+	validAmount := false
+	if denomExists {
+		tokenDecimal := 0
+		if assetConfig := GetAssetConfigByDenom(input.ToTokenDenom); assetConfig != nil {
+			tokenDecimal = assetConfig.TokenDecimal
+		} else if token != nil {
+			tokenDecimal = int(token.Precision)
+		}
+
+		amount, err = ParseDisplayAmount(input.ToAmount, tokenDecimal)
+		switch {
+		case errors.Is(err, ErrAmountPrecisionExceeded):
+			addFailedCheck(coreumservicemsg.TransferParamsCheckAmountPrecisionExceeded, "%v", err)
+		case err != nil:
+			addFailedCheck(coreumservicemsg.TransferParamsCheckInvalidAmount, "%v", err)
+		default:
+			validAmount = true
+		}
+	}
+
+	// Check the treasury balance
+	if validAmount {
+		senderSecretID := input.SenderSecretID
+		if senderSecretID == "" {
+			if assetConfig := GetAssetConfigByDenom(input.ToTokenDenom); assetConfig != nil {
+				senderSecretID = assetConfig.TreasurySecretID
+			}
+		}
+		if senderSecretID != "" {
+			treasuryAddress, err := GetTreasuryAddress(ctx, senderSecretID)
+			if err != nil {
+				return nil, errors.Wrap(err, "GetTreasuryAddress")
+			}
+			treasuryBalance, err := getBalance(ctx, treasuryAddress.Address, input.ToTokenDenom)
+			if err != nil {
+				return nil, errors.Wrap(err, "getBalance")
+			}
+			if treasuryBalance.LT(amount) {
+				addFailedCheck(coreumservicemsg.TransferParamsCheckInsufficientTreasuryBalance,
+					"treasury balance %s%s is less than %s%s", treasuryBalance, input.ToTokenDenom, amount, input.ToTokenDenom)
+			}
+		}
+	}
+
+	// Check the recipient against the token features
+	if validAddress && token != nil {
+		var checkedAmount *cosmossdk.Int
+		if validAmount {
+			checkedAmount = &amount
+		}
+		tokenFailedChecks, err := checkRecipientTokenFeatures(ctx, assetfttypes.NewQueryClient(GetClientContext()), token, input.ToAddress, checkedAmount)
+		if err != nil {
+			return nil, err
+		}
+		failedChecks = append(failedChecks, tokenFailedChecks...)
+	}
+
+	return &coreumservicemsg.ValidateTransferParamsReply{
+		Valid:        len(failedChecks) == 0,
+		FailedChecks: failedChecks,
+	}, nil
+}
+
+// Check the recipient against the globally frozen, freezing and whitelisting features of the token.
+// The whitelisted limit is checked only if the amount is valid (non-nil).
+func checkRecipientTokenFeatures(
+	ctx context.Context,
+	assetftClient assetfttypes.QueryClient,
+	token *assetfttypes.Token,
+	toAddress string,
+	amount *cosmossdk.Int,
+) ([]*coreumservicemsg.TransferParamsCheckFailure, error) {
+	failedChecks := []*coreumservicemsg.TransferParamsCheckFailure{}
+	if token.GloballyFrozen {
+		failedChecks = append(failedChecks, newTransferParamsCheckFailure(coreumservicemsg.TransferParamsCheckTokenGloballyFrozen,
+			"token %q is globally frozen", token.Denom))
+	}
+
+	if isTokenFeatureEnabled(token, assetfttypes.Feature_freezing) {
+		frozen, err := assetftClient.FrozenBalance(ctx, &assetfttypes.QueryFrozenBalanceRequest{
+			Account: toAddress,
+			Denom:   token.Denom,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "assetftClient.FrozenBalance")
+		}
+		if frozen.Balance.IsPositive() {
+			failedChecks = append(failedChecks, newTransferParamsCheckFailure(coreumservicemsg.TransferParamsCheckRecipientFrozen,
+				"recipient has frozen balance %s", frozen.Balance))
+		}
+	}
+
+	if amount != nil && isTokenFeatureEnabled(token, assetfttypes.Feature_whitelisting) {
+		whitelisted, err := assetftClient.WhitelistedBalance(ctx, &assetfttypes.QueryWhitelistedBalanceRequest{
+			Account: toAddress,
+			Denom:   token.Denom,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "assetftClient.WhitelistedBalance")
+		}
+		recipientBalance, err := getBalance(ctx, toAddress, token.Denom)
+		if err != nil {
+			return nil, errors.Wrap(err, "getBalance")
+		}
+		if whitelisted.Balance.Amount.LT(recipientBalance.Add(*amount)) {
+			failedChecks = append(failedChecks, newTransferParamsCheckFailure(coreumservicemsg.TransferParamsCheckRecipientNotWhitelisted,
+				"recipient whitelisted limit %s is less than the balance %s after the transfer", whitelisted.Balance, recipientBalance.Add(*amount)))
+		}
+	}
+	return failedChecks, nil
+}
+
+func newTransferParamsCheckFailure(code coreumservicemsg.TransferParamsCheckCode, format string, args ...interface{}) *coreumservicemsg.TransferParamsCheckFailure {
+	return &coreumservicemsg.TransferParamsCheckFailure{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Return the smart token (assetft) of the denom.
+// The token is nil for the denoms that exist on chain but are not smart tokens (e.g. the native denom).
+func getTokenByDenom(ctx context.Context, denom string) (*assetfttypes.Token, bool, error) {
+	if denom == "" {
+		return nil, false, nil
+	}
+
+	clientCtx := GetClientContext()
+	res, err := assetfttypes.NewQueryClient(clientCtx).Token(ctx, &assetfttypes.QueryTokenRequest{
+		Denom: denom,
+	})
+	if err == nil {
+		return &res.Token, true, nil
+	}
+	if !strings.Contains(err.Error(), assetfttypes.ErrTokenNotFound.Error()) &&
+		!strings.Contains(err.Error(), assetfttypes.ErrInvalidDenom.Error()) {
+		return nil, false, errors.Wrap(err, "assetftClient.Token")
+	}
+
+	// Not a smart token, fallback to the total supply of the bank module
+	supply, err := banktypes.NewQueryClient(clientCtx).SupplyOf(ctx, &banktypes.QuerySupplyOfRequest{
+		Denom: denom,
+	})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "bankClient.SupplyOf")
+	}
+	return nil, supply.Amount.IsPositive(), nil
+}
+
+func isTokenFeatureEnabled(token *assetfttypes.Token, feature assetfttypes.Feature) bool {
+	for _, f := range token.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func getBalance(ctx context.Context, address string, denom string) (cosmossdk.Int, error) {
+	balance, err := GetBalanceOfAddress(ctx, address, denom)
+	if err != nil {
+		return cosmossdk.Int{}, errors.Wrap(err, "GetBalanceOfAddress")
+	}
+	amount, ok := cosmossdk.NewIntFromString(balance)
+	if !ok {
+		return cosmossdk.Int{}, errors.Errorf("invalid balance %q", balance)
+	}
+	return amount, nil
+}
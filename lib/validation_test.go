@@ -0,0 +1,235 @@
+//go:build integration
+// +build integration
+
+package coreumservicelib
+
+import (
+	"context"
+	"coreumservicemsg"
+	"testing"
+
+	"coreumservice/go/stably_io/config"
+
+	assetfttypes "github.com/CoreumFoundation/coreum/x/asset/ft/types"
+	cosmossdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const validationTestAddress = "testcore1un00l6nzdg58htj6e9fmx24433srcxpgdft57e"
+
+func TestValidateTransferParams(t *testing.T) {
+	ctx := context.Background()
+	usdsConfig := config.GetConfigDefault().Blockchain.Coreum.USDS
+
+	_, addressBytes, err := bech32.DecodeAndConvert(validationTestAddress)
+	require.NoError(t, err)
+	wrongPrefixAddress, err := bech32.ConvertAndEncode("core", addressBytes)
+	require.NoError(t, err)
+
+	t.Run("Valid params", func(it *testing.T) {
+		reply, err := ValidateTransferParams(ctx, &coreumservicemsg.ValidateTransferParamsRequest{
+			ToTokenDenom: usdsConfig.TokenDenom,
+			ToAddress:    validationTestAddress,
+			ToAmount:     "0.000001",
+		})
+		require.NoError(it, err)
+		require.True(it, reply.Valid)
+		require.Empty(it, reply.FailedChecks)
+	})
+
+	t.Run("Every failed check is reported", func(it *testing.T) {
+		reply, err := ValidateTransferParams(ctx, &coreumservicemsg.ValidateTransferParamsRequest{
+			ToTokenDenom: usdsConfig.TokenDenom,
+			ToAddress:    "core1un00l6nzdg58htj6e9fmx24433srcxpgdft57e",
+			ToAmount:     "1.0000001",
+		})
+		require.NoError(it, err)
+		require.False(it, reply.Valid)
+		require.ElementsMatch(it, []coreumservicemsg.TransferParamsCheckCode{
+			coreumservicemsg.TransferParamsCheckInvalidAddress,
+			coreumservicemsg.TransferParamsCheckAmountPrecisionExceeded,
+		}, failedCheckCodes(reply))
+	})
+
+	testCases := []struct {
+		name  string
+		input *coreumservicemsg.ValidateTransferParamsRequest
+		code  coreumservicemsg.TransferParamsCheckCode
+	}{
+		{
+			name: "Invalid address",
+			input: &coreumservicemsg.ValidateTransferParamsRequest{
+				ToTokenDenom: usdsConfig.TokenDenom,
+				ToAddress:    "testcore1invalid",
+				ToAmount:     "0.000001",
+			},
+			code: coreumservicemsg.TransferParamsCheckInvalidAddress,
+		},
+		{
+			name: "Wrong address prefix",
+			input: &coreumservicemsg.ValidateTransferParamsRequest{
+				ToTokenDenom: usdsConfig.TokenDenom,
+				ToAddress:    wrongPrefixAddress,
+				ToAmount:     "0.000001",
+			},
+			code: coreumservicemsg.TransferParamsCheckWrongAddressPrefix,
+		},
+		{
+			name: "Unknown denom",
+			input: &coreumservicemsg.ValidateTransferParamsRequest{
+				ToTokenDenom: "unknowndenom",
+				ToAddress:    validationTestAddress,
+				ToAmount:     "0.000001",
+			},
+			code: coreumservicemsg.TransferParamsCheckUnknownDenom,
+		},
+		{
+			name: "Invalid amount",
+			input: &coreumservicemsg.ValidateTransferParamsRequest{
+				ToTokenDenom: usdsConfig.TokenDenom,
+				ToAddress:    validationTestAddress,
+				ToAmount:     "1,5",
+			},
+			code: coreumservicemsg.TransferParamsCheckInvalidAmount,
+		},
+		{
+			name: "Amount precision exceeded",
+			input: &coreumservicemsg.ValidateTransferParamsRequest{
+				ToTokenDenom: usdsConfig.TokenDenom,
+				ToAddress:    validationTestAddress,
+				ToAmount:     "0.0000001",
+			},
+			code: coreumservicemsg.TransferParamsCheckAmountPrecisionExceeded,
+		},
+		{
+			name: "Insufficient treasury balance",
+			input: &coreumservicemsg.ValidateTransferParamsRequest{
+				ToTokenDenom: usdsConfig.TokenDenom,
+				ToAddress:    validationTestAddress,
+				ToAmount:     "1000000000000000",
+			},
+			code: coreumservicemsg.TransferParamsCheckInsufficientTreasuryBalance,
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(it *testing.T) {
+			reply, err := ValidateTransferParams(ctx, testCase.input)
+			require.NoError(it, err)
+			require.False(it, reply.Valid)
+			require.Equal(it, []coreumservicemsg.TransferParamsCheckCode{testCase.code}, failedCheckCodes(reply))
+		})
+	}
+}
+
+// Return the balances of the recipient, the other queries are not implemented
+type fakeAssetFTQueryClient struct {
+	assetfttypes.QueryClient
+	frozenBalance      cosmossdk.Coin
+	whitelistedBalance cosmossdk.Coin
+}
+
+func (c *fakeAssetFTQueryClient) FrozenBalance(
+	context.Context, *assetfttypes.QueryFrozenBalanceRequest, ...grpc.CallOption,
+) (*assetfttypes.QueryFrozenBalanceResponse, error) {
+	return &assetfttypes.QueryFrozenBalanceResponse{Balance: c.frozenBalance}, nil
+}
+
+func (c *fakeAssetFTQueryClient) WhitelistedBalance(
+	context.Context, *assetfttypes.QueryWhitelistedBalanceRequest, ...grpc.CallOption,
+) (*assetfttypes.QueryWhitelistedBalanceResponse, error) {
+	return &assetfttypes.QueryWhitelistedBalanceResponse{Balance: c.whitelistedBalance}, nil
+}
+
+func TestCheckRecipientTokenFeatures(t *testing.T) {
+	ctx := context.Background()
+	denom := "utoken-" + validationTestAddress
+	amount := cosmossdk.NewInt(100)
+
+	testCases := []struct {
+		name        string
+		token       *assetfttypes.Token
+		queryClient *fakeAssetFTQueryClient
+		codes       []coreumservicemsg.TransferParamsCheckCode
+	}{
+		{
+			name: "No feature",
+			token: &assetfttypes.Token{
+				Denom: denom,
+			},
+			queryClient: &fakeAssetFTQueryClient{},
+			codes:       []coreumservicemsg.TransferParamsCheckCode{},
+		},
+		{
+			name: "Token globally frozen",
+			token: &assetfttypes.Token{
+				Denom:          denom,
+				GloballyFrozen: true,
+			},
+			queryClient: &fakeAssetFTQueryClient{},
+			codes:       []coreumservicemsg.TransferParamsCheckCode{coreumservicemsg.TransferParamsCheckTokenGloballyFrozen},
+		},
+		{
+			name: "Recipient frozen",
+			token: &assetfttypes.Token{
+				Denom:    denom,
+				Features: []assetfttypes.Feature{assetfttypes.Feature_freezing},
+			},
+			queryClient: &fakeAssetFTQueryClient{
+				frozenBalance: cosmossdk.NewCoin(denom, cosmossdk.NewInt(1)),
+			},
+			codes: []coreumservicemsg.TransferParamsCheckCode{coreumservicemsg.TransferParamsCheckRecipientFrozen},
+		},
+		{
+			name: "Recipient not frozen",
+			token: &assetfttypes.Token{
+				Denom:    denom,
+				Features: []assetfttypes.Feature{assetfttypes.Feature_freezing},
+			},
+			queryClient: &fakeAssetFTQueryClient{
+				frozenBalance: cosmossdk.NewCoin(denom, cosmossdk.ZeroInt()),
+			},
+			codes: []coreumservicemsg.TransferParamsCheckCode{},
+		},
+		{
+			name: "Recipient not whitelisted",
+			token: &assetfttypes.Token{
+				Denom:    denom,
+				Features: []assetfttypes.Feature{assetfttypes.Feature_whitelisting},
+			},
+			queryClient: &fakeAssetFTQueryClient{
+				whitelistedBalance: cosmossdk.NewCoin(denom, amount.SubRaw(1)),
+			},
+			codes: []coreumservicemsg.TransferParamsCheckCode{coreumservicemsg.TransferParamsCheckRecipientNotWhitelisted},
+		},
+		{
+			name: "Recipient whitelisted",
+			token: &assetfttypes.Token{
+				Denom:    denom,
+				Features: []assetfttypes.Feature{assetfttypes.Feature_whitelisting},
+			},
+			queryClient: &fakeAssetFTQueryClient{
+				whitelistedBalance: cosmossdk.NewCoin(denom, amount),
+			},
+			codes: []coreumservicemsg.TransferParamsCheckCode{},
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(it *testing.T) {
+			failedChecks, err := checkRecipientTokenFeatures(ctx, testCase.queryClient, testCase.token, validationTestAddress, &amount)
+			require.NoError(it, err)
+			require.Equal(it, testCase.codes, failedCheckCodes(&coreumservicemsg.ValidateTransferParamsReply{FailedChecks: failedChecks}))
+		})
+	}
+}
+
+func failedCheckCodes(reply *coreumservicemsg.ValidateTransferParamsReply) []coreumservicemsg.TransferParamsCheckCode {
+	codes := []coreumservicemsg.TransferParamsCheckCode{}
+	for _, failedCheck := range reply.FailedChecks {
+		codes = append(codes, failedCheck.Code)
+	}
+	return codes
+}
@@ -8,6 +8,7 @@ func beta() *Coreum {
 		DepositEnabled:                true,
 		USDS: CoreumAssetConfig{
 			TokenDecimal:       TokenDecimal,
+			DisplayUnit:        UsdsDisplayUnit,
 			TokenDenom:         TestUsdsTokenDenom,
 			IssuanceEnabled:    true,
 			RedemptionEnabled:  true,
@@ -16,9 +17,12 @@ func beta() *Coreum {
 			TreasurySecretID:   "usds_treasury_wallet_mnemonic",
 		},
 		PRCConfig: CoreumRPCConfig{
-			GRPCNodeURL:          "full-node.testnet-1.coreum.dev:9090",
-			TendermintRPCNodeURL: "https://full-node.testnet-1.coreum.dev:26657",
-			HTTPServerPort:       HTTPServerPort,
+			Nodes:                   testnetNodes(),
+			MaxNodeBlockLag:         MaxNodeBlockLag,
+			NodeHealthCheckInterval: NodeHealthCheckInterval,
+			HTTPServerPort:          HTTPServerPort,
 		},
+		BlockScan: defaultBlockScanConfig(),
+		Gas:       defaultGasConfig(TestnetMaxFeePerTx),
 	}
 }
@@ -1,15 +1,31 @@
 package coreumconfig
 
 import (
+	"time"
+
 	configutils "coreumservice/go/stably_io/config/utils"
 	"coreumservice/go/stably_io/utils"
 )
 
 const TestnetInitialTokenSupply = 9000000000000000
 const TokenDecimal = 6
+const UsdsDisplayUnit = "USDS"
 const TestnetRequiredNumberOfConfirmations = 1
 const MainnetRequiredNumberOfConfirmations = 2
 const HTTPServerPort = 5011
+const MaxNodeBlockLag = 5
+const NodeHealthCheckInterval = 15 * time.Second
+const BlockScanMaxConcurrency = 10
+const BlockScanMaxRangeSize = 1000
+const BlockScanMaxRetries = 10
+const BlockScanInitialRetryBackoff = 500 * time.Millisecond
+const BlockScanMaxRetryBackoff = 10 * time.Second
+const GasAdjustment = 1.0
+const GasPriceAdjustment = 1.1
+const TestnetMaxFeePerTx = 10000000 // 10 TESTCORE
+const MainnetMaxFeePerTx = 2000000  // 2 CORE
+const RemoteSignerTimeout = 10 * time.Second
+const RemoteSignerTokenEnvVar = "REMOTE_SIGNER_TOKEN"
 
 //nolint:gosec // This is the common value used in the test config
 const TestUsdsTokenDenom = "microusds-testcore162rs3klx73exmyupxlqjju0u7aggcp0fswetn2"
@@ -21,6 +37,15 @@ type Coreum struct {
 	DepositEnabled bool
 	USDS           CoreumAssetConfig
 	PRCConfig      CoreumRPCConfig
+	BlockScan      CoreumBlockScanConfig
+	Gas            CoreumGasConfig
+	FeeGrant       CoreumFeeGrantConfig
+
+	// The message types allowed in the transactions signed outside the service, empty allows all the types
+	AllowedRawTxMessageTypes []string
+
+	RemoteSigner   CoreumRemoteSignerConfig
+	OfflineSigning CoreumOfflineSigningConfig
 }
 
 type CoreumAssetConfig struct {
@@ -31,6 +56,14 @@ type CoreumAssetConfig struct {
 	SupplyAdjustment   float64
 	InitialTokenSupply uint64
 	TreasurySecretID   string
+	// The unit of the display amounts, e.g. USDS for the amounts in microusds
+	DisplayUnit string
+
+	// The hot wallet sending the transfers of the treasury with the authz send authorization granted by the treasury,
+	// the treasury signs its transfers itself if empty
+	OperatorSecretID string
+	// The address of the treasury, required with the operator so the treasury mnemonic isn't loaded to send the transfers
+	TreasuryAddress string
 }
 
 type CoreumNetworkConfig struct {
@@ -41,9 +74,144 @@ type CoreumNetworkConfig struct {
 }
 
 type CoreumRPCConfig struct {
+	// The nodes are tried in order, the unhealthy ones are skipped
+	Nodes []CoreumNodeConfig
+	// The node lagging behind the highest node by more than this number of blocks is ejected
+	MaxNodeBlockLag         int64
+	NodeHealthCheckInterval time.Duration
+	HTTPServerPort          int
+}
+
+// Limits of fetching the transactions of a block range
+type CoreumBlockScanConfig struct {
+	// Maximum number of blocks fetched at the same time
+	MaxConcurrency int
+	// Maximum number of blocks in a single request
+	MaxRangeSize int64
+	// Maximum number of attempts to fetch a block, with exponential backoff between the attempts
+	MaxRetries          int
+	InitialRetryBackoff time.Duration
+	MaxRetryBackoff     time.Duration
+}
+
+func defaultBlockScanConfig() CoreumBlockScanConfig {
+	return CoreumBlockScanConfig{
+		MaxConcurrency:      BlockScanMaxConcurrency,
+		MaxRangeSize:        BlockScanMaxRangeSize,
+		MaxRetries:          BlockScanMaxRetries,
+		InitialRetryBackoff: BlockScanInitialRetryBackoff,
+		MaxRetryBackoff:     BlockScanMaxRetryBackoff,
+	}
+}
+
+// The fee allowances letting the sending wallets pay the gas from the gas-funding wallet
+type CoreumFeeGrantConfig struct {
+	// The secret of the gas-funding wallet granting the fee allowances
+	GasFunderSecretID string
+	// The fee granter address of the sending wallet address, the sending wallet without a granter pays its own fee
+	Granters map[string]string
+}
+
+// The external signer holding the keys of the secrets, so their private keys never enter the service.
+// The signer (e.g. in front of AWS KMS) signs the sign bytes with the secp256k1 key and returns the 64-byte signature.
+type CoreumRemoteSignerConfig struct {
+	// The base URL of the signer API
+	URL string
+	// The key ID at the signer by the secret ID, the other secrets are signed with their mnemonic
+	KeyIDs map[string]string
+	// RemoteSignerTimeout if 0
+	Timeout time.Duration
+	// The environment variable with the bearer token of the signer API, RemoteSignerTokenEnvVar if empty
+	TokenEnvVar string
+}
+
+// The transactions built outside the service and signed by the sign-transaction endpoint
+type CoreumOfflineSigningConfig struct {
+	// The secret IDs of the keys allowed to sign, all the keys are refused if empty
+	SecretIDs []string
+	// The message types allowed in the signed transactions, empty allows all the types
+	AllowedMessageTypes []string
+}
+
+type GasPriceStrategy string
+
+const (
+	// The current minimum gas price of the chain
+	GasPriceStrategyMinGasPrice GasPriceStrategy = "min_gas_price"
+	// The gas price of the fee model without the discount, at least the current minimum gas price
+	GasPriceStrategyFeeModelRecommended GasPriceStrategy = "feemodel_recommended"
+	// The configured FixedGasPrice, regardless of the chain
+	GasPriceStrategyFixed GasPriceStrategy = "fixed"
+)
+
+// The gas and fee limits of the transactions sent by the service
+type CoreumGasConfig struct {
+	// The simulated gas is multiplied by this adjustment to get the gas limit
+	GasAdjustment float64
+	// The gas price of the strategy is multiplied by this adjustment, except for the fixed strategy
+	GasPriceAdjustment float64
+	PriceStrategy      GasPriceStrategy
+	// The gas price with the denom used by the fixed strategy, e.g. "0.0625ucore"
+	FixedGasPrice string
+	// The maximum fee of a transaction in the fee denom (ucore on mainnet), 0 for no limit.
+	// The transaction with a higher estimated fee is refused.
+	MaxFeePerTx uint64
+}
+
+func defaultGasConfig(maxFeePerTx uint64) CoreumGasConfig {
+	return CoreumGasConfig{
+		GasAdjustment:      GasAdjustment,
+		GasPriceAdjustment: GasPriceAdjustment,
+		PriceStrategy:      GasPriceStrategyMinGasPrice,
+		MaxFeePerTx:        maxFeePerTx,
+	}
+}
+
+type CoreumNodeConfig struct {
+	// Name of the node used in the logs and metrics
+	Name                 string
 	GRPCNodeURL          string
 	TendermintRPCNodeURL string
-	HTTPServerPort       int
+}
+
+func testnetNodes() []CoreumNodeConfig {
+	return []CoreumNodeConfig{
+		{
+			Name:                 "full-node",
+			GRPCNodeURL:          "full-node.testnet-1.coreum.dev:9090",
+			TendermintRPCNodeURL: "https://full-node.testnet-1.coreum.dev:26657",
+		},
+		{
+			Name:                 "full-node-pluto",
+			GRPCNodeURL:          "full-node-pluto.testnet-1.coreum.dev:9090",
+			TendermintRPCNodeURL: "https://full-node-pluto.testnet-1.coreum.dev:26657",
+		},
+		{
+			Name:                 "full-node-eris",
+			GRPCNodeURL:          "full-node-eris.testnet-1.coreum.dev:9090",
+			TendermintRPCNodeURL: "https://full-node-eris.testnet-1.coreum.dev:26657",
+		},
+	}
+}
+
+func mainnetNodes() []CoreumNodeConfig {
+	return []CoreumNodeConfig{
+		{
+			Name:                 "full-node",
+			GRPCNodeURL:          "full-node.mainnet-1.coreum.dev:9090",
+			TendermintRPCNodeURL: "https://full-node.mainnet-1.coreum.dev:26657",
+		},
+		{
+			Name:                 "full-node-californium",
+			GRPCNodeURL:          "full-node-californium.mainnet-1.coreum.dev:9090",
+			TendermintRPCNodeURL: "https://full-node-californium.mainnet-1.coreum.dev:26657",
+		},
+		{
+			Name:                 "full-node-uranium",
+			GRPCNodeURL:          "full-node-uranium.mainnet-1.coreum.dev:9090",
+			TendermintRPCNodeURL: "https://full-node-uranium.mainnet-1.coreum.dev:26657",
+		},
+	}
 }
 
 func GetConfig(stage utils.Stage) *Coreum {
@@ -8,6 +8,7 @@ func local() *Coreum {
 
 		USDS: CoreumAssetConfig{
 			TokenDecimal:       TokenDecimal,
+			DisplayUnit:        UsdsDisplayUnit,
 			TokenDenom:         TestUsdsTokenDenom,
 			IssuanceEnabled:    true,
 			RedemptionEnabled:  true,
@@ -16,9 +17,16 @@ func local() *Coreum {
 			TreasurySecretID:   "usds_treasury_wallet_mnemonic",
 		},
 		PRCConfig: CoreumRPCConfig{
-			GRPCNodeURL:          "full-node.testnet-1.coreum.dev:9090",
-			TendermintRPCNodeURL: "https://full-node.testnet-1.coreum.dev:26657",
-			HTTPServerPort:       HTTPServerPort,
+			Nodes:                   testnetNodes(),
+			MaxNodeBlockLag:         MaxNodeBlockLag,
+			NodeHealthCheckInterval: NodeHealthCheckInterval,
+			HTTPServerPort:          HTTPServerPort,
+		},
+		BlockScan: defaultBlockScanConfig(),
+		Gas:       defaultGasConfig(TestnetMaxFeePerTx),
+		OfflineSigning: CoreumOfflineSigningConfig{
+			SecretIDs:           []string{"usds_treasury_wallet_mnemonic"},
+			AllowedMessageTypes: []string{"/cosmos.bank.v1beta1.MsgSend"},
 		},
 	}
 }
@@ -9,6 +9,7 @@ func prod() *Coreum {
 		DepositEnabled:                true,
 		USDS: CoreumAssetConfig{
 			TokenDecimal:       TokenDecimal,
+			DisplayUnit:        UsdsDisplayUnit,
 			TokenDenom:         "microusds-core17z02cx2xxz2rehq6qay3rc06g5ksa9nxjwh5uv",
 			IssuanceEnabled:    true,
 			RedemptionEnabled:  true,
@@ -17,9 +18,12 @@ func prod() *Coreum {
 			TreasurySecretID:   "usds_treasury_wallet_mnemonic",
 		},
 		PRCConfig: CoreumRPCConfig{
-			GRPCNodeURL:          "full-node.mainnet-1.coreum.dev:9090",
-			TendermintRPCNodeURL: "https://full-node.mainnet-1.coreum.dev:26657",
-			HTTPServerPort:       HTTPServerPort,
+			Nodes:                   mainnetNodes(),
+			MaxNodeBlockLag:         MaxNodeBlockLag,
+			NodeHealthCheckInterval: NodeHealthCheckInterval,
+			HTTPServerPort:          HTTPServerPort,
 		},
+		BlockScan: defaultBlockScanConfig(),
+		Gas:       defaultGasConfig(MainnetMaxFeePerTx),
 	}
 }
@@ -3,6 +3,7 @@ package config
 import (
 	awsconfig "coreumservice/go/stably_io/config/aws"
 	blockchainconfig "coreumservice/go/stably_io/config/blockchain"
+	secretconfig "coreumservice/go/stably_io/config/secret"
 
 	"coreumservice/go/stably_io/utils"
 )
@@ -10,6 +11,7 @@ import (
 type RunConfig struct {
 	AWS        *awsconfig.AwsConfig
 	Blockchain *blockchainconfig.Blockchain
+	Secret     *secretconfig.SecretConfig
 }
 
 // Get the run config based on the value of STAGE environment variable.
@@ -22,5 +24,6 @@ func GetConfig(stage utils.Stage) *RunConfig {
 	return &RunConfig{
 		AWS:        awsconfig.GetConfig(stage),
 		Blockchain: blockchainconfig.GetConfig(stage),
+		Secret:     secretconfig.GetConfig(stage),
 	}
 }
@@ -0,0 +1,106 @@
+package secretconfig
+
+import (
+	"time"
+
+	configutils "coreumservice/go/stably_io/config/utils"
+	"coreumservice/go/stably_io/utils"
+)
+
+type Provider string
+
+const (
+	// AWS Secrets Manager
+	ProviderAWS Provider = "aws"
+	// The environment variables
+	ProviderEnv Provider = "env"
+	// The JSON file, optionally encrypted
+	ProviderFile Provider = "file"
+	// The KV version 2 secrets engine of Vault, or any server with the same HTTP API
+	ProviderVault Provider = "vault"
+)
+
+// The cached secrets are fetched again after the TTL, so the rotated secrets are picked up without restarting the service
+const DefaultCacheTTL = 15 * time.Minute
+
+type SecretConfig struct {
+	// The backend of the stage, overridden by the SECRET_PROVIDER environment variable if set in the local and test stages
+	Provider Provider
+	Env      *EnvConfig
+	File     *FileConfig
+	Vault    *VaultConfig
+	// The secrets never expire if 0
+	CacheTTL time.Duration
+}
+
+type EnvConfig struct {
+	// The secret "Tokenization" is read from the environment variable {Prefix}TOKENIZATION
+	Prefix string
+}
+
+type FileConfig struct {
+	// The JSON object of the secrets by name, overridden by the SECRETS_FILE environment variable if set in the local and test stages
+	Path string
+	// The environment variable with the base64 AES-256 key of the encrypted file, the file is plain JSON if it's not set
+	KeyEnvVar string
+}
+
+type VaultConfig struct {
+	// Overridden by the VAULT_ADDR environment variable if set in the local and test stages
+	Address string
+	// The mount path of the KV version 2 secrets engine
+	MountPath string
+	// The environment variable with the Vault token
+	TokenEnvVar string
+}
+
+func GetConfig(stage utils.Stage) *SecretConfig {
+	provider := configutils.SwitchOnStage(stage,
+		func() Provider { return ProviderAWS },
+		func() Provider { return ProviderAWS },
+		func() Provider { return ProviderEnv },
+		func() Provider { return ProviderFile },
+	)
+	if envProvider := getEnvOverride(stage, "SECRET_PROVIDER"); envProvider != "" {
+		provider = Provider(envProvider)
+	}
+
+	filePath := getEnvOverride(stage, "SECRETS_FILE")
+	if filePath == "" {
+		filePath = "secrets.local.json"
+	}
+	vaultAddress := getEnvOverride(stage, "VAULT_ADDR")
+	if vaultAddress == "" {
+		vaultAddress = "http://127.0.0.1:8200"
+	}
+
+	return &SecretConfig{
+		Provider: provider,
+		Env: &EnvConfig{
+			Prefix: "SECRET_",
+		},
+		File: &FileConfig{
+			Path:      filePath,
+			KeyEnvVar: "SECRETS_FILE_KEY",
+		},
+		Vault: &VaultConfig{
+			Address:     vaultAddress,
+			MountPath:   "secret",
+			TokenEnvVar: "VAULT_TOKEN",
+		},
+		CacheTTL: DefaultCacheTTL,
+	}
+}
+
+// The backend can only be overridden by the environment in the local and test stages,
+// so the prod and beta secrets can't be read from the backend of the environment
+func getEnvOverride(stage utils.Stage, envVarName string) string {
+	if stage != utils.Local && stage != utils.Test {
+		return ""
+	}
+	return utils.GetEnvVar(envVarName, false)
+}
+
+func GetConfigDefault() *SecretConfig {
+	return GetConfig(utils.GetStage())
+}
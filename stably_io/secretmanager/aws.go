@@ -0,0 +1,76 @@
+package secretmanager
+
+// If you need more information about configurations or implementing the sample code, visit the AWS docs:
+// https://aws.github.io/aws-sdk-go-v2/docs/getting-started/
+
+import (
+	"context"
+	awsconfig "coreumservice/go/stably_io/config/aws"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/pkg/errors"
+)
+
+type awsSecretProvider struct {
+	region string
+	mu     sync.Mutex
+	// Created on the first fetch, so the SDK configuration is only loaded once
+	client *secretsmanager.Client
+}
+
+func newAWSSecretProvider() *awsSecretProvider {
+	return &awsSecretProvider{
+		region: awsconfig.GetConfigDefault().SecretManager.Region,
+	}
+}
+
+// The SDK configuration that can't be loaded is loaded again on the next fetch
+func (p *awsSecretProvider) getClient(ctx context.Context) (*secretsmanager.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(p.region))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load SDK configuration for region %s", p.region)
+	}
+	p.client = secretsmanager.NewFromConfig(config)
+	return p.client, nil
+}
+
+func (p *awsSecretProvider) GetSecretVersion(ctx context.Context, secretName string, versionStage string) (*SecretVersion, error) {
+	svc, err := p.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := svc.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(secretName),
+		VersionStage: aws.String(versionStage), // VersionStage defaults to AWSCURRENT if unspecified
+	})
+	if err != nil {
+		// The secret never rotated has no AWSPREVIOUS version
+		var notFoundErr *types.ResourceNotFoundException
+		if versionStage != VersionStageCurrent && errors.As(err, &notFoundErr) {
+			return nil, errors.Wrapf(ErrVersionNotFound, "%v of secret: %s", versionStage, secretName)
+		}
+		// For a list of exceptions thrown, see
+		// https://docs.aws.amazon.com/secretsmanager/latest/apireference/API_GetSecretValue.html
+		return nil, errors.Wrapf(err, "failed to get secret: %s", secretName)
+	}
+	if result == nil {
+		return nil, errors.New("result is nil for secret: " + secretName)
+	}
+	if result.SecretString == nil {
+		return nil, errors.New("secret string is nil for secret: " + secretName)
+	}
+
+	return &SecretVersion{
+		Value:     *result.SecretString,
+		VersionID: aws.ToString(result.VersionId),
+	}, nil
+}
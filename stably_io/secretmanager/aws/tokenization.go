@@ -1,17 +1,23 @@
 package awssecretmanager
 
+import "context"
+
+const SecretNameTokenization = "Tokenization"
+
 const (
 	KeyUsdsTreasuryWalletMnemonic = "usds_treasury_wallet_mnemonic"
+	KeyUsdsOperatorWalletMnemonic = "usds_operator_wallet_mnemonic"
 )
 
 type TokenizationSecrets struct {
 	Coreum struct {
 		USDsTreasuryWalletMnemonic string `json:"usds_treasury_wallet_mnemonic"`
+		USDsOperatorWalletMnemonic string `json:"usds_operator_wallet_mnemonic"`
 	} `json:"coreum"`
 }
 
-func GetTokenizationSecrets() TokenizationSecrets {
+func GetTokenizationSecrets(ctx context.Context) (TokenizationSecrets, error) {
 	var tokenizationSecrets TokenizationSecrets
-	unmarshalJSONSecretOrPanic("Tokenization", &tokenizationSecrets)
-	return tokenizationSecrets
+	err := unmarshalJSONSecret(ctx, SecretNameTokenization, &tokenizationSecrets)
+	return tokenizationSecrets, err
 }
@@ -0,0 +1,22 @@
+package awssecretmanager_test
+
+import (
+	"context"
+	"testing"
+
+	awssecretmanager "coreumservice/go/stably_io/secretmanager/aws"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetTokenizationSecrets(t *testing.T) {
+	// The secrets of the test stage are read from the environment variables
+	t.Setenv("STAGE", "test")
+	t.Setenv("SECRET_PROVIDER", "")
+	t.Setenv("SECRET_TOKENIZATION", `{"coreum": {"usds_treasury_wallet_mnemonic": "treasury", "usds_operator_wallet_mnemonic": "operator"}}`)
+
+	tokenizationSecrets, err := awssecretmanager.GetTokenizationSecrets(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "treasury", tokenizationSecrets.Coreum.USDsTreasuryWalletMnemonic)
+	require.Equal(t, "operator", tokenizationSecrets.Coreum.USDsOperatorWalletMnemonic)
+}
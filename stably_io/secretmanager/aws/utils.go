@@ -1,79 +1,198 @@
 package awssecretmanager
 
-// If you need more information about configurations or implementing the sample code, visit the AWS docs:
-// https://aws.github.io/aws-sdk-go-v2/docs/getting-started/
+// The secrets are read from the secret provider of the stage, AWS Secrets Manager in prod and beta
 
 import (
 	"context"
-	awsconfig "coreumservice/go/stably_io/config/aws"
-	"coreumservice/go/stably_io/utils"
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/secretmanager"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/pkg/errors"
 )
 
+// The cached versions of the secret
+type cachedSecret struct {
+	current *secretmanager.SecretVersion
+	// Nil if the secret has no previous version
+	previous    *secretmanager.SecretVersion
+	refreshedAt time.Time
+}
+
+// The versions of the cached secret
+type SecretVersionInfo struct {
+	SecretName        string
+	CurrentVersionID  string
+	PreviousVersionID string
+	RefreshedAt       time.Time
+}
+
+// The fetch of the secret shared by the concurrent reads of the secret
+type secretFetch struct {
+	// Closed once the fetch is done
+	done chan struct{}
+	// The refreshed secret, or the kept secret if the fetch failed, nil if there's none
+	secret *cachedSecret
+	err    error
+}
+
+// The fetch is bounded, so the stuck backend doesn't block the reads of the secret forever
+const secretFetchTimeout = 30 * time.Second
+
 //nolint:gochecknoglobals // For in-memory caching of secrets
 var (
-	initOnce = make(map[string]*sync.Once)
-	secrets  = make(map[string]string)
+	secretsMu sync.Mutex
+	secrets   = make(map[string]*cachedSecret)
+	// The fetches in flight by secret name, the lock is not held while fetching
+	secretFetches = make(map[string]*secretFetch)
 )
 
-func unmarshalJSONSecretOrPanic(secretName string, v interface{}) {
-	secretJSON := getSecretStringOrPanic(secretName)
-	err := json.Unmarshal([]byte(secretJSON), v)
+func unmarshalJSONSecret(ctx context.Context, secretName string, v interface{}) error {
+	secretJSON, err := getSecretString(ctx, secretName)
 	if err != nil {
-		panic(errors.Wrapf(err, "failed to unmarshal JSON secret: %s", secretName))
+		return err
+	}
+	if err := json.Unmarshal([]byte(secretJSON), v); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal JSON secret: %s", secretName)
 	}
+	return nil
 }
 
-func getSecretStringOrPanic(secretName string) string {
-	initSecretOnce, ok := initOnce[secretName]
-	if !ok {
-		initSecretOnce = &sync.Once{}
-		initOnce[secretName] = initSecretOnce
+func getSecretString(ctx context.Context, secretName string) (string, error) {
+	secret, err := getSecret(ctx, secretName, false)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch secret with name: %v", secretName)
 	}
-	initSecretOnce.Do(func() {
-		ctx := context.Background()
-		res, err := fetchSecretStringFromAWS(ctx, secretName)
-		if err != nil {
-			err = errors.Wrapf(err, "failed to fetch secret with name: %v", secretName)
-			panic(utils.GetErrorWithStack(err))
+	if secret.current.Value == "" {
+		return "", errors.New("secret is empty: " + secretName)
+	}
+	return secret.current.Value, nil
+}
+
+// Return the cached secret, it's fetched again once the TTL expires or if forceRefresh.
+// The expired secret is kept if it can't be fetched again, so the outage of the backend doesn't break the running service.
+// The concurrent reads of the secret wait for the same fetch.
+func getSecret(ctx context.Context, secretName string, forceRefresh bool) (*cachedSecret, error) {
+	cacheTTL := secretconfig.GetConfigDefault().CacheTTL
+	secretsMu.Lock()
+	cached := secrets[secretName]
+	if cached != nil && !forceRefresh && (cacheTTL == 0 || time.Since(cached.refreshedAt) < cacheTTL) {
+		secretsMu.Unlock()
+		return cached, nil
+	}
+	fetch := secretFetches[secretName]
+	if fetch == nil {
+		fetch = &secretFetch{done: make(chan struct{})}
+		secretFetches[secretName] = fetch
+		go runSecretFetch(secretName, fetch)
+	}
+	secretsMu.Unlock()
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "failed to wait for secret: %s", secretName)
+	}
+	if fetch.err != nil && (fetch.secret == nil || forceRefresh) {
+		return nil, fetch.err
+	}
+	return fetch.secret, nil
+}
+
+// Fetch the secret with its own context, so the cancelled read doesn't fail the other reads waiting for the fetch
+func runSecretFetch(secretName string, fetch *secretFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretFetchTimeout)
+	defer cancel()
+	refreshed, err := fetchSecret(ctx, secretName)
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	defer close(fetch.done)
+	delete(secretFetches, secretName)
+
+	cached := secrets[secretName]
+	if err != nil {
+		fetch.err = err
+		if cached != nil {
+			fmt.Printf("[secret manager] Failed to refresh secret %v, keeping version %v: %v\n",
+				secretName, cached.current.VersionID, err)
+			// Retry after the TTL again
+			kept := *cached
+			kept.refreshedAt = time.Now()
+			secrets[secretName] = &kept
+			fetch.secret = &kept
 		}
-		secrets[secretName] = res
-	})
-	if secrets[secretName] == "" {
-		panic(errors.New("secret is empty: " + secretName))
+		return
+	}
+	if cached != nil && cached.current.VersionID != refreshed.current.VersionID {
+		fmt.Printf("[secret manager] Secret %v rotated from version %v to version %v\n",
+			secretName, cached.current.VersionID, refreshed.current.VersionID)
 	}
-	return secrets[secretName]
+	secrets[secretName] = refreshed
+	fetch.secret = refreshed
 }
 
-func fetchSecretStringFromAWS(ctx context.Context, secretName string) (string, error) {
-	region := awsconfig.GetConfigDefault().SecretManager.Region
-	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+// Fetch the current version, and the previous version if any
+func fetchSecret(ctx context.Context, secretName string) (*cachedSecret, error) {
+	provider, err := secretmanager.GetSecretProviderDefault()
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to load SDK configuration for region %s", region)
+		return nil, errors.Wrap(err, "failed to create secret provider")
+	}
+	current, err := provider.GetSecretVersion(ctx, secretName, secretmanager.VersionStageCurrent)
+	if err != nil {
+		return nil, err
 	}
 
-	svc := secretsmanager.NewFromConfig(config)
-	result, err := svc.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
-		SecretId:     aws.String(secretName),
-		VersionStage: aws.String("AWSCURRENT"), // VersionStage defaults to AWSCURRENT if unspecified
-	})
+	// The previous version is only informational, the current version is used anyway
+	previous, err := provider.GetSecretVersion(ctx, secretName, secretmanager.VersionStagePrevious)
 	if err != nil {
-		// For a list of exceptions thrown, see
-		// https://docs.aws.amazon.com/secretsmanager/latest/apireference/API_GetSecretValue.html
-		return "", errors.Wrapf(err, "failed to get secret: %s", secretName)
+		if !errors.Is(err, secretmanager.ErrVersionNotFound) {
+			fmt.Printf("[secret manager] failed to fetch previous version of secret %v: %v\n", secretName, err)
+		}
+		previous = nil
+	}
+
+	return &cachedSecret{
+		current:     current,
+		previous:    previous,
+		refreshedAt: time.Now(),
+	}, nil
+}
+
+func (s *cachedSecret) toVersionInfo(secretName string) *SecretVersionInfo {
+	info := &SecretVersionInfo{
+		SecretName:       secretName,
+		CurrentVersionID: s.current.VersionID,
+		RefreshedAt:      s.refreshedAt,
 	}
-	if result == nil {
-		return "", errors.New("result is nil for secret: " + secretName)
+	if s.previous != nil {
+		info.PreviousVersionID = s.previous.VersionID
 	}
-	if result.SecretString == nil {
-		return "", errors.New("secret string is nil for secret: " + secretName)
+	return info
+}
+
+// Fetch the secrets again without waiting for the TTL, all the cached secrets if secretNames is empty
+func RefreshSecrets(ctx context.Context, secretNames []string) ([]*SecretVersionInfo, error) {
+	if len(secretNames) == 0 {
+		secretsMu.Lock()
+		for secretName := range secrets {
+			secretNames = append(secretNames, secretName)
+		}
+		secretsMu.Unlock()
+		sort.Strings(secretNames)
 	}
 
-	return *result.SecretString, nil
+	versions := []*SecretVersionInfo{}
+	for _, secretName := range secretNames {
+		secret, err := getSecret(ctx, secretName, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to refresh secret: %s", secretName)
+		}
+		versions = append(versions, secret.toVersionInfo(secretName))
+	}
+	return versions, nil
 }
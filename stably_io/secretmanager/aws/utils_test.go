@@ -0,0 +1,90 @@
+package awssecretmanager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"coreumservice/go/stably_io/secretmanager"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireSecretString(t *testing.T, expected string, secretName string) {
+	value, err := getSecretString(context.Background(), secretName)
+	require.NoError(t, err)
+	require.Equal(t, expected, value)
+}
+
+func Test_SecretRotation(t *testing.T) {
+	// The secrets of the test stage are read from the environment variables
+	t.Setenv("STAGE", "test")
+	t.Setenv("SECRET_PROVIDER", "")
+	t.Setenv("SECRET_TESTING_ROTATION_543543", "first")
+
+	// The concurrent reads share the cached secret
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			requireSecretString(t, "first", "Testing_Rotation_543543")
+		}()
+	}
+	wg.Wait()
+
+	// The rotated secret is only picked up after the TTL or the refresh
+	t.Setenv("SECRET_TESTING_ROTATION_543543", "second")
+	requireSecretString(t, "first", "Testing_Rotation_543543")
+
+	versions, err := RefreshSecrets(context.Background(), []string{"Testing_Rotation_543543"})
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	require.Equal(t, "Testing_Rotation_543543", versions[0].SecretName)
+	require.NotEmpty(t, versions[0].CurrentVersionID)
+	// The environment variables have no previous version
+	require.Empty(t, versions[0].PreviousVersionID)
+	requireSecretString(t, "second", "Testing_Rotation_543543")
+
+	// The secret removed from the backend can't be refreshed
+	t.Setenv("SECRET_TESTING_ROTATION_543543", "")
+	_, err = getSecretString(context.Background(), "Testing_Missing_543543")
+	require.Error(t, err)
+	_, err = RefreshSecrets(context.Background(), []string{"Testing_Rotation_543543"})
+	require.Error(t, err)
+	requireSecretString(t, "second", "Testing_Rotation_543543")
+}
+
+func Test_SecretFetchInFlight(t *testing.T) {
+	t.Setenv("STAGE", "test")
+	t.Setenv("SECRET_PROVIDER", "")
+	t.Setenv("SECRET_TESTING_OTHER_543543", "other")
+	secretName := "Testing_InFlight_543543"
+	fetch := &secretFetch{done: make(chan struct{})}
+	secretsMu.Lock()
+	secretFetches[secretName] = fetch
+	secretsMu.Unlock()
+	defer func() {
+		secretsMu.Lock()
+		delete(secretFetches, secretName)
+		delete(secrets, secretName)
+		secretsMu.Unlock()
+	}()
+
+	// The cancelled read doesn't wait for the fetch in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := getSecret(ctx, secretName, false)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The other secrets are read while the fetch is in flight
+	requireSecretString(t, "other", "Testing_Other_543543")
+
+	// The reads get the secret of the fetch in flight
+	fetch.secret = &cachedSecret{current: &secretmanager.SecretVersion{Value: "fetched"}, refreshedAt: time.Now()}
+	close(fetch.done)
+	secret, err := getSecret(context.Background(), secretName, false)
+	require.NoError(t, err)
+	require.Equal(t, "fetched", secret.current.Value)
+}
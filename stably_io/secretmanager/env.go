@@ -0,0 +1,46 @@
+package secretmanager
+
+import (
+	"context"
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/utils"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The secrets in the environment variables, e.g. the secret "Tokenization" in SECRET_TOKENIZATION
+type envSecretProvider struct {
+	prefix string
+}
+
+func newEnvSecretProvider(envConfig *secretconfig.EnvConfig) *envSecretProvider {
+	return &envSecretProvider{prefix: envConfig.Prefix}
+}
+
+// Only the current version is available
+func (p *envSecretProvider) GetSecretVersion(_ context.Context, secretName string, versionStage string) (*SecretVersion, error) {
+	if versionStage != VersionStageCurrent {
+		return nil, errors.Wrapf(ErrVersionNotFound, "%v of secret: %s", versionStage, secretName)
+	}
+	envVar := p.getEnvVarName(secretName)
+	value := utils.GetEnvVar(envVar, false)
+	if value == "" {
+		return nil, errors.Errorf("missing %v environment variable of secret: %s", envVar, secretName)
+	}
+	return &SecretVersion{Value: value, VersionID: getValueVersionID(value)}, nil
+}
+
+// The upper case secret name with the other characters than the letters and the digits replaced by "_"
+func (p *envSecretProvider) getEnvVarName(secretName string) string {
+	return p.prefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, secretName)
+}
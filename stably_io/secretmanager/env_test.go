@@ -0,0 +1,52 @@
+package secretmanager_test
+
+import (
+	"context"
+	"testing"
+
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/secretmanager"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EnvSecretProvider(t *testing.T) {
+	provider, err := secretmanager.NewSecretProvider(&secretconfig.SecretConfig{
+		Provider: secretconfig.ProviderEnv,
+		Env:      &secretconfig.EnvConfig{Prefix: "TESTING_SECRET_543543_"},
+	})
+	require.NoError(t, err)
+
+	t.Run("Existing secret", func(it *testing.T) {
+		it.Setenv("TESTING_SECRET_543543_TOKENIZATION_V2", `{"coreum":{}}`)
+		value, err := secretmanager.GetSecretString(context.Background(), provider, "Tokenization-v2")
+		require.NoError(it, err)
+		require.Equal(it, `{"coreum":{}}`, value)
+	})
+
+	t.Run("Secret versions", func(it *testing.T) {
+		it.Setenv("TESTING_SECRET_543543_ROTATED", "first")
+		firstVersion, err := provider.GetSecretVersion(context.Background(), "Rotated", secretmanager.VersionStageCurrent)
+		require.NoError(it, err)
+
+		// The rotated value has the other version ID
+		it.Setenv("TESTING_SECRET_543543_ROTATED", "second")
+		secondVersion, err := provider.GetSecretVersion(context.Background(), "Rotated", secretmanager.VersionStageCurrent)
+		require.NoError(it, err)
+		require.Equal(it, "second", secondVersion.Value)
+		require.NotEqual(it, firstVersion.VersionID, secondVersion.VersionID)
+
+		_, err = provider.GetSecretVersion(context.Background(), "Rotated", secretmanager.VersionStagePrevious)
+		require.ErrorIs(it, err, secretmanager.ErrVersionNotFound)
+	})
+
+	t.Run("Missing secret", func(it *testing.T) {
+		_, err := secretmanager.GetSecretString(context.Background(), provider, "Missing")
+		require.ErrorContains(it, err, "TESTING_SECRET_543543_MISSING")
+	})
+}
+
+func Test_UnknownSecretProvider(t *testing.T) {
+	_, err := secretmanager.NewSecretProvider(&secretconfig.SecretConfig{Provider: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package secretmanager
+
+import (
+	"context"
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/utils"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// The secrets in the JSON object of the file by name, e.g. {"Tokenization": {"coreum": {...}}}.
+// The value of the secret is the JSON string, or the JSON text of the other values.
+// The encrypted file is the AES-256-GCM nonce followed by the sealed JSON, see EncryptSecretsFile.
+type fileSecretProvider struct {
+	path string
+	// Nil if the file is not encrypted
+	key []byte
+}
+
+func newFileSecretProvider(fileConfig *secretconfig.FileConfig) (*fileSecretProvider, error) {
+	provider := &fileSecretProvider{path: fileConfig.Path}
+	if encodedKey := utils.GetEnvVar(fileConfig.KeyEnvVar, false); encodedKey != "" {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid base64 key in %v environment variable", fileConfig.KeyEnvVar)
+		}
+		provider.key = key
+	}
+	return provider, nil
+}
+
+// The file is read again for every secret, so the edited file is picked up without restarting the service.
+// Only the current version is available.
+func (p *fileSecretProvider) GetSecretVersion(_ context.Context, secretName string, versionStage string) (*SecretVersion, error) {
+	if versionStage != VersionStageCurrent {
+		return nil, errors.Wrapf(ErrVersionNotFound, "%v of secret: %s", versionStage, secretName)
+	}
+	value, err := p.getSecretString(secretName)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretVersion{Value: value, VersionID: getValueVersionID(value)}, nil
+}
+
+func (p *fileSecretProvider) getSecretString(secretName string) (string, error) {
+	content, err := os.ReadFile(p.path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read secrets file: %s", p.path)
+	}
+	if p.key != nil {
+		content, err = decryptSecretsFile(content, p.key)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to decrypt secrets file: %s", p.path)
+		}
+	}
+
+	secrets := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &secrets); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal secrets file: %s", p.path)
+	}
+	value, ok := secrets[secretName]
+	if !ok {
+		return "", errors.Errorf("secret %s is not in secrets file: %s", secretName, p.path)
+	}
+
+	var stringValue string
+	if err := json.Unmarshal(value, &stringValue); err == nil {
+		return stringValue, nil
+	}
+	return string(value), nil
+}
+
+// Encrypt the JSON content of the secrets file with the AES-256 key
+func EncryptSecretsFile(content []byte, key []byte) ([]byte, error) {
+	gcm, err := newSecretsFileGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return gcm.Seal(nonce, nonce, content, nil), nil
+}
+
+func decryptSecretsFile(encryptedContent []byte, key []byte) ([]byte, error) {
+	gcm, err := newSecretsFileGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedContent) < gcm.NonceSize() {
+		return nil, errors.New("encrypted content is shorter than nonce")
+	}
+	nonce, sealedContent := encryptedContent[:gcm.NonceSize()], encryptedContent[gcm.NonceSize():]
+	content, err := gcm.Open(nil, nonce, sealedContent, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open encrypted content")
+	}
+	return content, nil
+}
+
+func newSecretsFileGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.Errorf("the key is %v bytes instead of 32 bytes", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create GCM")
+	}
+	return gcm, nil
+}
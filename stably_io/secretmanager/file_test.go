@@ -0,0 +1,79 @@
+package secretmanager_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/secretmanager"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecretsFileContent = `{"Tokenization": {"coreum": {"usds_treasury_wallet_mnemonic": "mnemonic"}}, "Plain": "plain value"}`
+
+func newFileSecretProvider(t *testing.T, content []byte, keyEnvVar string) secretmanager.SecretProvider {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	provider, err := secretmanager.NewSecretProvider(&secretconfig.SecretConfig{
+		Provider: secretconfig.ProviderFile,
+		File:     &secretconfig.FileConfig{Path: path, KeyEnvVar: keyEnvVar},
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+func Test_FileSecretProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Plain file", func(it *testing.T) {
+		provider := newFileSecretProvider(it, []byte(testSecretsFileContent), "NOT_EXISTING_ENV_VAR_543543")
+
+		// The JSON object is returned as is, the JSON string is unquoted
+		value, err := secretmanager.GetSecretString(ctx, provider, "Tokenization")
+		require.NoError(it, err)
+		require.JSONEq(it, `{"coreum": {"usds_treasury_wallet_mnemonic": "mnemonic"}}`, value)
+		value, err = secretmanager.GetSecretString(ctx, provider, "Plain")
+		require.NoError(it, err)
+		require.Equal(it, "plain value", value)
+
+		_, err = secretmanager.GetSecretString(ctx, provider, "Missing")
+		require.Error(it, err)
+	})
+
+	t.Run("Encrypted file", func(it *testing.T) {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		require.NoError(it, err)
+		it.Setenv("TESTING_SECRETS_FILE_KEY_543543", base64.StdEncoding.EncodeToString(key))
+
+		encryptedContent, err := secretmanager.EncryptSecretsFile([]byte(testSecretsFileContent), key)
+		require.NoError(it, err)
+		provider := newFileSecretProvider(it, encryptedContent, "TESTING_SECRETS_FILE_KEY_543543")
+		value, err := secretmanager.GetSecretString(ctx, provider, "Plain")
+		require.NoError(it, err)
+		require.Equal(it, "plain value", value)
+
+		// The file encrypted with the other key
+		otherKey := make([]byte, 32)
+		encryptedContent, err = secretmanager.EncryptSecretsFile([]byte(testSecretsFileContent), otherKey)
+		require.NoError(it, err)
+		provider = newFileSecretProvider(it, encryptedContent, "TESTING_SECRETS_FILE_KEY_543543")
+		_, err = secretmanager.GetSecretString(ctx, provider, "Plain")
+		require.Error(it, err)
+	})
+
+	t.Run("Missing file", func(it *testing.T) {
+		provider, err := secretmanager.NewSecretProvider(&secretconfig.SecretConfig{
+			Provider: secretconfig.ProviderFile,
+			File:     &secretconfig.FileConfig{Path: filepath.Join(it.TempDir(), "missing.json")},
+		})
+		require.NoError(it, err)
+		_, err = secretmanager.GetSecretString(ctx, provider, "Plain")
+		require.Error(it, err)
+	})
+}
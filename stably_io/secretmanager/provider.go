@@ -0,0 +1,90 @@
+package secretmanager
+
+import (
+	"context"
+	"coreumservice/go/stably_io/config"
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// The version stages of the secret, as in AWS Secrets Manager
+const (
+	VersionStageCurrent  = "AWSCURRENT"
+	VersionStagePrevious = "AWSPREVIOUS"
+)
+
+// The secret has no version in the version stage, e.g. it has never been rotated
+var ErrVersionNotFound = errors.New("secret version not found")
+
+// The secret string, usually the JSON object, stored under its name
+type SecretVersion struct {
+	Value string
+	// Changes when the secret is rotated
+	VersionID string
+}
+
+// The backend the secrets are read from
+type SecretProvider interface {
+	// Return the version of the secret in the version stage, ErrVersionNotFound if there's none
+	GetSecretVersion(ctx context.Context, secretName string, versionStage string) (*SecretVersion, error)
+}
+
+func NewSecretProvider(secretConfig *secretconfig.SecretConfig) (SecretProvider, error) {
+	switch secretConfig.Provider {
+	case secretconfig.ProviderAWS:
+		return newAWSSecretProvider(), nil
+	case secretconfig.ProviderEnv:
+		return newEnvSecretProvider(secretConfig.Env), nil
+	case secretconfig.ProviderFile:
+		return newFileSecretProvider(secretConfig.File)
+	case secretconfig.ProviderVault:
+		return newVaultSecretProvider(secretConfig.Vault)
+	default:
+		return nil, errors.Errorf("unknown secret provider %q", secretConfig.Provider)
+	}
+}
+
+// Return the current secret string
+func GetSecretString(ctx context.Context, provider SecretProvider, secretName string) (string, error) {
+	secretVersion, err := provider.GetSecretVersion(ctx, secretName, VersionStageCurrent)
+	if err != nil {
+		return "", err
+	}
+	return secretVersion.Value, nil
+}
+
+// The version ID of the secret of the backends without the versions, derived from its value
+func getValueVersionID(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:8])
+}
+
+//nolint:gochecknoglobals
+var (
+	defaultProviderMu sync.Mutex
+	// The provider of the stage and the config it's built from
+	defaultProvider       SecretProvider
+	defaultProviderConfig *secretconfig.SecretConfig
+)
+
+// Return the secret provider of the stage, it's built again only if the config changes
+func GetSecretProviderDefault() (SecretProvider, error) {
+	secretConfig := config.GetConfigDefault().Secret
+	defaultProviderMu.Lock()
+	defer defaultProviderMu.Unlock()
+	if defaultProvider != nil && reflect.DeepEqual(defaultProviderConfig, secretConfig) {
+		return defaultProvider, nil
+	}
+	provider, err := NewSecretProvider(secretConfig)
+	if err != nil {
+		return nil, err
+	}
+	defaultProvider = provider
+	defaultProviderConfig = secretConfig
+	return provider, nil
+}
@@ -0,0 +1,49 @@
+package secretmanager_test
+
+import (
+	"testing"
+
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/secretmanager"
+	"coreumservice/go/stably_io/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetSecretProviderDefault(t *testing.T) {
+	t.Setenv("STAGE", "test")
+	t.Setenv("SECRET_PROVIDER", "")
+
+	t.Run("Provider built once", func(it *testing.T) {
+		provider, err := secretmanager.GetSecretProviderDefault()
+		require.NoError(it, err)
+		sameProvider, err := secretmanager.GetSecretProviderDefault()
+		require.NoError(it, err)
+		require.Same(it, provider, sameProvider)
+
+		// The changed config builds the other provider
+		it.Setenv("SECRET_PROVIDER", "file")
+		fileProvider, err := secretmanager.GetSecretProviderDefault()
+		require.NoError(it, err)
+		require.NotSame(it, provider, fileProvider)
+	})
+
+	t.Run("Environment overrides", func(it *testing.T) {
+		it.Setenv("SECRET_PROVIDER", "vault")
+		it.Setenv("SECRETS_FILE", "testing_secrets_543543.json")
+		it.Setenv("VAULT_ADDR", "http://vault.testing:8200")
+
+		secretConfig := secretconfig.GetConfig(utils.Local)
+		require.Equal(it, secretconfig.ProviderVault, secretConfig.Provider)
+		require.Equal(it, "testing_secrets_543543.json", secretConfig.File.Path)
+		require.Equal(it, "http://vault.testing:8200", secretConfig.Vault.Address)
+
+		// The prod and beta backends can't be redirected by the environment
+		for _, stage := range []utils.Stage{utils.Prod, utils.Beta} {
+			secretConfig := secretconfig.GetConfig(stage)
+			require.Equal(it, secretconfig.ProviderAWS, secretConfig.Provider)
+			require.Equal(it, "secrets.local.json", secretConfig.File.Path)
+			require.Equal(it, "http://127.0.0.1:8200", secretConfig.Vault.Address)
+		}
+	})
+}
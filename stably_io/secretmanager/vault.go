@@ -0,0 +1,111 @@
+package secretmanager
+
+import (
+	"context"
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/utils"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const vaultRequestTimeout = 10 * time.Second
+
+// The secrets in the KV version 2 secrets engine of Vault.
+// The value of the secret is the JSON object of its data, e.g. {"coreum": {...}} of the secret "Tokenization".
+type vaultSecretProvider struct {
+	address    string
+	mountPath  string
+	token      string
+	httpClient *http.Client
+}
+
+type vaultReadSecretReply struct {
+	Data struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+func newVaultSecretProvider(vaultConfig *secretconfig.VaultConfig) (*vaultSecretProvider, error) {
+	token := utils.GetEnvVar(vaultConfig.TokenEnvVar, false)
+	if token == "" {
+		return nil, errors.Errorf("missing %v environment variable of Vault token", vaultConfig.TokenEnvVar)
+	}
+	return &vaultSecretProvider{
+		address:    strings.TrimSuffix(vaultConfig.Address, "/"),
+		mountPath:  strings.Trim(vaultConfig.MountPath, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: vaultRequestTimeout},
+	}, nil
+}
+
+// The current version is the latest version of the secret, the previous version is the one before it
+func (p *vaultSecretProvider) GetSecretVersion(ctx context.Context, secretName string, versionStage string) (*SecretVersion, error) {
+	reply, err := p.readSecret(ctx, secretName, 0)
+	if err != nil {
+		return nil, err
+	}
+	if versionStage != VersionStageCurrent {
+		if versionStage != VersionStagePrevious || reply.Data.Metadata.Version <= 1 {
+			return nil, errors.Wrapf(ErrVersionNotFound, "%v of secret: %s", versionStage, secretName)
+		}
+		reply, err = p.readSecret(ctx, secretName, reply.Data.Metadata.Version-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The deleted version has no data
+	if len(reply.Data.Data) == 0 || string(reply.Data.Data) == "null" {
+		if versionStage != VersionStageCurrent {
+			return nil, errors.Wrapf(ErrVersionNotFound, "%v of secret: %s", versionStage, secretName)
+		}
+		return nil, errors.New("secret data is empty for secret: " + secretName)
+	}
+	return &SecretVersion{
+		Value:     string(reply.Data.Data),
+		VersionID: strconv.Itoa(reply.Data.Metadata.Version),
+	}, nil
+}
+
+// Read the version of the secret, the latest version if version is 0
+func (p *vaultSecretProvider) readSecret(ctx context.Context, secretName string, version int) (*vaultReadSecretReply, error) {
+	url := p.address + "/v1/" + p.mountPath + "/data/" + secretName
+	if version > 0 {
+		url += "?version=" + strconv.Itoa(version)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Vault request")
+	}
+	request.Header.Set("X-Vault-Token", p.token)
+
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get secret from Vault: %s", secretName)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read Vault reply of secret: %s", secretName)
+	}
+	// Vault replies 404 with the metadata for the deleted version
+	if response.StatusCode != http.StatusOK && (version == 0 || response.StatusCode != http.StatusNotFound) {
+		return nil, errors.Errorf("Vault replied %v to secret %s: %s", response.StatusCode, secretName, body)
+	}
+
+	reply := &vaultReadSecretReply{}
+	if err := json.Unmarshal(body, reply); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal Vault reply of secret: %s", secretName)
+	}
+	return reply, nil
+}
@@ -0,0 +1,87 @@
+package secretmanager_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	secretconfig "coreumservice/go/stably_io/config/secret"
+	"coreumservice/go/stably_io/secretmanager"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VaultSecretProvider(t *testing.T) {
+	// The fake Vault with the secret "Tokenization" in the KV version 2 secrets engine mounted at "kv"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "testing-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/kv/data/Tokenization" {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		switch r.URL.Query().Get("version") {
+		case "", "3":
+			_, _ = w.Write([]byte(`{"data": {"data": {"coreum": {"usds_treasury_wallet_mnemonic": "mnemonic"}}, "metadata": {"version": 3}}}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"data": {"data": {"coreum": {"usds_treasury_wallet_mnemonic": "previous"}}, "metadata": {"version": 2}}}`))
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	vaultConfig := &secretconfig.SecretConfig{
+		Provider: secretconfig.ProviderVault,
+		Vault: &secretconfig.VaultConfig{
+			Address:     server.URL + "/",
+			MountPath:   "/kv/",
+			TokenEnvVar: "TESTING_VAULT_TOKEN_543543",
+		},
+	}
+
+	t.Run("Missing token", func(it *testing.T) {
+		_, err := secretmanager.NewSecretProvider(vaultConfig)
+		require.Error(it, err)
+	})
+
+	t.Run("Existing secret", func(it *testing.T) {
+		it.Setenv("TESTING_VAULT_TOKEN_543543", "testing-token")
+		provider, err := secretmanager.NewSecretProvider(vaultConfig)
+		require.NoError(it, err)
+
+		value, err := secretmanager.GetSecretString(context.Background(), provider, "Tokenization")
+		require.NoError(it, err)
+		require.JSONEq(it, `{"coreum": {"usds_treasury_wallet_mnemonic": "mnemonic"}}`, value)
+
+		_, err = secretmanager.GetSecretString(context.Background(), provider, "Missing")
+		require.Error(it, err)
+	})
+
+	t.Run("Secret versions", func(it *testing.T) {
+		it.Setenv("TESTING_VAULT_TOKEN_543543", "testing-token")
+		provider, err := secretmanager.NewSecretProvider(vaultConfig)
+		require.NoError(it, err)
+
+		currentVersion, err := provider.GetSecretVersion(context.Background(), "Tokenization", secretmanager.VersionStageCurrent)
+		require.NoError(it, err)
+		require.Equal(it, "3", currentVersion.VersionID)
+
+		// The previous version is the one before the latest version
+		previousVersion, err := provider.GetSecretVersion(context.Background(), "Tokenization", secretmanager.VersionStagePrevious)
+		require.NoError(it, err)
+		require.Equal(it, "2", previousVersion.VersionID)
+		require.JSONEq(it, `{"coreum": {"usds_treasury_wallet_mnemonic": "previous"}}`, previousVersion.Value)
+	})
+
+	t.Run("Invalid token", func(it *testing.T) {
+		it.Setenv("TESTING_VAULT_TOKEN_543543", "other-token")
+		provider, err := secretmanager.NewSecretProvider(vaultConfig)
+		require.NoError(it, err)
+		_, err = secretmanager.GetSecretString(context.Background(), provider, "Tokenization")
+		require.ErrorContains(it, err, "403")
+	})
+}